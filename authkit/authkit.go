@@ -0,0 +1,87 @@
+// Package authkit provides JWT bearer token authentication
+// for echo & gRPC server
+package authkit
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+// contextKeyClaims to store/obtains verified *Claims to/from request's context.
+var contextKeyClaims = web.ContextKey("authClaims")
+
+// Claims is verified JWT claims.
+// Scopes are taken from `scope` (space separated) or `scp` claim,
+// Roles are taken from `roles` claim.
+type Claims struct {
+	Subject   string                 `json:"sub,omitempty"`
+	Issuer    string                 `json:"iss,omitempty"`
+	Audience  []string               `json:"aud,omitempty"`
+	ExpiresAt time.Time              `json:"exp,omitempty"`
+	IssuedAt  time.Time              `json:"iat,omitempty"`
+	Scopes    []string               `json:"scopes,omitempty"`
+	Roles     []string               `json:"roles,omitempty"`
+	Raw       map[string]interface{} `json:"-"`
+}
+
+// HasScopes reports whether claims contains all scopes.
+func (c *Claims) HasScopes(scopes ...string) bool {
+	return containsAll(c.Scopes, scopes)
+}
+
+// HasRoles reports whether claims contains all roles.
+func (c *Claims) HasRoles(roles ...string) bool {
+	return containsAll(c.Roles, roles)
+}
+
+// NewContext returns copy of ctx containing claims,
+// claims subject is added to ctx's logger.
+func NewContext(ctx context.Context, c *Claims) context.Context {
+	ctx = context.WithValue(ctx, contextKeyClaims, c)
+
+	logger := log.FromCtx(ctx)
+	logger.AddField("subject", c.Subject)
+
+	return log.AddToContext(ctx, logger)
+}
+
+// ClaimsFromContext returns verified claims from ctx.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	c, ok := ctx.Value(contextKeyClaims).(*Claims)
+
+	return c, ok && c != nil
+}
+
+// bearerToken returns token from `Bearer <token>` authorization value.
+func bearerToken(authorization string) string {
+	const prefix = "bearer "
+
+	if len(authorization) <= len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return ""
+	}
+
+	return strings.TrimSpace(authorization[len(prefix):])
+}
+
+func containsAll(have []string, want []string) bool {
+	for _, w := range want {
+		found := false
+
+		for _, h := range have {
+			if h == w {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,123 @@
+package authkit
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+	"github.com/adipurnama/go-toolkit/internal/grpcutil"
+	"github.com/adipurnama/go-toolkit/log"
+)
+
+const keyAuthorization = "authorization"
+
+// Requirement is authorization requirement of a gRPC method.
+type Requirement struct {
+	Public bool     // Public method doesn't require token
+	Scopes []string // Scopes required in token claims
+	Roles  []string // Roles required in token claims
+}
+
+// MethodPolicy maps gRPC full method name, e.g. `/pkg.Service/Method`,
+// or service prefix, e.g. `/pkg.Service/`, to its Requirement.
+// exact method name is matched first. Method not found in the policy only requires valid token.
+type MethodPolicy map[string]Requirement
+
+func (p MethodPolicy) requirement(fullMethod string) Requirement {
+	if r, ok := p[fullMethod]; ok {
+		return r
+	}
+
+	if idx := strings.LastIndex(fullMethod, "/"); idx > 0 {
+		if r, ok := p[fullMethod[:idx+1]]; ok {
+			return r
+		}
+	}
+
+	return Requirement{}
+}
+
+// UnaryServerInterceptor verifies bearer token from `authorization` metadata using v
+// and authorizes the call using policy. gRPC health check service is always public.
+func UnaryServerInterceptor(v *Verifier, policy MethodPolicy) grpc.UnaryServerInterceptor {
+	if v == nil {
+		panic("authkit: verifier cannot be nil")
+	}
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		ctx, err = authorize(ctx, v, policy, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is stream version of UnaryServerInterceptor.
+func StreamServerInterceptor(v *Verifier, policy MethodPolicy) grpc.StreamServerInterceptor {
+	if v == nil {
+		panic("authkit: verifier cannot be nil")
+	}
+
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx, err := authorize(ss.Context(), v, policy, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, grpcutil.WrapServerStream(ctx, ss))
+	}
+}
+
+func authorize(ctx context.Context, v *Verifier, policy MethodPolicy, fullMethod string) (context.Context, error) {
+	if grpcutil.IsHealthCheck(fullMethod) {
+		return ctx, nil
+	}
+
+	r := policy.requirement(fullMethod)
+	if r.Public {
+		return ctx, nil
+	}
+
+	var token string
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get(keyAuthorization); len(vals) > 0 {
+			token = bearerToken(vals[0])
+		}
+	}
+
+	claims, err := v.Verify(ctx, token)
+	if errors.Is(err, ErrJWKSUnavailable) {
+		log.FromCtx(ctx).WarnError(err, "bearer token can't be verified", "method", fullMethod)
+
+		return ctx, apperrors.Wrap(err, apperrors.CodeUnavailable)
+	}
+
+	if err != nil {
+		log.FromCtx(ctx).Debug("bearer token rejected", "method", fullMethod, "error", err.Error())
+
+		return ctx, apperrors.Wrap(err, apperrors.CodeUnauthenticated)
+	}
+
+	if !claims.HasScopes(r.Scopes...) || !claims.HasRoles(r.Roles...) {
+		return ctx, apperrors.New(apperrors.CodePermissionDenied)
+	}
+
+	return NewContext(ctx, claims), nil
+}
@@ -0,0 +1,255 @@
+package authkit
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/adipurnama/go-toolkit/log"
+)
+
+// jwksRetryBackoff is how long a failed fetch error is returned before JWKS is fetched again.
+const jwksRetryBackoff = 5 * time.Second
+
+// ErrJWKSUnavailable is returned when JWKS can't be fetched & there's no cached key for the token,
+// authkit middleware & interceptors respond with Unavailable instead of Unauthenticated.
+var ErrJWKSUnavailable = errors.New("authkit: JWKS is unavailable")
+
+var (
+	errUnknownKeyID    = errors.New("authkit: unknown token key id")
+	errJWKSFetchFailed = errors.New("authkit: failed to fetch JWKS")
+	errInvalidJWK      = errors.New("authkit: invalid JWK")
+)
+
+// jwks caches remote JSON Web Key Set keyed by `kid`.
+// keys are refreshed every refreshInterval, or when unknown kid is found
+// (at most once per minRefreshInterval after successful fetch). stale keys are kept if refresh fails,
+// failed fetch is retried after jwksRetryBackoff.
+type jwks struct {
+	url                string
+	client             *http.Client
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+	fetchTimeout       time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]interface{}
+	fetchedAt time.Time
+	failedAt  time.Time
+	fetchErr  error
+
+	refreshMu sync.Mutex
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func newJWKS(url string, client *http.Client, refreshInterval, minRefreshInterval, fetchTimeout time.Duration) *jwks {
+	return &jwks{
+		url:                url,
+		client:             client,
+		refreshInterval:    refreshInterval,
+		minRefreshInterval: minRefreshInterval,
+		fetchTimeout:       fetchTimeout,
+		keys:               make(map[string]interface{}),
+	}
+}
+
+func (j *jwks) key(ctx context.Context, kid string) (interface{}, error) {
+	j.mu.RLock()
+	k, found := j.keys[kid]
+	stale := time.Since(j.fetchedAt) > j.refreshInterval
+	recentlyFetched := time.Since(j.fetchedAt) < j.minRefreshInterval
+	j.mu.RUnlock()
+
+	if found && !stale {
+		return k, nil
+	}
+
+	if !found && recentlyFetched {
+		return nil, errors.Wrapf(errUnknownKeyID, "kid %q", kid)
+	}
+
+	if err := j.refresh(ctx, found); err != nil {
+		if found {
+			log.FromCtx(ctx).WarnError(err, "JWKS refresh failed, using cached keys", "jwks_url", j.url)
+
+			return k, nil
+		}
+
+		return nil, err
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	if k, ok := j.keys[kid]; ok {
+		return k, nil
+	}
+
+	return nil, errors.Wrapf(errUnknownKeyID, "kid %q", kid)
+}
+
+// refresh fetches JWKS, concurrent callers wait for the same fetch.
+// It returns the last fetch error wrapping ErrJWKSUnavailable until jwksRetryBackoff passed.
+func (j *jwks) refresh(ctx context.Context, staleOnly bool) error {
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+
+	// other caller may have just refreshed the keys, or failed to
+	j.mu.RLock()
+	fresh := time.Since(j.fetchedAt) <= j.refreshInterval
+	recentlyFetched := time.Since(j.fetchedAt) < j.minRefreshInterval
+	recentlyFailed := time.Since(j.failedAt) < jwksRetryBackoff
+	fetchErr := j.fetchErr
+	j.mu.RUnlock()
+
+	if (staleOnly && fresh) || recentlyFetched {
+		return nil
+	}
+
+	if recentlyFailed {
+		return fetchErr
+	}
+
+	// fetch isn't bound to caller's request, so its cancellation doesn't fail other waiting callers
+	fetchCtx, cancel := context.WithTimeout(log.AddToContext(context.Background(), log.FromCtx(ctx)), j.fetchTimeout)
+	defer cancel()
+
+	keys, err := j.fetch(fetchCtx)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err != nil {
+		j.failedAt = time.Now()
+		j.fetchErr = errors.Wrap(ErrJWKSUnavailable, err.Error())
+
+		return j.fetchErr
+	}
+
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	j.failedAt = time.Time{}
+	j.fetchErr = nil
+
+	return nil
+}
+
+func (j *jwks) fetch(ctx context.Context) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "authkit: create JWKS request")
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "authkit: fetch JWKS")
+	}
+
+	defer log.OnCloseErrorf(log.FromCtx(ctx), resp.Body, "authkit: close JWKS response body")
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Wrapf(errJWKSFetchFailed, "status code %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, errors.Wrap(err, "authkit: decode JWKS")
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.FromCtx(ctx).WarnError(err, "skipping JWK", "kid", jwk.Kid, "kty", jwk.Kty)
+			continue
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Wrapf(errInvalidJWK, "unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.Wrap(errInvalidJWK, "point is not on curve")
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, errors.Wrapf(errInvalidJWK, "unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrapf(errInvalidJWK, "decode base64url: %v", err)
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,342 @@
+package authkit_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/adipurnama/go-toolkit/authkit"
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+)
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	tok := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		tok.Header["kid"] = kid
+	}
+
+	s, err := tok.SignedString(key)
+	require.NoError(t, err)
+
+	return s
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "user-1",
+		"iss":   "https://issuer.test",
+		"aud":   []string{"orders"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"scope": "orders:read orders:write",
+		"roles": []string{"admin"},
+	}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func TestVerifierHMAC(t *testing.T) {
+	secret := []byte("s3cr3t")
+
+	v, err := authkit.NewVerifier(authkit.Config{
+		HMACSecret: secret,
+		Issuer:     "https://issuer.test",
+		Audience:   []string{"orders"},
+		ClockSkew:  time.Minute,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	claims, err := v.Verify(ctx, sign(t, jwt.SigningMethodHS256, secret, "", validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.True(t, claims.HasScopes("orders:read", "orders:write"))
+	assert.True(t, claims.HasRoles("admin"))
+	assert.False(t, claims.HasScopes("orders:delete"))
+
+	tests := map[string]func(c jwt.MapClaims){
+		"expired":        func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-2 * time.Minute).Unix() },
+		"missing exp":    func(c jwt.MapClaims) { delete(c, "exp") },
+		"not valid yet":  func(c jwt.MapClaims) { c["nbf"] = time.Now().Add(2 * time.Minute).Unix() },
+		"wrong issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.test" },
+		"wrong audience": func(c jwt.MapClaims) { c["aud"] = "payments" },
+	}
+
+	for name, mutate := range tests {
+		mutate := mutate
+
+		t.Run(name, func(t *testing.T) {
+			c := validClaims()
+			mutate(c)
+
+			_, err := v.Verify(ctx, sign(t, jwt.SigningMethodHS256, secret, "", c))
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("expired within clock skew", func(t *testing.T) {
+		c := validClaims()
+		c["exp"] = time.Now().Add(-30 * time.Second).Unix()
+
+		_, err := v.Verify(ctx, sign(t, jwt.SigningMethodHS256, secret, "", c))
+		assert.NoError(t, err)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		_, err := v.Verify(ctx, sign(t, jwt.SigningMethodHS256, []byte("other"), "", validClaims()))
+		assert.Error(t, err)
+	})
+}
+
+func TestVerifierPublicKey(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	v, err := authkit.NewVerifier(authkit.Config{PublicKey: &ecKey.PublicKey})
+	require.NoError(t, err)
+
+	_, err = v.Verify(context.Background(), sign(t, jwt.SigningMethodES256, ecKey, "", validClaims()))
+	assert.NoError(t, err)
+
+	// HMAC token must not be verified using public key
+	_, err = v.Verify(context.Background(), sign(t, jwt.SigningMethodHS256, []byte("secret"), "", validClaims()))
+	assert.Error(t, err)
+}
+
+func TestVerifierJWKSRotation(t *testing.T) {
+	key1, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	key2, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var (
+		rotated int32
+		fetches int32
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+
+		keys := []map[string]string{{
+			"kty": "RSA",
+			"kid": "rsa-1",
+			"use": "sig",
+			"n":   b64(key1.N.Bytes()),
+			"e":   b64(big.NewInt(int64(key1.E)).Bytes()),
+		}}
+
+		if atomic.LoadInt32(&rotated) == 1 {
+			keys = append(keys, map[string]string{
+				"kty": "EC",
+				"kid": "ec-2",
+				"crv": "P-256",
+				"x":   b64(key2.X.Bytes()),
+				"y":   b64(key2.Y.Bytes()),
+			})
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+	defer srv.Close()
+
+	v, err := authkit.NewVerifier(authkit.Config{
+		JWKSURL:                srv.URL,
+		JWKSMinRefreshInterval: time.Nanosecond,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = v.Verify(ctx, sign(t, jwt.SigningMethodRS256, key1, "rsa-1", validClaims()))
+	require.NoError(t, err)
+
+	_, err = v.Verify(ctx, sign(t, jwt.SigningMethodRS256, key1, "rsa-1", validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches), "cached keys must be used")
+
+	tokenEC := sign(t, jwt.SigningMethodES256, key2, "ec-2", validClaims())
+
+	_, err = v.Verify(ctx, tokenEC)
+	assert.Error(t, err, "unknown kid before rotation")
+
+	atomic.StoreInt32(&rotated, 1)
+
+	_, err = v.Verify(ctx, tokenEC)
+	assert.NoError(t, err, "unknown kid triggers refetch")
+}
+
+func TestVerifierJWKSUnavailable(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var (
+		healthy int32
+		fetches int32
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "rsa-1",
+			"n":   b64(key.N.Bytes()),
+			"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer srv.Close()
+
+	token := sign(t, jwt.SigningMethodRS256, key, "rsa-1", validClaims())
+
+	t.Run("fetch error is returned while no key is cached", func(t *testing.T) {
+		v, err := authkit.NewVerifier(authkit.Config{JWKSURL: srv.URL})
+		require.NoError(t, err)
+
+		_, err = v.Verify(context.Background(), token)
+		assert.ErrorIs(t, err, authkit.ErrJWKSUnavailable)
+
+		_, err = v.Verify(context.Background(), token)
+		assert.ErrorIs(t, err, authkit.ErrJWKSUnavailable, "failed fetch isn't retried immediately")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+
+		err = authkit.EchoMiddleware(v, nil)(func(echo.Context) error { return nil })(e.NewContext(req, httptest.NewRecorder()))
+		assert.Equal(t, apperrors.CodeUnavailable, apperrors.CodeOf(err))
+	})
+
+	t.Run("cancelled request doesn't fail the fetch", func(t *testing.T) {
+		atomic.StoreInt32(&healthy, 1)
+
+		v, err := authkit.NewVerifier(authkit.Config{JWKSURL: srv.URL})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = v.Verify(ctx, token)
+		assert.NoError(t, err)
+	})
+}
+
+func TestEchoMiddleware(t *testing.T) {
+	secret := []byte("s3cr3t")
+
+	v, err := authkit.NewVerifier(authkit.Config{HMACSecret: secret})
+	require.NoError(t, err)
+
+	e := echo.New()
+	handler := authkit.EchoMiddleware(v, nil)(authkit.RequireScopes("orders:write")(func(ctx echo.Context) error {
+		claims, ok := authkit.ClaimsFromContext(ctx.Request().Context())
+		if !ok {
+			return echo.ErrInternalServerError
+		}
+
+		return ctx.String(http.StatusOK, claims.Subject)
+	}))
+
+	call := func(authorization string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		if authorization != "" {
+			req.Header.Set(echo.HeaderAuthorization, authorization)
+		}
+
+		rec := httptest.NewRecorder()
+
+		return rec, handler(e.NewContext(req, rec))
+	}
+
+	rec, err := call("Bearer " + sign(t, jwt.SigningMethodHS256, secret, "", validClaims()))
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", rec.Body.String())
+
+	rec, err = call("")
+	assert.Equal(t, apperrors.CodeUnauthenticated, apperrors.CodeOf(err))
+	assert.Contains(t, rec.Header().Get(echo.HeaderWWWAuthenticate), "Bearer")
+
+	c := validClaims()
+	c["scope"] = "orders:read"
+
+	_, err = call("Bearer " + sign(t, jwt.SigningMethodHS256, secret, "", c))
+	assert.Equal(t, apperrors.CodePermissionDenied, apperrors.CodeOf(err))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	secret := []byte("s3cr3t")
+
+	v, err := authkit.NewVerifier(authkit.Config{HMACSecret: secret})
+	require.NoError(t, err)
+
+	interceptor := authkit.UnaryServerInterceptor(v, authkit.MethodPolicy{
+		"/orders.Service/":           {Scopes: []string{"orders:read"}},
+		"/orders.Service/Delete":     {Roles: []string{"superuser"}},
+		"/orders.Service/ListPublic": {Public: true},
+	})
+
+	handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		if claims, ok := authkit.ClaimsFromContext(ctx); ok {
+			return claims.Subject, nil
+		}
+
+		return "anonymous", nil
+	}
+
+	call := func(method, token string) (interface{}, codes.Code) {
+		ctx := context.Background()
+		if token != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))
+		}
+
+		resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+
+		return resp, status.Code(err)
+	}
+
+	token := sign(t, jwt.SigningMethodHS256, secret, "", validClaims())
+
+	resp, code := call("/orders.Service/Get", token)
+	assert.Equal(t, codes.OK, code)
+	assert.Equal(t, "user-1", resp)
+
+	_, code = call("/orders.Service/Get", "")
+	assert.Equal(t, codes.Unauthenticated, code)
+
+	_, code = call("/orders.Service/Delete", token)
+	assert.Equal(t, codes.PermissionDenied, code)
+
+	resp, code = call("/orders.Service/ListPublic", "")
+	assert.Equal(t, codes.OK, code)
+	assert.Equal(t, "anonymous", resp)
+}
@@ -0,0 +1,292 @@
+package authkit
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultClockSkew           = 30 * time.Second
+	defaultJWKSRefreshInterval = time.Hour
+	defaultJWKSMinRefresh      = time.Minute
+	defaultJWKSFetchTimeout    = 10 * time.Second
+)
+
+var (
+	errMissingToken       = errors.New("authkit: missing bearer token")
+	errNoVerificationKey  = errors.New("authkit: no key configured to verify token algorithm")
+	errInvalidIssuer      = errors.New("authkit: invalid token issuer")
+	errInvalidAudience    = errors.New("authkit: invalid token audience")
+	errTokenExpired       = errors.New("authkit: token is expired")
+	errTokenNotValidYet   = errors.New("authkit: token is not valid yet")
+	errMissingExpiry      = errors.New("authkit: token has no expiry")
+	errNoKeySource        = errors.New("authkit: HMACSecret, PublicKey or JWKSURL is required")
+	errUnsupportedKeyType = errors.New("authkit: unsupported public key type")
+)
+
+// Config is token Verifier configuration
+// default value:
+//   - ClockSkew: 30 seconds, tolerance for exp, nbf & iat check
+//   - Algorithms: all algorithms supported by configured key(s)
+//   - JWKSRefreshInterval: 1 hour, JWKS is also refetched when token has unknown `kid`
+//     at most once per JWKSMinRefreshInterval (1 minute)
+//   - JWKSFetchTimeout: 10 seconds, JWKS is fetched independently from the request being verified
+//
+// Issuer & Audience are only checked when set. Token must have `exp` claim.
+type Config struct {
+	Issuer                 string        `json:"issuer,omitempty"`
+	Audience               []string      `json:"audience,omitempty"`
+	ClockSkew              time.Duration `json:"clock_skew,omitempty"`
+	Algorithms             []string      `json:"algorithms,omitempty"`
+	HMACSecret             []byte        `json:"-"`
+	PublicKey              interface{}   `json:"-"`
+	JWKSURL                string        `json:"jwks_url,omitempty"`
+	JWKSRefreshInterval    time.Duration `json:"jwks_refresh_interval,omitempty"`
+	JWKSMinRefreshInterval time.Duration `json:"jwks_min_refresh_interval,omitempty"`
+	JWKSFetchTimeout       time.Duration `json:"jwks_fetch_timeout,omitempty"`
+	HTTPClient             *http.Client  `json:"-"`
+}
+
+// Verifier verifies JWT signature & its registered claims.
+type Verifier struct {
+	cfg    Config
+	jwks   *jwks
+	parser *jwt.Parser
+}
+
+// NewVerifier returns new *Verifier.
+func NewVerifier(cfg Config) (*Verifier, error) {
+	if cfg.HMACSecret == nil && cfg.PublicKey == nil && cfg.JWKSURL == "" {
+		return nil, errNoKeySource
+	}
+
+	if cfg.PublicKey != nil {
+		switch cfg.PublicKey.(type) {
+		case *rsa.PublicKey, *ecdsa.PublicKey:
+		default:
+			return nil, errors.Wrapf(errUnsupportedKeyType, "%T", cfg.PublicKey)
+		}
+	}
+
+	if cfg.ClockSkew <= 0 {
+		cfg.ClockSkew = defaultClockSkew
+	}
+
+	if cfg.JWKSRefreshInterval <= 0 {
+		cfg.JWKSRefreshInterval = defaultJWKSRefreshInterval
+	}
+
+	if cfg.JWKSMinRefreshInterval <= 0 {
+		cfg.JWKSMinRefreshInterval = defaultJWKSMinRefresh
+	}
+
+	if cfg.JWKSFetchTimeout <= 0 {
+		cfg.JWKSFetchTimeout = defaultJWKSFetchTimeout
+	}
+
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	opts := []jwt.ParserOption{
+		// registered claims are validated with clock skew in Verify
+		jwt.WithoutClaimsValidation(),
+	}
+
+	if len(cfg.Algorithms) > 0 {
+		opts = append(opts, jwt.WithValidMethods(cfg.Algorithms))
+	}
+
+	v := &Verifier{
+		cfg:    cfg,
+		parser: jwt.NewParser(opts...),
+	}
+
+	if cfg.JWKSURL != "" {
+		v.jwks = newJWKS(cfg.JWKSURL, cfg.HTTPClient, cfg.JWKSRefreshInterval, cfg.JWKSMinRefreshInterval, cfg.JWKSFetchTimeout)
+	}
+
+	return v, nil
+}
+
+// Verify parses & verifies raw token, returns its claims.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	if token == "" {
+		return nil, errMissingToken
+	}
+
+	mc := jwt.MapClaims{}
+
+	_, err := v.parser.ParseWithClaims(token, mc, func(t *jwt.Token) (interface{}, error) {
+		return v.key(ctx, t)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "authkit: parse token")
+	}
+
+	if err := v.validate(mc, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return newClaims(mc), nil
+}
+
+// key returns verification key for token's algorithm,
+// HMAC secret is never used for asymmetric algorithm & vice versa.
+func (v *Verifier) key(ctx context.Context, t *jwt.Token) (interface{}, error) {
+	switch t.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if v.cfg.HMACSecret == nil {
+			return nil, errNoVerificationKey
+		}
+
+		return v.cfg.HMACSecret, nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+	default:
+		return nil, errors.Wrapf(errNoVerificationKey, "alg %s", t.Method.Alg())
+	}
+
+	kid, _ := t.Header["kid"].(string)
+
+	var key interface{}
+
+	if v.jwks != nil {
+		k, err := v.jwks.key(ctx, kid)
+		if err != nil && v.cfg.PublicKey == nil {
+			return nil, err
+		}
+
+		key = k
+	}
+
+	if key == nil {
+		key = v.cfg.PublicKey
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey:
+		if _, ok := t.Method.(*jwt.SigningMethodECDSA); ok {
+			return nil, errors.Wrapf(errNoVerificationKey, "alg %s", t.Method.Alg())
+		}
+	case *ecdsa.PublicKey:
+		if _, ok := t.Method.(*jwt.SigningMethodECDSA); !ok {
+			return nil, errors.Wrapf(errNoVerificationKey, "alg %s", t.Method.Alg())
+		}
+	default:
+		return nil, errors.Wrapf(errNoVerificationKey, "alg %s", t.Method.Alg())
+	}
+
+	return key, nil
+}
+
+func (v *Verifier) validate(mc jwt.MapClaims, now time.Time) error {
+	skew := v.cfg.ClockSkew
+
+	exp, ok := numericDate(mc["exp"])
+	if !ok {
+		return errMissingExpiry
+	}
+
+	if now.After(exp.Add(skew)) {
+		return errors.Wrapf(errTokenExpired, "expired at %s", exp.Format(time.RFC3339))
+	}
+
+	if nbf, ok := numericDate(mc["nbf"]); ok && now.Add(skew).Before(nbf) {
+		return errTokenNotValidYet
+	}
+
+	if iat, ok := numericDate(mc["iat"]); ok && now.Add(skew).Before(iat) {
+		return errTokenNotValidYet
+	}
+
+	if v.cfg.Issuer != "" {
+		if iss, _ := mc["iss"].(string); iss != v.cfg.Issuer {
+			return errors.Wrapf(errInvalidIssuer, "got %q", iss)
+		}
+	}
+
+	if len(v.cfg.Audience) > 0 {
+		aud := stringList(mc["aud"], false)
+
+		for _, want := range v.cfg.Audience {
+			if containsAll(aud, []string{want}) {
+				return nil
+			}
+		}
+
+		return errors.Wrapf(errInvalidAudience, "got %v", aud)
+	}
+
+	return nil
+}
+
+func newClaims(mc jwt.MapClaims) *Claims {
+	c := &Claims{
+		Raw:      mc,
+		Audience: stringList(mc["aud"], false),
+		Roles:    stringList(mc["roles"], true),
+	}
+
+	c.Subject, _ = mc["sub"].(string)
+	c.Issuer, _ = mc["iss"].(string)
+
+	if exp, ok := numericDate(mc["exp"]); ok {
+		c.ExpiresAt = exp
+	}
+
+	if iat, ok := numericDate(mc["iat"]); ok {
+		c.IssuedAt = iat
+	}
+
+	if scope, ok := mc["scope"]; ok {
+		c.Scopes = stringList(scope, true)
+	} else {
+		c.Scopes = stringList(mc["scp"], true)
+	}
+
+	return c
+}
+
+func numericDate(v interface{}) (time.Time, bool) {
+	switch n := v.(type) {
+	case float64:
+		return time.Unix(int64(n), 0), true
+	case int64:
+		return time.Unix(n, 0), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// stringList returns claim value as list of string,
+// string value is split by space if splitSpace is true.
+func stringList(v interface{}, splitSpace bool) []string {
+	switch val := v.(type) {
+	case string:
+		if splitSpace {
+			return strings.Fields(val)
+		}
+
+		return []string{val}
+	case []interface{}:
+		result := make([]string, 0, len(val))
+
+		for _, item := range val {
+			result = append(result, fmt.Sprint(item))
+		}
+
+		return result
+	case []string:
+		return val
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,28 @@
+// Package cachekit provides tagged cache stores, in memory for single instance app
+// and redis for multiple instances. Used by `echokit.CacheMiddleware`.
+package cachekit
+
+import (
+	"context"
+	"time"
+)
+
+// Store is key value cache with tag based invalidation.
+// Services invalidate cached values, e.g. HTTP responses cached by echokit.CacheMiddleware,
+// after changing the underlying data:
+//
+//	_ = store.InvalidateTags(ctx, "product:42", "catalog")
+type Store interface {
+	// Get returns value of key, false when key is missing or expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores value of key for ttl, tagged with tags.
+	// Zero ttl stores it without expiry, negative ttl means it's already expired, so key is removed.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error
+
+	// Delete removes keys.
+	Delete(ctx context.Context, keys ...string) error
+
+	// InvalidateTags removes all keys tagged with any of tags.
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
@@ -0,0 +1,169 @@
+package cachekit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMemoryMaxEntries = 10000
+	memorySweepInterval     = time.Minute
+)
+
+// NewMemoryStore returns in-process Store holding up to maxEntries values, default 10000.
+// Expired values are removed every minute, when it's full arbitrary values are evicted.
+// Suitable for single instance app, or per instance cache of small data.
+func NewMemoryStore(maxEntries int) Store {
+	if maxEntries <= 0 {
+		maxEntries = defaultMemoryMaxEntries
+	}
+
+	return &memoryStore{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*memoryEntry),
+		tags:       make(map[string]map[string]struct{}),
+		now:        time.Now,
+	}
+}
+
+type memoryStore struct {
+	mu         sync.RWMutex
+	maxEntries int
+	entries    map[string]*memoryEntry
+	tags       map[string]map[string]struct{}
+	lastSweep  time.Time
+	now        func() time.Time
+}
+
+// memoryEntry is stored value, zero expiresAt means it doesn't expire.
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+	tags      []string
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// Get implements Store.
+func (m *memoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	e, ok := m.entries[key]
+	if !ok || e.expired(m.now()) {
+		return nil, false, nil
+	}
+
+	return e.value, true, nil
+}
+
+// Set implements Store.
+func (m *memoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	now := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(now)
+
+	m.remove(key)
+
+	if ttl < 0 {
+		return nil
+	}
+
+	for len(m.entries) >= m.maxEntries {
+		for k := range m.entries {
+			m.remove(k)
+			break
+		}
+	}
+
+	e := &memoryEntry{
+		value: value,
+		tags:  tags,
+	}
+
+	if ttl > 0 {
+		e.expiresAt = now.Add(ttl)
+	}
+
+	m.entries[key] = e
+
+	for _, t := range tags {
+		keys, ok := m.tags[t]
+		if !ok {
+			keys = make(map[string]struct{})
+			m.tags[t] = keys
+		}
+
+		keys[key] = struct{}{}
+	}
+
+	return nil
+}
+
+// Delete implements Store.
+func (m *memoryStore) Delete(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, k := range keys {
+		m.remove(k)
+	}
+
+	return nil
+}
+
+// InvalidateTags implements Store.
+func (m *memoryStore) InvalidateTags(_ context.Context, tags ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, t := range tags {
+		for k := range m.tags[t] {
+			m.remove(k)
+		}
+
+		delete(m.tags, t)
+	}
+
+	return nil
+}
+
+// remove deletes key & its tag index, m.mu must be held.
+func (m *memoryStore) remove(key string) {
+	e, ok := m.entries[key]
+	if !ok {
+		return
+	}
+
+	delete(m.entries, key)
+
+	for _, t := range e.tags {
+		keys := m.tags[t]
+		delete(keys, key)
+
+		if len(keys) == 0 {
+			delete(m.tags, t)
+		}
+	}
+}
+
+// sweep removes expired entries, at most once per minute, m.mu must be held.
+func (m *memoryStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < memorySweepInterval {
+		return
+	}
+
+	m.lastSweep = now
+
+	for k, e := range m.entries {
+		if e.expired(now) {
+			m.remove(k)
+		}
+	}
+}
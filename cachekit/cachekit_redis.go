@@ -0,0 +1,166 @@
+package cachekit
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRedisKeyPrefix = "cache:"
+	redisTagKeyPrefix     = "tag:"
+)
+
+// KEYS[1] tag set
+// ARGV[1] tagged key, ARGV[2] key ttl (ms), 0 when the key doesn't expire.
+// tag set lives as long as its longest living key, it doesn't expire once it has key without expiry.
+var tagScript = goredis.NewScript(`
+local ttl = tonumber(ARGV[2])
+local current = redis.call('PTTL', KEYS[1])
+
+redis.call('SADD', KEYS[1], ARGV[1])
+
+if ttl == 0 then
+	redis.call('PERSIST', KEYS[1])
+elseif current == -2 or (current >= 0 and current < ttl) then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+
+return 1
+`)
+
+// KEYS[1] tag set
+// returns tagged keys & removes the tag set atomically,
+// so keys tagged after this call are kept in a new tag set.
+var popTagScript = goredis.NewScript(`
+local keys = redis.call('SMEMBERS', KEYS[1])
+redis.call('DEL', KEYS[1])
+
+return keys
+`)
+
+type redisOptions struct {
+	keyPrefix string
+}
+
+// RedisOption sets options for redis Store.
+type RedisOption func(*redisOptions)
+
+// WithKeyPrefix returns RedisOption which sets redis key prefix,
+// default to `cache:`.
+func WithKeyPrefix(prefix string) RedisOption {
+	return func(o *redisOptions) {
+		if prefix != "" {
+			o.keyPrefix = prefix
+		}
+	}
+}
+
+// NewRedisStore returns Store keeping values in redis, shared by app instances.
+// Each tag is a redis set of its keys, scripts only touch a single key so it works on redis cluster.
+// client is usually created using `rediskit.NewRedisConnection`.
+func NewRedisStore(client goredis.Cmdable, o ...RedisOption) Store {
+	opts := redisOptions{
+		keyPrefix: defaultRedisKeyPrefix,
+	}
+
+	for _, o := range o {
+		o(&opts)
+	}
+
+	return &redisStore{
+		client:    client,
+		keyPrefix: opts.keyPrefix,
+	}
+}
+
+type redisStore struct {
+	client    goredis.Cmdable
+	keyPrefix string
+}
+
+// Get implements Store.
+func (r *redisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b, err := r.client.Get(ctx, r.keyPrefix+key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, errors.Wrap(err, "cachekit: redis get")
+	}
+
+	return b, true, nil
+}
+
+// Set implements Store.
+func (r *redisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	if ttl < 0 {
+		return r.Delete(ctx, key)
+	}
+
+	key = r.keyPrefix + key
+
+	_, err := r.client.Pipelined(ctx, func(p goredis.Pipeliner) error {
+		p.Set(ctx, key, value, ttl)
+
+		for _, t := range tags {
+			tagScript.Eval(ctx, p, []string{r.tagKey(t)}, key, ttl.Milliseconds())
+		}
+
+		return nil
+	})
+
+	return errors.Wrap(err, "cachekit: redis set")
+}
+
+// Delete implements Store.
+func (r *redisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, err := r.client.Pipelined(ctx, func(p goredis.Pipeliner) error {
+		// one key per command, keys may live on different cluster slots
+		for _, k := range keys {
+			p.Del(ctx, r.keyPrefix+k)
+		}
+
+		return nil
+	})
+
+	return errors.Wrap(err, "cachekit: redis delete")
+}
+
+// InvalidateTags implements Store.
+func (r *redisStore) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, t := range tags {
+		keys, err := popTagScript.Run(ctx, r.client, []string{r.tagKey(t)}).StringSlice()
+		if err != nil {
+			return errors.Wrapf(err, "cachekit: redis invalidate tag %s", t)
+		}
+
+		if len(keys) == 0 {
+			continue
+		}
+
+		_, err = r.client.Pipelined(ctx, func(p goredis.Pipeliner) error {
+			for _, k := range keys {
+				p.Del(ctx, k)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return errors.Wrapf(err, "cachekit: redis invalidate tag %s", t)
+		}
+	}
+
+	return nil
+}
+
+func (r *redisStore) tagKey(tag string) string {
+	return r.keyPrefix + redisTagKeyPrefix + tag
+}
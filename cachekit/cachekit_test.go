@@ -0,0 +1,142 @@
+package cachekit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/cachekit"
+)
+
+func TestStores(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+
+	defer mr.Close()
+
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	stores := map[string]cachekit.Store{
+		"memory": cachekit.NewMemoryStore(0),
+		"redis":  cachekit.NewRedisStore(client, cachekit.WithKeyPrefix("test:")),
+	}
+
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			_, ok, err := s.Get(ctx, "missing")
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			require.NoError(t, s.Set(ctx, "product:1", []byte("one"), time.Minute, "product:1", "catalog"))
+			require.NoError(t, s.Set(ctx, "product:2", []byte("two"), time.Minute, "product:2", "catalog"))
+			require.NoError(t, s.Set(ctx, "banner", []byte("banner"), time.Minute))
+
+			v, ok, err := s.Get(ctx, "product:1")
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, "one", string(v))
+
+			require.NoError(t, s.InvalidateTags(ctx, "product:1"))
+
+			_, ok, _ = s.Get(ctx, "product:1")
+			assert.False(t, ok, "tagged key is invalidated")
+
+			_, ok, _ = s.Get(ctx, "product:2")
+			assert.True(t, ok, "other key is kept")
+
+			require.NoError(t, s.InvalidateTags(ctx, "catalog", "unknown"))
+
+			_, ok, _ = s.Get(ctx, "product:2")
+			assert.False(t, ok)
+
+			require.NoError(t, s.Delete(ctx, "banner"))
+
+			_, ok, _ = s.Get(ctx, "banner")
+			assert.False(t, ok)
+		})
+	}
+
+	assert.False(t, mr.Exists("test:tag:catalog"), "invalidated tag set is removed")
+}
+
+func TestStoresWithoutExpiry(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+
+	defer mr.Close()
+
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	stores := map[string]cachekit.Store{
+		"memory": cachekit.NewMemoryStore(0),
+		"redis":  cachekit.NewRedisStore(client, cachekit.WithKeyPrefix("test:")),
+	}
+
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			require.NoError(t, s.Set(ctx, "short", []byte("v"), time.Second, "tag"))
+			require.NoError(t, s.Set(ctx, "forever", []byte("v"), 0, "tag"))
+			require.NoError(t, s.Set(ctx, "later", []byte("v"), time.Minute, "tag"))
+
+			for _, k := range []string{"short", "forever", "later"} {
+				_, ok, err := s.Get(ctx, k)
+				require.NoError(t, err)
+				assert.True(t, ok, "%s is stored", k)
+			}
+
+			if name == "redis" {
+				assert.True(t, mr.Exists("test:tag:tag"))
+				assert.Zero(t, mr.TTL("test:tag:tag"), "tag set of key without expiry doesn't expire")
+			}
+
+			require.NoError(t, s.Set(ctx, "forever", []byte("v"), -time.Second))
+
+			_, ok, err := s.Get(ctx, "forever")
+			require.NoError(t, err)
+			assert.False(t, ok, "negative ttl removes the key")
+
+			require.NoError(t, s.Set(ctx, "forever", []byte("v"), 0, "tag"))
+			require.NoError(t, s.InvalidateTags(ctx, "tag"))
+
+			for _, k := range []string{"short", "forever", "later"} {
+				_, ok, _ := s.Get(ctx, k)
+				assert.False(t, ok, "%s is invalidated", k)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreEviction(t *testing.T) {
+	ctx := context.Background()
+	s := cachekit.NewMemoryStore(2)
+
+	require.NoError(t, s.Set(ctx, "a", []byte("a"), time.Minute))
+	require.NoError(t, s.Set(ctx, "b", []byte("b"), time.Minute))
+	require.NoError(t, s.Set(ctx, "c", []byte("c"), time.Minute))
+
+	var n int
+
+	for _, k := range []string{"a", "b", "c"} {
+		if _, ok, _ := s.Get(ctx, k); ok {
+			n++
+		}
+	}
+
+	assert.Equal(t, 2, n)
+
+	require.NoError(t, s.Set(ctx, "expired", []byte("x"), -time.Second))
+
+	_, ok, _ := s.Get(ctx, "expired")
+	assert.False(t, ok)
+}
@@ -0,0 +1,397 @@
+// Package concurrencykit provides adaptive concurrency limiter for load shedding,
+// based on Netflix concurrency-limits AIMD & Gradient2 algorithms.
+//
+// The limit of concurrent requests is adjusted from measured latency,
+// requests above the limit are rejected early instead of queueing until they time out.
+package concurrencykit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/adipurnama/go-toolkit/config"
+)
+
+// Algorithm is limit adjustment algorithm.
+type Algorithm string
+
+const (
+	// AIMD increases the limit by 1 on success, and decreases it by BackoffRatio
+	// when request is dropped or slower than Timeout.
+	AIMD Algorithm = "aimd"
+
+	// Gradient adjusts the limit using ratio between long term & current latency,
+	// so it decreases as soon as latency grows, before requests time out.
+	Gradient Algorithm = "gradient"
+)
+
+// ErrInvalidPriority is returned by ParsePriority for unknown priority name.
+var ErrInvalidPriority = errors.New("concurrencykit: unknown priority")
+
+// Priority is request priority class. When in-flight requests approach the limit,
+// lower priority requests are rejected first, see Config.PriorityShares.
+type Priority int
+
+// supported priorities.
+const (
+	PriorityNormal Priority = iota
+	PriorityCritical
+	PrioritySheddable
+)
+
+// ParsePriority returns Priority of name: critical, normal or sheddable.
+func ParsePriority(name string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "critical":
+		return PriorityCritical, nil
+	case "normal", "":
+		return PriorityNormal, nil
+	case "sheddable":
+		return PrioritySheddable, nil
+	default:
+		return PriorityNormal, fmt.Errorf("%w %q", ErrInvalidPriority, name)
+	}
+}
+
+// String returns priority name, used as metric label.
+func (p Priority) String() string {
+	switch p {
+	case PriorityCritical:
+		return "critical"
+	case PriorityNormal:
+		return "normal"
+	case PrioritySheddable:
+		return "sheddable"
+	default:
+		return strconv.Itoa(int(p))
+	}
+}
+
+const (
+	defaultInitialLimit = 20
+	defaultMinLimit     = 1
+	defaultMaxLimit     = 1000
+	defaultBackoffRatio = 0.9
+	defaultTimeout      = 5 * time.Second
+	defaultTolerance    = 1.5
+	defaultSmoothing    = 0.2
+	defaultLongWindow   = 600
+	defaultQueueSize    = 4
+	defaultSubsystem    = "server"
+)
+
+// Config adaptive concurrency limiter configuration
+// default value:
+//   - Algorithm: Gradient
+//   - InitialLimit: 20, MinLimit: 1, MaxLimit: 1000
+//   - BackoffRatio: 0.9, limit multiplier when request is dropped
+//   - Timeout: 5 seconds, AIMD treats slower request as dropped
+//   - Tolerance: 1.5, Gradient tolerated ratio of current to long term latency before decreasing the limit
+//   - Smoothing: 0.2, Gradient limit change smoothing factor
+//   - PriorityShares: critical 1, normal 0.9, sheddable 0.5; share of the limit usable by the priority
+//   - Name: `server`, metrics subsystem, must be unique per Registerer
+//   - Registerer: prometheus.DefaultRegisterer
+type Config struct {
+	Algorithm      Algorithm             `json:"algorithm,omitempty"`
+	InitialLimit   int                   `json:"initial_limit,omitempty"`
+	MinLimit       int                   `json:"min_limit,omitempty"`
+	MaxLimit       int                   `json:"max_limit,omitempty"`
+	BackoffRatio   float64               `json:"backoff_ratio,omitempty"`
+	Timeout        time.Duration         `json:"timeout,omitempty"`
+	Tolerance      float64               `json:"tolerance,omitempty"`
+	Smoothing      float64               `json:"smoothing,omitempty"`
+	PriorityShares map[Priority]float64  `json:"priority_shares,omitempty"`
+	Name           string                `json:"name,omitempty"`
+	Registerer     prometheus.Registerer `json:"-"`
+}
+
+/*
+NewConfig returns *Config based on viper configuration
+with layout:
+
+	given config file contents:
+
+		concurrency-limit:
+		  algorithm: gradient
+		  initial-limit: 50
+		  min-limit: 10
+		  max-limit: 500
+		  timeout: 2s
+
+	call using `concurrencykit.NewConfig(v, "restapi.concurrency-limit")`.
+*/
+func NewConfig(cfg config.KVStore, path string) *Config {
+	return &Config{
+		Algorithm:    Algorithm(cfg.GetString(fmt.Sprintf("%s.algorithm", path))),
+		InitialLimit: cfg.GetInt(fmt.Sprintf("%s.initial-limit", path)),
+		MinLimit:     cfg.GetInt(fmt.Sprintf("%s.min-limit", path)),
+		MaxLimit:     cfg.GetInt(fmt.Sprintf("%s.max-limit", path)),
+		BackoffRatio: cfg.GetFloat64(fmt.Sprintf("%s.backoff-ratio", path)),
+		Timeout:      cfg.GetDuration(fmt.Sprintf("%s.timeout", path)),
+		Tolerance:    cfg.GetFloat64(fmt.Sprintf("%s.tolerance", path)),
+		Smoothing:    cfg.GetFloat64(fmt.Sprintf("%s.smoothing", path)),
+	}
+}
+
+// Limiter is adaptive concurrency limiter, safe for concurrent use.
+type Limiter struct {
+	cfg *Config
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	longRTT  float64
+
+	limitGauge    prometheus.Gauge
+	inFlightGauge prometheus.Gauge
+	rejected      *prometheus.CounterVec
+}
+
+// New returns Limiter using cfg.
+// It panics when another limiter with the same cfg.Name is registered to cfg.Registerer,
+// as their metrics would be mixed up.
+func New(cfg *Config) *Limiter {
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = Gradient
+	}
+
+	if cfg.Algorithm != AIMD && cfg.Algorithm != Gradient {
+		panic("concurrencykit: unsupported algorithm " + string(cfg.Algorithm))
+	}
+
+	if cfg.MinLimit <= 0 {
+		cfg.MinLimit = defaultMinLimit
+	}
+
+	if cfg.MaxLimit <= 0 {
+		cfg.MaxLimit = defaultMaxLimit
+	}
+
+	if cfg.InitialLimit <= 0 {
+		cfg.InitialLimit = defaultInitialLimit
+	}
+
+	if cfg.BackoffRatio <= 0 || cfg.BackoffRatio >= 1 {
+		cfg.BackoffRatio = defaultBackoffRatio
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	if cfg.Tolerance < 1 {
+		cfg.Tolerance = defaultTolerance
+	}
+
+	if cfg.Smoothing <= 0 || cfg.Smoothing > 1 {
+		cfg.Smoothing = defaultSmoothing
+	}
+
+	if cfg.PriorityShares == nil {
+		cfg.PriorityShares = map[Priority]float64{
+			PriorityCritical:  1,
+			PriorityNormal:    0.9,
+			PrioritySheddable: 0.5,
+		}
+	}
+
+	if cfg.Name == "" {
+		cfg.Name = defaultSubsystem
+	}
+
+	if cfg.Registerer == nil {
+		cfg.Registerer = prometheus.DefaultRegisterer
+	}
+
+	l := &Limiter{
+		cfg:   cfg,
+		limit: float64(cfg.InitialLimit),
+		limitGauge: registerCollector(cfg.Registerer, prometheus.NewGauge(prometheus.GaugeOpts{
+			Subsystem: cfg.Name,
+			Name:      "concurrency_limit",
+			Help:      "Current adaptive concurrency limit.",
+		})),
+		inFlightGauge: registerCollector(cfg.Registerer, prometheus.NewGauge(prometheus.GaugeOpts{
+			Subsystem: cfg.Name,
+			Name:      "concurrency_in_flight",
+			Help:      "Number of requests counted by concurrency limiter.",
+		})),
+		rejected: registerCollector(cfg.Registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Subsystem: cfg.Name,
+			Name:      "concurrency_rejected_total",
+			Help:      "Number of requests rejected by concurrency limiter.",
+		}, []string{"priority"})),
+	}
+
+	l.clampLimit()
+	l.limitGauge.Set(float64(l.Limit()))
+
+	return l
+}
+
+// Limit returns current concurrency limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return int(l.limit)
+}
+
+// InFlight returns number of acquired tokens not yet released.
+func (l *Limiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.inFlight
+}
+
+// Acquire returns Token when request with priority p is allowed,
+// it must be released using one of Token methods. Returns false when request should be rejected.
+func (l *Limiter) Acquire(p Priority) (*Token, bool) {
+	share, ok := l.cfg.PriorityShares[p]
+	if !ok {
+		share = l.cfg.PriorityShares[PriorityNormal]
+	}
+
+	l.mu.Lock()
+
+	// at least one request of any priority is allowed
+	allowed := float64(l.inFlight) < math.Max(1, math.Floor(l.limit*share))
+	if allowed {
+		l.inFlight++
+		l.inFlightGauge.Set(float64(l.inFlight))
+	}
+
+	l.mu.Unlock()
+
+	if !allowed {
+		l.rejected.WithLabelValues(p.String()).Inc()
+
+		return nil, false
+	}
+
+	return &Token{limiter: l, start: time.Now()}, true
+}
+
+// Token is acquired concurrency slot.
+type Token struct {
+	limiter *Limiter
+	start   time.Time
+	once    sync.Once
+}
+
+// Success releases t & samples request latency to adjust the limit.
+func (t *Token) Success() {
+	t.release(func(l *Limiter, rtt time.Duration, inFlight int) {
+		l.onSample(rtt, inFlight, false)
+	})
+}
+
+// Dropped releases t & decreases the limit, e.g. request timed out or downstream is overloaded.
+func (t *Token) Dropped() {
+	t.release(func(l *Limiter, rtt time.Duration, inFlight int) {
+		l.onSample(rtt, inFlight, true)
+	})
+}
+
+// Ignore releases t without adjusting the limit, e.g. request failed for reason unrelated to load.
+func (t *Token) Ignore() {
+	t.release(func(*Limiter, time.Duration, int) {})
+}
+
+func (t *Token) release(fn func(l *Limiter, rtt time.Duration, inFlight int)) {
+	t.once.Do(func() {
+		l := t.limiter
+		rtt := time.Since(t.start)
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		inFlight := l.inFlight
+		l.inFlight--
+		l.inFlightGauge.Set(float64(l.inFlight))
+
+		fn(l, rtt, inFlight)
+		l.clampLimit()
+		l.limitGauge.Set(math.Floor(l.limit))
+	})
+}
+
+// onSample adjusts the limit from request latency, inFlight is number of in-flight
+// requests when it's completed. must be called with l.mu held.
+func (l *Limiter) onSample(rtt time.Duration, inFlight int, dropped bool) {
+	if l.cfg.Algorithm == AIMD && rtt > l.cfg.Timeout {
+		dropped = true
+	}
+
+	if dropped {
+		l.limit *= l.cfg.BackoffRatio
+
+		return
+	}
+
+	// app limited, latency doesn't reflect the limit
+	if float64(inFlight) < l.limit/2 {
+		return
+	}
+
+	if l.cfg.Algorithm == AIMD {
+		l.limit++
+
+		return
+	}
+
+	l.gradient(float64(rtt))
+}
+
+// gradient implements Netflix Gradient2 limit.
+func (l *Limiter) gradient(shortRTT float64) {
+	if shortRTT <= 0 {
+		return
+	}
+
+	if l.longRTT == 0 {
+		l.longRTT = shortRTT
+	}
+
+	// exponential moving average of latency over long window
+	l.longRTT += (shortRTT - l.longRTT) * 2 / (defaultLongWindow + 1)
+
+	// latency has dropped e.g. after recovery, converge long term latency faster
+	if l.longRTT/shortRTT > 2 {
+		l.longRTT *= 0.95
+	}
+
+	gradient := math.Max(0.5, math.Min(1, l.cfg.Tolerance*l.longRTT/shortRTT))
+	newLimit := l.limit*gradient + defaultQueueSize
+
+	l.limit = l.limit*(1-l.cfg.Smoothing) + newLimit*l.cfg.Smoothing
+}
+
+// clampLimit must be called with l.mu held.
+func (l *Limiter) clampLimit() {
+	l.limit = math.Max(float64(l.cfg.MinLimit), math.Min(float64(l.cfg.MaxLimit), l.limit))
+}
+
+// registerCollector registers c to reg, panics when limiter with the same name is already registered.
+func registerCollector[T prometheus.Collector](reg prometheus.Registerer, c T) T {
+	err := reg.Register(c)
+
+	var errRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &errRegistered) {
+		panic("concurrencykit: limiter metrics are already registered, use unique Config.Name per registerer")
+	}
+
+	if err != nil {
+		panic("concurrencykit: failed to register limiter metrics: " + err.Error())
+	}
+
+	return c
+}
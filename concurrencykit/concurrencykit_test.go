@@ -0,0 +1,170 @@
+package concurrencykit_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/concurrencykit"
+)
+
+// saturate acquires all available critical tokens, holds them for d then releases them as success.
+func saturate(t *testing.T, l *concurrencykit.Limiter, d time.Duration) {
+	t.Helper()
+
+	var tokens []*concurrencykit.Token
+
+	for {
+		token, ok := l.Acquire(concurrencykit.PriorityCritical)
+		if !ok {
+			break
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	require.NotEmpty(t, tokens)
+
+	time.Sleep(d)
+
+	for _, token := range tokens {
+		token.Success()
+	}
+}
+
+func TestAIMD(t *testing.T) {
+	l := concurrencykit.New(&concurrencykit.Config{
+		Algorithm:    concurrencykit.AIMD,
+		InitialLimit: 10,
+		MaxLimit:     12,
+		Timeout:      time.Hour,
+		Registerer:   prometheus.NewRegistry(),
+	})
+
+	assert.Equal(t, 10, l.Limit())
+
+	saturate(t, l, 0)
+	assert.Equal(t, 12, l.Limit(), "limit increases up to MaxLimit")
+
+	token, ok := l.Acquire(concurrencykit.PriorityNormal)
+	require.True(t, ok)
+	token.Dropped()
+	assert.Equal(t, 10, l.Limit(), "limit backs off on drop")
+
+	// releasing twice has no effect
+	token.Dropped()
+	assert.Equal(t, 10, l.Limit())
+	assert.Equal(t, 0, l.InFlight())
+
+	// app limited success doesn't increase the limit
+	token, _ = l.Acquire(concurrencykit.PriorityNormal)
+	token.Success()
+	assert.Equal(t, 10, l.Limit())
+
+	// ignored request doesn't change the limit
+	token, _ = l.Acquire(concurrencykit.PriorityNormal)
+	token.Ignore()
+	assert.Equal(t, 10, l.Limit())
+}
+
+func TestAIMDTimeout(t *testing.T) {
+	l := concurrencykit.New(&concurrencykit.Config{
+		Algorithm:    concurrencykit.AIMD,
+		InitialLimit: 10,
+		Timeout:      time.Millisecond,
+		Registerer:   prometheus.NewRegistry(),
+	})
+
+	token, _ := l.Acquire(concurrencykit.PriorityNormal)
+
+	time.Sleep(5 * time.Millisecond)
+	token.Success()
+
+	assert.Equal(t, 9, l.Limit(), "request slower than timeout is counted as dropped")
+}
+
+func TestGradient(t *testing.T) {
+	l := concurrencykit.New(&concurrencykit.Config{
+		InitialLimit: 10,
+		MinLimit:     2,
+		Registerer:   prometheus.NewRegistry(),
+	})
+
+	for i := 0; i < 5; i++ {
+		saturate(t, l, 0)
+	}
+
+	grown := l.Limit()
+	assert.Greater(t, grown, 10, "limit grows while latency is stable")
+
+	for i := 0; i < 5; i++ {
+		saturate(t, l, 10*time.Millisecond)
+	}
+
+	assert.Less(t, l.Limit(), grown, "limit decreases when latency grows")
+	assert.GreaterOrEqual(t, l.Limit(), 2)
+}
+
+func TestPriority(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	l := concurrencykit.New(&concurrencykit.Config{
+		Algorithm:    concurrencykit.AIMD,
+		InitialLimit: 10,
+		Name:         "orders",
+		Registerer:   registry,
+	})
+
+	acquire := func(p concurrencykit.Priority) int {
+		n := 0
+
+		for {
+			if _, ok := l.Acquire(p); !ok {
+				return n
+			}
+
+			n++
+		}
+	}
+
+	assert.Equal(t, 5, acquire(concurrencykit.PrioritySheddable))
+	assert.Equal(t, 4, acquire(concurrencykit.PriorityNormal))
+	assert.Equal(t, 1, acquire(concurrencykit.PriorityCritical))
+	assert.Equal(t, 10, l.InFlight())
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP orders_concurrency_limit Current adaptive concurrency limit.
+# TYPE orders_concurrency_limit gauge
+orders_concurrency_limit 10
+# HELP orders_concurrency_rejected_total Number of requests rejected by concurrency limiter.
+# TYPE orders_concurrency_rejected_total counter
+orders_concurrency_rejected_total{priority="critical"} 1
+orders_concurrency_rejected_total{priority="normal"} 1
+orders_concurrency_rejected_total{priority="sheddable"} 1
+`), "orders_concurrency_limit", "orders_concurrency_rejected_total"))
+}
+
+func TestParsePriority(t *testing.T) {
+	p, err := concurrencykit.ParsePriority("Critical")
+	require.NoError(t, err)
+	assert.Equal(t, concurrencykit.PriorityCritical, p)
+	assert.Equal(t, "critical", p.String())
+
+	_, err = concurrencykit.ParsePriority("urgent")
+	assert.ErrorIs(t, err, concurrencykit.ErrInvalidPriority)
+}
+
+func TestDuplicateName(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	cfg := func() *concurrencykit.Config {
+		return &concurrencykit.Config{Name: "orders", Registerer: registry}
+	}
+
+	concurrencykit.New(cfg())
+
+	assert.Panics(t, func() { concurrencykit.New(cfg()) }, "limiters can't share metrics")
+}
@@ -1,13 +1,13 @@
 package echoapmkit
 
 import (
-	"fmt"
+	"context"
 	"net/http"
 	"reflect"
 	"runtime"
-	"runtime/debug"
 
-	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/echokit"
+	"github.com/adipurnama/go-toolkit/runtimekit"
 	echo "github.com/labstack/echo/v4"
 	"github.com/pinpoint-apm/pinpoint-go-agent"
 	phttp "github.com/pinpoint-apm/pinpoint-go-agent/plugin/http"
@@ -21,8 +21,8 @@ import (
 // RecoverMiddleware returns a new Echo middleware handler for tracing
 // requests and reporting errors.
 //
-// This middleware will recover and report panics, so it can
-// be used instead of echo/middleware.Recover.
+// This middleware will recover panics using echokit.RecoverMiddleware
+// and report them to APM, so it can be used instead of echo/middleware.Recover.
 //
 // By default, the middleware will use apm.DefaultTracer.
 // Use WithTracer to specify an alternative tracer.
@@ -48,8 +48,6 @@ func RecoverMiddleware(o ...APMOption) echo.MiddlewareFunc {
 	}
 }
 
-var errPanicInternal = errors.New("found panic while serving request")
-
 type middleware struct {
 	handler               echo.HandlerFunc
 	elasticTracer         *apm.Tracer
@@ -91,29 +89,18 @@ func (m *middleware) handle(c echo.Context) error {
 
 	resp.Status = http.StatusInternalServerError
 
-	var errHandler error
-
-	defer func() {
-		if v := recover(); v != nil {
-			errStack := debug.Stack()
-
-			err, ok := v.(error)
-			if !ok {
-				err = errors.Wrap(errPanicInternal, fmt.Sprint(v))
-			}
-
-			log.FromCtx(c.Request().Context()).Error(
-				err,
-				"recovered from panic",
-				"panic_stack", errStack,
-			)
-
-			c.Error(err)
+	var (
+		errHandler error
+		panicked   bool
+	)
 
+	recoverMiddleware := echokit.RecoverMiddleware(&echokit.RecoverConfig{
+		Reporter: func(_ context.Context, err *runtimekit.PanicError) {
+			panicked = true
 			resp.Status = http.StatusInternalServerError
 
 			if eBody != nil {
-				e := m.elasticTracer.Recovered(v)
+				e := m.elasticTracer.Recovered(err.Value)
 				e.SetTransaction(tx)
 				setContext(&e.Context, req, resp, eBody)
 				e.Send()
@@ -122,9 +109,11 @@ func (m *middleware) handle(c echo.Context) error {
 			if pTracer != nil {
 				pTracer.Span().SetError(err)
 			}
-		}
+		},
+	})
 
-		if errHandler != nil {
+	defer func() {
+		if errHandler != nil && !panicked {
 			if eBody != nil {
 				e := m.elasticTracer.NewError(errHandler)
 				setContext(&e.Context, req, resp, eBody)
@@ -152,7 +141,7 @@ func (m *middleware) handle(c echo.Context) error {
 		}
 	}()
 
-	errHandler = m.handler(c)
+	errHandler = recoverMiddleware(m.handler)(c)
 
 	if errHandler == nil {
 		if !resp.Committed {
@@ -0,0 +1,165 @@
+package echokit
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+// AccessLogFormat is field layout of access log entry.
+type AccessLogFormat string
+
+// supported access log formats.
+const (
+	// AccessLogFormatECS writes Elastic Common Schema fields, e.g. `http.request.method`, `url.path`, `client.ip`.
+	AccessLogFormatECS AccessLogFormat = "ecs"
+	// AccessLogFormatGCP writes Google Cloud Logging `httpRequest` field.
+	AccessLogFormatGCP AccessLogFormat = "gcp"
+)
+
+// AccessLogConfig access log middleware configuration
+// default value:
+//   - SampleRate: 1, all requests are logged
+//   - SlowThreshold: 0, slow request escalation is disabled
+//   - Format: AccessLogFormatECS
+//
+// failed (5xx) & slow requests are always logged regardless of SampleRate.
+type AccessLogConfig struct {
+	Skipper       middleware.Skipper `json:"-"`
+	SampleRate    float64            `json:"sample_rate,omitempty"`
+	SlowThreshold time.Duration      `json:"slow_threshold,omitempty"`
+	Format        AccessLogFormat    `json:"format,omitempty"`
+}
+
+// AccessLogMiddleware writes one log line per request containing method, route template,
+// status, latency, request & response size, client IP, user agent & request ID.
+// Request slower than SlowThreshold is logged in warn level, otherwise info.
+//
+// It should be registered after RequestIDLoggerMiddleware, so the entry contains request & trace ID.
+func AccessLogMiddleware(cfg *AccessLogConfig) echo.MiddlewareFunc {
+	if cfg == nil {
+		cfg = &AccessLogConfig{}
+	}
+
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+
+	if cfg.SampleRate <= 0 || cfg.SampleRate > 1 {
+		cfg.SampleRate = 1
+	}
+
+	if cfg.Format == "" {
+		cfg.Format = AccessLogFormatECS
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if cfg.Skipper(ctx) {
+				return next(ctx)
+			}
+
+			start := time.Now()
+
+			err := next(ctx)
+			if err != nil {
+				// write error response now to get its status & size
+				ctx.Error(err)
+			}
+
+			latency := time.Since(start)
+			status := ctx.Response().Status
+			slow := cfg.SlowThreshold > 0 && latency >= cfg.SlowThreshold
+
+			//nolint:gosec // sampling doesn't need crypto/rand
+			if !slow && status < http.StatusInternalServerError && cfg.SampleRate < 1 && rand.Float64() >= cfg.SampleRate {
+				return err
+			}
+
+			route := ctx.Path()
+			if route == "" {
+				route = ctx.Request().URL.Path
+			}
+
+			logger := log.FromCtx(ctx.Request().Context())
+			msg := fmt.Sprintf("%s %s - %d", ctx.Request().Method, route, status)
+			fields := accessLogFields(ctx, cfg.Format, route, latency)
+
+			if slow {
+				logger.Warn(msg+" - slow request", append(fields, "slow_threshold", cfg.SlowThreshold.String())...)
+
+				return err
+			}
+
+			logger.Info(msg, fields...)
+
+			return err
+		}
+	}
+}
+
+func accessLogFields(ctx echo.Context, format AccessLogFormat, route string, latency time.Duration) []interface{} {
+	req := ctx.Request()
+	resp := ctx.Response()
+
+	requestID := headerValue(ctx, web.HTTPKeyRequestID)
+
+	if format == AccessLogFormatGCP {
+		return []interface{}{
+			log.RawKey("httpRequest"), map[string]interface{}{
+				"requestMethod": req.Method,
+				"requestUrl":    log.MaskURL(req.URL.String()),
+				"requestSize":   strconv.FormatInt(requestSize(req), 10),
+				"status":        resp.Status,
+				"responseSize":  strconv.FormatInt(resp.Size, 10),
+				"userAgent":     req.UserAgent(),
+				"remoteIp":      web.GetIP(req),
+				"referer":       req.Referer(),
+				"latency":       fmt.Sprintf("%.9fs", latency.Seconds()),
+				"protocol":      req.Proto,
+			},
+			"route", route,
+			"request_id", requestID,
+		}
+	}
+
+	return []interface{}{
+		"http", map[string]interface{}{
+			"version": fmt.Sprintf("%d.%d", req.ProtoMajor, req.ProtoMinor),
+			"route":   route,
+			"request": map[string]interface{}{
+				"id":     requestID,
+				"method": req.Method,
+				"body":   map[string]interface{}{"bytes": requestSize(req)},
+			},
+			"response": map[string]interface{}{
+				"status_code": resp.Status,
+				"body":        map[string]interface{}{"bytes": resp.Size},
+			},
+		},
+		"url", map[string]interface{}{
+			"path":     req.URL.Path,
+			"original": log.MaskURL(req.URL.RequestURI()),
+		},
+		"client", map[string]interface{}{"ip": web.GetIP(req)},
+		"user_agent", map[string]interface{}{"original": req.UserAgent()},
+		"event", map[string]interface{}{"duration": latency.Nanoseconds()},
+	}
+}
+
+// requestSize returns request body size, or 0 if it's unknown.
+func requestSize(req *http.Request) int64 {
+	if req.ContentLength > 0 {
+		return req.ContentLength
+	}
+
+	return 0
+}
@@ -0,0 +1,117 @@
+package echokit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+	"github.com/adipurnama/go-toolkit/log"
+)
+
+func TestAccessLogMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			logger := &log.Logger{Level: log.LevelDebug, StdLog: zerolog.New(&buf)}
+			rCtx := log.AddToContext(ctx.Request().Context(), logger)
+			ctx.SetRequest(ctx.Request().WithContext(rCtx))
+
+			return next(ctx)
+		}
+	})
+
+	e.Use(echokit.AccessLogMiddleware(&echokit.AccessLogConfig{SlowThreshold: 50 * time.Millisecond}))
+
+	e.GET("/users/:id", func(ctx echo.Context) error {
+		if ctx.QueryParam("slow") != "" {
+			time.Sleep(60 * time.Millisecond)
+		}
+
+		return ctx.String(http.StatusOK, "hello")
+	})
+
+	e.GET("/missing", func(ctx echo.Context) error {
+		return echo.ErrNotFound
+	})
+
+	serve := func(path string) map[string]interface{} {
+		buf.Reset()
+
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("User-Agent", "test-agent")
+		req.Header.Set("X-Forwarded-For", "10.1.2.3")
+
+		e.ServeHTTP(httptest.NewRecorder(), req)
+
+		entry := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry), buf.String())
+
+		return entry
+	}
+
+	t.Run("ecs fields", func(t *testing.T) {
+		entry := serve("/users/42?token=secret")
+
+		assert.Equal(t, "info", entry["level"])
+		assert.Equal(t, "GET /users/:id - 200", entry["message"])
+
+		httpField := entry["http"].(map[string]interface{})
+		assert.Equal(t, "/users/:id", httpField["route"])
+		assert.EqualValues(t, 200, httpField["response"].(map[string]interface{})["status_code"])
+
+		urlField := entry["url"].(map[string]interface{})
+		assert.Equal(t, "/users/42", urlField["path"])
+		assert.NotContains(t, urlField["original"], "secret")
+
+		assert.Equal(t, "10.1.2.3", entry["client"].(map[string]interface{})["ip"])
+		assert.Equal(t, "test-agent", entry["user_agent"].(map[string]interface{})["original"])
+	})
+
+	t.Run("error response status", func(t *testing.T) {
+		entry := serve("/missing")
+
+		assert.Equal(t, "GET /missing - 404", entry["message"])
+	})
+
+	t.Run("slow request escalated to warn", func(t *testing.T) {
+		entry := serve("/users/42?slow=true")
+
+		assert.Equal(t, "warn", entry["level"])
+	})
+}
+
+func TestAccessLogMiddlewareGCPFormat(t *testing.T) {
+	var buf bytes.Buffer
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(`{"id":1}`))
+	req = req.WithContext(log.AddToContext(req.Context(), &log.Logger{Level: log.LevelDebug, StdLog: zerolog.New(&buf)}))
+	rec := httptest.NewRecorder()
+
+	mid := echokit.AccessLogMiddleware(&echokit.AccessLogConfig{Format: echokit.AccessLogFormatGCP})
+	err := mid(func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusCreated)
+	})(e.NewContext(req, rec))
+	require.NoError(t, err)
+
+	entry := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry), buf.String())
+
+	httpRequest, ok := entry["httpRequest"].(map[string]interface{})
+	require.True(t, ok, buf.String())
+	assert.Equal(t, "POST", httpRequest["requestMethod"])
+	assert.Equal(t, "8", httpRequest["requestSize"])
+	assert.EqualValues(t, http.StatusCreated, httpRequest["status"])
+	assert.Regexp(t, `^\d+\.\d{9}s$`, httpRequest["latency"])
+}
@@ -0,0 +1,306 @@
+package echokit
+
+import (
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	echo "github.com/labstack/echo/v4"
+
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+// translation keys for binding error messages.
+const (
+	bindKeyInvalidValue  = "bind.invalid_value"
+	bindKeyInvalidType   = "bind.invalid_type"
+	bindKeyMalformedBody = "bind.malformed_body"
+
+	bindKeyRequired          = "bind.required"
+	bindKeyContractViolation = "bind.contract_violation"
+
+	bindFieldBody = "body"
+)
+
+var bindMessages = map[string]map[string]string{
+	bindKeyInvalidValue: {
+		"en": "{0} has invalid value",
+		"id": "{0} memiliki nilai yang tidak valid",
+	},
+	bindKeyInvalidType: {
+		"en": "{0} must be a valid {1}",
+		"id": "{0} harus berupa {1} yang valid",
+	},
+	bindKeyMalformedBody: {
+		"en": "request body is malformed",
+		"id": "format body permintaan tidak valid",
+	},
+	bindKeyRequired: {
+		"en": "{0} is required",
+		"id": "{0} wajib diisi",
+	},
+	bindKeyContractViolation: {
+		"en": "{0} does not match API contract: {1}",
+		"id": "{0} tidak sesuai kontrak API: {1}",
+	},
+}
+
+// binding sources, in binding order.
+var bindSources = []string{"param", "query", "header", "body"}
+
+// Bind returns new T filled from request path params (`param` tag), query params (`query` tag),
+// headers (`header` tag) & body (`json`, `xml` or `form` tag), then validates it using echo.Validator.
+//
+// Both binding & validation failure returns *web.HTTPError (400) with field errors,
+// translated using translator from TranslatorMiddleware:
+//
+//	{"code":400,"message":"...","response":{"exception":"...","errors":[{"field":"age","message":"..."}]}}
+func Bind[T any](ctx echo.Context) (T, error) {
+	var req T
+
+	if err := bind(ctx, &req); err != nil {
+		return req, err
+	}
+
+	if ctx.Echo().Validator == nil {
+		return req, nil
+	}
+
+	if err := ctx.Validate(&req); err != nil {
+		return req, web.NewHTTPValidationError(ctx.Request().Context(), err)
+	}
+
+	return req, nil
+}
+
+// Handle adapts typed handler into echo.HandlerFunc.
+// request is bound & validated using Bind, successful response
+// is written as web.Response envelope with status 200.
+func Handle[Req any, Resp any](h func(ctx echo.Context, req Req) (Resp, error)) echo.HandlerFunc {
+	return HandleWithStatus(http.StatusOK, h)
+}
+
+// HandleWithStatus is Handle with custom success status code, e.g. http.StatusCreated.
+func HandleWithStatus[Req any, Resp any](code int, h func(ctx echo.Context, req Req) (Resp, error)) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		req, err := Bind[Req](ctx)
+		if err != nil {
+			return err
+		}
+
+		resp, err := h(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if ctx.Response().Committed {
+			return nil
+		}
+
+		return ctx.JSON(code, web.NewResponse(code, resp))
+	}
+}
+
+func bind(ctx echo.Context, req interface{}) error {
+	b := &echo.DefaultBinder{}
+
+	for _, source := range bindSources {
+		var err error
+
+		switch source {
+		case "param":
+			err = b.BindPathParams(ctx, req)
+		case "query":
+			err = b.BindQueryParams(ctx, req)
+		case "header":
+			err = b.BindHeaders(ctx, req)
+		default:
+			err = b.BindBody(ctx, req)
+		}
+
+		if err != nil {
+			return newBindError(ctx, req, source, err)
+		}
+	}
+
+	return nil
+}
+
+func newBindError(ctx echo.Context, req interface{}, source string, err error) error {
+	var errEcho *echo.HTTPError
+	if stdErrors.As(err, &errEcho) && errEcho.Code != http.StatusBadRequest {
+		// e.g. 415 unsupported media type
+		return err
+	}
+
+	trans := web.TranslatorFromContext(ctx.Request().Context())
+	field := web.ErrorField{Field: bindFieldBody, Message: translate(trans, bindKeyMalformedBody)}
+
+	var (
+		errType   *json.UnmarshalTypeError
+		errSyntax *json.SyntaxError
+	)
+
+	switch {
+	case stdErrors.As(err, &errType):
+		name := errType.Field
+		if name == "" {
+			name = bindFieldBody
+		}
+
+		field = web.ErrorField{Field: name, Message: translate(trans, bindKeyInvalidType, name, errType.Type.String())}
+	case stdErrors.As(err, &errSyntax):
+	default:
+		tag := source
+		if source == "body" {
+			tag = "form"
+		}
+
+		if name := invalidField(ctx, reflect.TypeOf(req).Elem(), tag); name != "" {
+			field = web.ErrorField{Field: name, Message: translate(trans, bindKeyInvalidValue, name)}
+		}
+	}
+
+	return &web.HTTPError{
+		Code:    http.StatusBadRequest,
+		Message: field.Message,
+		Response: web.ErrorDetails{
+			Exception: "request binding error found",
+			Errors:    []web.ErrorField{field},
+		},
+	}
+}
+
+// invalidField returns name of the first field tagged with tag which value can't be bound.
+// each field is probed by binding its value alone into single field struct.
+func invalidField(ctx echo.Context, typ reflect.Type, tag string) string {
+	if typ.Kind() != reflect.Struct {
+		return ""
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+
+		name := f.Tag.Get(tag)
+		if name == "" {
+			if name := invalidField(ctx, f.Type, tag); name != "" {
+				return name
+			}
+
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		vals := bindValues(ctx, tag, name)
+		if len(vals) == 0 {
+			continue
+		}
+
+		probe := reflect.New(reflect.StructOf([]reflect.StructField{{
+			Name: "V",
+			Type: f.Type,
+			Tag:  reflect.StructTag(fmt.Sprintf("%s:%q", tag, name)),
+		}})).Interface()
+
+		if err := bindProbe(ctx, probe, tag, name, vals); err != nil {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func bindValues(ctx echo.Context, tag, name string) []string {
+	switch tag {
+	case "param":
+		for i, n := range ctx.ParamNames() {
+			if n == name && i < len(ctx.ParamValues()) {
+				return []string{ctx.ParamValues()[i]}
+			}
+		}
+
+		return nil
+	case "query":
+		return ctx.QueryParams()[name]
+	case "header":
+		return ctx.Request().Header.Values(name)
+	default:
+		params, err := ctx.FormParams()
+		if err != nil {
+			return nil
+		}
+
+		return params[name]
+	}
+}
+
+func bindProbe(ctx echo.Context, probe interface{}, tag, name string, vals []string) error {
+	orig := ctx.Request()
+
+	req, _ := http.NewRequestWithContext(orig.Context(), orig.Method, "/", http.NoBody)
+	pCtx := ctx.Echo().NewContext(req, nil)
+	b := &echo.DefaultBinder{}
+
+	switch tag {
+	case "param":
+		pCtx.SetParamNames(name)
+		pCtx.SetParamValues(vals[0])
+
+		return b.BindPathParams(pCtx, probe)
+	case "query":
+		req.URL.RawQuery = url.Values{name: vals}.Encode()
+
+		return b.BindQueryParams(pCtx, probe)
+	case "header":
+		req.Header[name] = vals
+
+		return b.BindHeaders(pCtx, probe)
+	default:
+		body := url.Values{name: vals}.Encode()
+		req.Body = io.NopCloser(strings.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+
+		return b.BindBody(pCtx, probe)
+	}
+}
+
+func translate(trans ut.Translator, key string, params ...string) string {
+	if trans != nil {
+		if msg, err := trans.T(key, params...); err == nil && msg != "" {
+			return msg
+		}
+	}
+
+	msg := bindMessages[key][web.DefaultLocale]
+	for i, p := range params {
+		msg = strings.ReplaceAll(msg, fmt.Sprintf("{%d}", i), p)
+	}
+
+	return msg
+}
+
+// registerBindTranslations adds binding error messages into trans.
+func registerBindTranslations(trans ut.Translator) error {
+	for key, messages := range bindMessages {
+		msg, ok := messages[trans.Locale()]
+		if !ok {
+			msg = messages[web.DefaultLocale]
+		}
+
+		if err := trans.Add(key, msg, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package echokit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	validator "github.com/go-playground/validator/v10"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+type updateUserRequest struct {
+	ID       int    `param:"id"`
+	DryRun   bool   `query:"dry_run"`
+	TenantID string `header:"X-Tenant-ID" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+	Age      int    `json:"age" validate:"gte=17"`
+}
+
+type updateUserResponse struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Tenant string `json:"tenant"`
+	DryRun bool   `json:"dry_run"`
+}
+
+func TestBindAndHandle(t *testing.T) {
+	v := validator.New()
+
+	e := echo.New()
+	e.Validator = web.NewValidator(v)
+	e.Use(echokit.ValidatorTranslatorMiddleware(v))
+	e.HTTPErrorHandler = func(err error, ctx echo.Context) {
+		var errHTTP *web.HTTPError
+		if errors.As(err, &errHTTP) {
+			_ = ctx.JSON(errHTTP.Code, errHTTP)
+			return
+		}
+
+		e.DefaultHTTPErrorHandler(err, ctx)
+	}
+
+	e.PUT("/users/:id", echokit.Handle(func(ctx echo.Context, req updateUserRequest) (updateUserResponse, error) {
+		return updateUserResponse{ID: req.ID, Name: req.Name, Tenant: req.TenantID, DryRun: req.DryRun}, nil
+	}))
+
+	call := func(path, body string, header ...string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		result := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result), rec.Body.String())
+
+		return rec.Code, result
+	}
+
+	errorFields := func(result map[string]interface{}) []interface{} {
+		return result["response"].(map[string]interface{})["errors"].([]interface{})
+	}
+
+	t.Run("binds path, query, header & body", func(t *testing.T) {
+		code, result := call("/users/7?dry_run=true", `{"name":"joe","age":20}`, "X-Tenant-ID", "acme")
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "OK", result["status"])
+		assert.Equal(t, map[string]interface{}{
+			"id": float64(7), "name": "joe", "tenant": "acme", "dry_run": true,
+		}, result["data"])
+	})
+
+	t.Run("invalid path param", func(t *testing.T) {
+		code, result := call("/users/abc", `{"name":"joe","age":20}`, "X-Tenant-ID", "acme")
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, map[string]interface{}{
+			"field": "id", "message": "id has invalid value",
+		}, errorFields(result)[0])
+	})
+
+	t.Run("invalid query param", func(t *testing.T) {
+		_, result := call("/users/1?dry_run=maybe", `{"name":"joe","age":20}`, "X-Tenant-ID", "acme")
+
+		assert.Equal(t, "dry_run", errorFields(result)[0].(map[string]interface{})["field"])
+	})
+
+	t.Run("json type mismatch is translated", func(t *testing.T) {
+		code, result := call("/users/1", `{"name":"joe","age":"old"}`, "X-Tenant-ID", "acme", "Accept-Lang", "id")
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, map[string]interface{}{
+			"field": "age", "message": "age harus berupa int yang valid",
+		}, errorFields(result)[0])
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		_, result := call("/users/1", `{"name":`, "X-Tenant-ID", "acme")
+
+		assert.Equal(t, "body", errorFields(result)[0].(map[string]interface{})["field"])
+	})
+
+	t.Run("validation errors use the same format", func(t *testing.T) {
+		code, result := call("/users/1", `{"name":"joe","age":10}`)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Len(t, errorFields(result), 2)
+	})
+}
@@ -0,0 +1,219 @@
+package echokit
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"github.com/adipurnama/go-toolkit/log"
+)
+
+const (
+	defaultBodyDumpMaxSize = 4 * 1024
+	// maxDecodedBodySize limits gzip decoded body, so masking doesn't read unbounded data.
+	maxDecodedBodySize = 1024 * 1024
+)
+
+// BodyDumpConfig body dump middleware configuration
+// default value:
+//   - MaxBodySize: 4KB, longer body is truncated with `...[TRUNCATED n bytes]` marker
+//   - IsSensitiveKey: log.IsSensitiveParam, used to mask JSON & form body values
+//
+// multipart & binary bodies are never dumped, gzip encoded bodies are decoded first
+// and sensitive headers (log.IsSensitiveHeader) are masked.
+type BodyDumpConfig struct {
+	Skipper        middleware.Skipper    `json:"-"`
+	MaxBodySize    int                   `json:"max_body_size,omitempty"`
+	IsSensitiveKey func(key string) bool `json:"-"`
+}
+
+// BodyDumpHandler logs incoming request & outgoing response body.
+func BodyDumpHandler(skipper middleware.Skipper) echo.MiddlewareFunc {
+	return BodyDumpHandlerWithConfig(&BodyDumpConfig{Skipper: skipper})
+}
+
+// BodyDumpHandlerWithConfig logs incoming request & outgoing response body in debug level.
+func BodyDumpHandlerWithConfig(cfg *BodyDumpConfig) echo.MiddlewareFunc {
+	if cfg == nil {
+		cfg = &BodyDumpConfig{}
+	}
+
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultBodyDumpMaxSize
+	}
+
+	if cfg.IsSensitiveKey == nil {
+		cfg.IsSensitiveKey = log.IsSensitiveParam
+	}
+
+	d := &bodyDumper{cfg: cfg}
+
+	return middleware.BodyDumpWithConfig(
+		middleware.BodyDumpConfig{
+			Skipper: func(c echo.Context) bool {
+				// SSE & websocket responses must not be buffered
+				return isStreamingRequest(c.Request()) || cfg.Skipper(c)
+			},
+			Handler: d.handle,
+		},
+	)
+}
+
+type bodyDumper struct {
+	cfg *BodyDumpConfig
+}
+
+func (d *bodyDumper) handle(c echo.Context, reqBody []byte, respBody []byte) {
+	req := c.Request()
+	respHeader := c.Response().Header()
+	l := log.FromCtx(req.Context())
+	msg := fmt.Sprintf("%s %s - http request completed", req.Method, req.URL.Path)
+
+	l.Debug(msg,
+		"http.response", d.body(respBody, respHeader.Get(echo.HeaderContentType), respHeader.Get(echo.HeaderContentEncoding)),
+		"http.request", d.body(reqBody, req.Header.Get(echo.HeaderContentType), req.Header.Get(echo.HeaderContentEncoding)),
+		"http.status_code", c.Response().Status,
+		"http.header", maskHeader(req.Header),
+	)
+}
+
+// body returns loggable representation of body.
+func (d *bodyDumper) body(b []byte, contentType, contentEncoding string) string {
+	if len(b) == 0 {
+		return ""
+	}
+
+	if strings.EqualFold(contentEncoding, "gzip") {
+		decoded, err := gunzip(b)
+		if err != nil {
+			return fmt.Sprintf("[invalid gzip body, %d bytes]", len(b))
+		}
+
+		b = decoded
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case isJSONMediaType(mediaType):
+		b = d.maskJSON(b)
+	case mediaType == echo.MIMEApplicationForm:
+		b = d.maskForm(b)
+	case mediaType == "" || strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "xml"):
+		if !utf8.Valid(b) {
+			return fmt.Sprintf("[binary body, %d bytes]", len(b))
+		}
+	default:
+		return fmt.Sprintf("[%s body, %d bytes]", mediaType, len(b))
+	}
+
+	return truncate(strings.TrimSuffix(string(b), "\n"), d.cfg.MaxBodySize)
+}
+
+func (d *bodyDumper) maskJSON(b []byte) []byte {
+	var v interface{}
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return b
+	}
+
+	masked, err := json.Marshal(d.maskValue(v))
+	if err != nil {
+		return b
+	}
+
+	return masked
+}
+
+func (d *bodyDumper) maskValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if d.cfg.IsSensitiveKey(k) {
+				val[k] = log.RedactionString
+				continue
+			}
+
+			val[k] = d.maskValue(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = d.maskValue(item)
+		}
+	}
+
+	return v
+}
+
+func (d *bodyDumper) maskForm(b []byte) []byte {
+	values, err := url.ParseQuery(string(b))
+	if err != nil {
+		return b
+	}
+
+	for k := range values {
+		if d.cfg.IsSensitiveKey(k) {
+			values.Set(k, log.RedactionString)
+		}
+	}
+
+	return []byte(values.Encode())
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == echo.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json")
+}
+
+func gunzip(b []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close()
+
+	return io.ReadAll(io.LimitReader(r, maxDecodedBodySize))
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+
+	// don't cut in the middle of UTF-8 character
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	return fmt.Sprintf("%s...[TRUNCATED %d bytes]", s[:cut], len(s)-cut)
+}
+
+func maskHeader(h http.Header) http.Header {
+	result := make(http.Header, len(h))
+
+	for k, vv := range h {
+		if log.IsSensitiveHeader(k) {
+			result[k] = []string{log.RedactionString}
+			continue
+		}
+
+		result[k] = vv
+	}
+
+	return result
+}
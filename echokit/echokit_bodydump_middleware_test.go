@@ -0,0 +1,84 @@
+package echokit_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+	"github.com/adipurnama/go-toolkit/log"
+)
+
+func TestBodyDumpHandler(t *testing.T) {
+	var buf bytes.Buffer
+
+	e := echo.New()
+	mid := echokit.BodyDumpHandlerWithConfig(&echokit.BodyDumpConfig{MaxBodySize: 64})
+
+	dump := func(req *http.Request, h echo.HandlerFunc) map[string]interface{} {
+		buf.Reset()
+
+		logger := &log.Logger{Level: log.LevelDebug, StdLog: zerolog.New(&buf)}
+		req = req.WithContext(log.AddToContext(req.Context(), logger))
+
+		require.NoError(t, mid(h)(e.NewContext(req, httptest.NewRecorder())))
+
+		entry := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry), buf.String())
+
+		return entry
+	}
+
+	t.Run("masks JSON keys & sensitive headers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"joe","password":"p4ss"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer abc")
+
+		entry := dump(req, func(ctx echo.Context) error {
+			return ctx.JSON(http.StatusOK, map[string]string{"access_token": "t0k3n", "user": "joe"})
+		})
+
+		assert.Equal(t, `{"password":"[FILTERED]","username":"joe"}`, entry["http_request"])
+		assert.Equal(t, `{"access_token":"[FILTERED]","user":"joe"}`, entry["http_response"])
+		assert.NotContains(t, buf.String(), "Bearer abc")
+	})
+
+	t.Run("skips multipart & binary body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("--boundary\r\n..."))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEMultipartForm+"; boundary=boundary")
+
+		entry := dump(req, func(ctx echo.Context) error {
+			return ctx.Blob(http.StatusOK, "image/png", []byte{0x89, 'P', 'N', 'G'})
+		})
+
+		assert.Equal(t, "[multipart/form-data body, 15 bytes]", entry["http_request"])
+		assert.Equal(t, "[image/png body, 4 bytes]", entry["http_response"])
+	})
+
+	t.Run("decodes gzip & truncates long body", func(t *testing.T) {
+		var gz bytes.Buffer
+
+		w := gzip.NewWriter(&gz)
+		_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+		_ = w.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/text", &gz)
+		req.Header.Set(echo.HeaderContentType, echo.MIMETextPlain)
+		req.Header.Set(echo.HeaderContentEncoding, "gzip")
+
+		entry := dump(req, func(ctx echo.Context) error {
+			return ctx.NoContent(http.StatusNoContent)
+		})
+
+		assert.Equal(t, strings.Repeat("a", 64)+"...[TRUNCATED 36 bytes]", entry["http_request"])
+	})
+}
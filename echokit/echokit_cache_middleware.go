@@ -0,0 +1,428 @@
+package echokit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/adipurnama/go-toolkit/cachekit"
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/tenantkit"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+const (
+	// HeaderXCache is response header telling whether response is served from cache, `HIT` or `MISS`.
+	HeaderXCache = "X-Cache"
+
+	// CacheKeyPrefix is prefix of response cache keys.
+	CacheKeyPrefix = "http:"
+
+	defaultCacheTTL = time.Minute
+
+	headerETag        = "ETag"
+	headerIfNoneMatch = "If-None-Match"
+	headerAge         = "Age"
+
+	contextKeyCacheTags = "echokit.cacheTags"
+)
+
+// CacheConfig response cache middleware configuration
+// default value:
+//   - TTL: 1 minute, unless response has Cache-Control max-age / s-maxage
+//   - KeyFunc: route template, method, path, sorted query, VaryHeaders values & tenant ID, see tenantkit
+//   - Tags: none, handlers can add tags using AddCacheTags
+//   - CredentialHeaders: Authorization, Cookie & X-API-Key
+//   - middleware.DefaultSkipper / apply to all GET & HEAD requests, except SSE & websocket
+//
+// Use cachekit.NewMemoryStore for single instance app or cachekit.NewRedisStore shared by instances.
+// Services invalidate cached responses using Store.InvalidateTags after changing the data.
+type CacheConfig struct {
+	Store             cachekit.Store              `json:"-"`
+	TTL               time.Duration               `json:"ttl,omitempty"`
+	VaryHeaders       []string                    `json:"vary_headers,omitempty"`
+	CredentialHeaders []string                    `json:"credential_headers,omitempty"`
+	KeyFunc           func(echo.Context) string   `json:"-"`
+	Tags              func(echo.Context) []string `json:"-"`
+	Skipper           middleware.Skipper          `json:"-"`
+}
+
+type cachedResponse struct {
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header,omitempty"`
+	Body     []byte      `json:"body,omitempty"`
+	StoredAt int64       `json:"stored_at"`
+}
+
+// cacheFill is result of a cache miss shared with concurrent requests of the same key,
+// entry is nil when response can't be cached. panicked is handler's recovered panic value.
+type cacheFill struct {
+	entry    *cachedResponse
+	panicked interface{}
+}
+
+// AddCacheTags adds cache tags to current request's response, e.g. IDs of returned entities,
+// so services can invalidate it using cachekit.Store.InvalidateTags.
+func AddCacheTags(ctx echo.Context, tags ...string) {
+	existing, _ := ctx.Get(contextKeyCacheTags).([]string)
+	ctx.Set(contextKeyCacheTags, append(existing, tags...))
+}
+
+// CacheMiddleware caches GET & HEAD responses in cfg.Store & answers `If-None-Match` with 304.
+//
+// 200 responses get ETag from body hash, unless handler sets it. Only 200 responses are stored,
+// except response with Cache-Control no-store, no-cache or private, Set-Cookie header,
+// or request with CredentialHeaders, e.g. Authorization or Cookie, unless response is Cache-Control public or s-maxage.
+// Request Cache-Control no-store bypasses the cache, no-cache or max-age=0 fetches fresh response.
+// Concurrent misses of the same key are served by a single handler call.
+// Register it after tenantkit.EchoMiddleware, so cache keys contain the tenant.
+func CacheMiddleware(cfg *CacheConfig) echo.MiddlewareFunc {
+	if cfg.Store == nil {
+		panic("echokit: CacheConfig.Store cannot be nil")
+	}
+
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultCacheTTL
+	}
+
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = func(ctx echo.Context) string {
+			return defaultCacheKey(ctx, cfg.VaryHeaders)
+		}
+	}
+
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+
+	if len(cfg.CredentialHeaders) == 0 {
+		cfg.CredentialHeaders = []string{echo.HeaderAuthorization, echo.HeaderCookie, "X-API-Key"}
+	}
+
+	c := &responseCache{cfg: cfg}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+
+			if cfg.Skipper(ctx) || isStreamingRequest(req) ||
+				(req.Method != http.MethodGet && req.Method != http.MethodHead) {
+				return next(ctx)
+			}
+
+			reqCC := parseCacheControl(req.Header.Get(echo.HeaderCacheControl))
+			if _, noStore := reqCC["no-store"]; noStore {
+				return next(ctx)
+			}
+
+			key := cfg.KeyFunc(ctx)
+
+			if _, noCache := reqCC["no-cache"]; !noCache && reqCC["max-age"] != "0" {
+				if entry, ok := c.load(ctx, key); ok {
+					return writeCachedResponse(ctx, entry)
+				}
+			}
+
+			return c.fill(ctx, next, key)
+		}
+	}
+}
+
+type responseCache struct {
+	cfg   *CacheConfig
+	group singleflight.Group
+}
+
+func (c *responseCache) load(ctx echo.Context, key string) (*cachedResponse, bool) {
+	rCtx := ctx.Request().Context()
+
+	b, ok, err := c.cfg.Store.Get(rCtx, key)
+	if err != nil {
+		log.FromCtx(rCtx).WarnError(err, "failed to load cached response", "cache_key", key)
+
+		return nil, false
+	}
+
+	if !ok {
+		return nil, false
+	}
+
+	var entry cachedResponse
+	if err := json.Unmarshal(b, &entry); err != nil {
+		log.FromCtx(rCtx).WarnError(err, "failed to decode cached response", "cache_key", key)
+
+		return nil, false
+	}
+
+	return &entry, true
+}
+
+// fill calls next once for concurrent requests of key, the caller running next gets its own response,
+// others get the cached response or call next themselves when it can't be cached.
+//
+// singleflight re-panics handler's panic in a new goroutine when other callers are waiting,
+// crashing the app, so it's recovered & panicked again in the caller goroutine only,
+// where recover middleware can handle it.
+func (c *responseCache) fill(ctx echo.Context, next echo.HandlerFunc, key string) error {
+	var (
+		leader  bool
+		errNext error
+	)
+
+	v, _, _ := c.group.Do(key, func() (result interface{}, _ error) {
+		leader = true
+
+		defer func() {
+			if r := recover(); r != nil {
+				result = cacheFill{panicked: r}
+			}
+		}()
+
+		var fill cacheFill
+
+		fill.entry, errNext = c.handleMiss(ctx, next, key)
+
+		return fill, nil
+	})
+
+	fill := v.(cacheFill)
+
+	if leader {
+		if fill.panicked != nil {
+			panic(fill.panicked)
+		}
+
+		return errNext
+	}
+
+	if fill.entry != nil {
+		return writeCachedResponse(ctx, fill.entry)
+	}
+
+	return next(ctx)
+}
+
+// handleMiss serves request using next, storing the response when it's cacheable.
+// next's error is handled here, so the returned error is only about writing the response.
+func (c *responseCache) handleMiss(ctx echo.Context, next echo.HandlerFunc, key string) (*cachedResponse, error) {
+	req := ctx.Request()
+	resp := ctx.Response()
+
+	// buffer response, ETag is computed from the body before it's written
+	w := resp.Writer
+	buf := &bufferedResponseWriter{ResponseWriter: w}
+	resp.Writer = buf
+
+	// restore writer when next panics, so recover middleware's response isn't buffered
+	defer func() { resp.Writer = w }()
+
+	if err := next(ctx); err != nil {
+		// write error response now, so it's buffered as well
+		ctx.Error(err)
+	}
+
+	resp.Writer = w
+
+	if !resp.Committed {
+		return nil, nil
+	}
+
+	h := resp.Header()
+	body := buf.body.Bytes()
+
+	if resp.Status == http.StatusOK && h.Get(headerETag) == "" {
+		sum := sha256.Sum256(body)
+		h.Set(headerETag, `"`+hex.EncodeToString(sum[:16])+`"`)
+	}
+
+	var entry *cachedResponse
+
+	if ttl, ok := c.cacheTTL(req, resp); ok {
+		entry = &cachedResponse{
+			Status:   resp.Status,
+			Header:   cacheableHeader(h),
+			Body:     body,
+			StoredAt: time.Now().Unix(),
+		}
+
+		c.store(ctx, key, entry, ttl)
+	}
+
+	h.Set(HeaderXCache, "MISS")
+
+	// response status & size are already recorded by echo.Response using buffered writer
+	if resp.Status == http.StatusOK && etagMatch(req.Header.Get(headerIfNoneMatch), h.Get(headerETag)) {
+		resp.Status = http.StatusNotModified
+		resp.Size = 0
+		w.WriteHeader(http.StatusNotModified)
+
+		return entry, nil
+	}
+
+	return entry, buf.flush(resp.Status)
+}
+
+func (c *responseCache) store(ctx echo.Context, key string, entry *cachedResponse, ttl time.Duration) {
+	rCtx := ctx.Request().Context()
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		log.FromCtx(rCtx).WarnError(err, "failed to encode cached response", "cache_key", key)
+		return
+	}
+
+	var tags []string
+	if c.cfg.Tags != nil {
+		tags = c.cfg.Tags(ctx)
+	}
+
+	added, _ := ctx.Get(contextKeyCacheTags).([]string)
+	tags = append(tags, added...)
+
+	if err := c.cfg.Store.Set(rCtx, key, b, ttl, tags...); err != nil {
+		log.FromCtx(rCtx).WarnError(err, "failed to store cached response", "cache_key", key)
+	}
+}
+
+// cacheTTL returns how long response can be cached, false when it can't be cached.
+func (c *responseCache) cacheTTL(req *http.Request, resp *echo.Response) (time.Duration, bool) {
+	h := resp.Header()
+
+	if resp.Status != http.StatusOK || h.Get("Set-Cookie") != "" || h.Get(echo.HeaderVary) == "*" {
+		return 0, false
+	}
+
+	cc := parseCacheControl(h.Get(echo.HeaderCacheControl))
+
+	for _, d := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := cc[d]; ok {
+			return 0, false
+		}
+	}
+
+	sMaxAge, hasSMaxAge := cc["s-maxage"]
+	_, public := cc["public"]
+
+	// shared cache must not store authorized response, unless it's explicitly allowed
+	if !public && !hasSMaxAge {
+		for _, name := range c.cfg.CredentialHeaders {
+			if req.Header.Get(name) != "" {
+				return 0, false
+			}
+		}
+	}
+
+	for _, v := range []string{sMaxAge, cc["max-age"]} {
+		if v == "" {
+			continue
+		}
+
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs <= 0 {
+			return 0, false
+		}
+
+		return time.Duration(secs) * time.Second, true
+	}
+
+	return c.cfg.TTL, true
+}
+
+// writeCachedResponse writes entry as response, 304 when it matches `If-None-Match`.
+func writeCachedResponse(ctx echo.Context, entry *cachedResponse) error {
+	h := ctx.Response().Header()
+
+	for k, vv := range entry.Header {
+		h[k] = vv
+	}
+
+	h.Set(HeaderXCache, "HIT")
+	h.Set(headerAge, strconv.FormatInt(maxInt64(0, time.Now().Unix()-entry.StoredAt), 10))
+
+	if etagMatch(ctx.Request().Header.Get(headerIfNoneMatch), h.Get(headerETag)) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	ctx.Response().WriteHeader(entry.Status)
+
+	_, err := ctx.Response().Write(entry.Body)
+
+	return err
+}
+
+// defaultCacheKey returns key of route template, method, path, sorted query, vary header values & tenant ID.
+func defaultCacheKey(ctx echo.Context, varyHeaders []string) string {
+	req := ctx.Request()
+
+	h := sha256.New()
+	h.Write([]byte(tenantkit.IDFromContext(req.Context()) + "\n"))
+	h.Write([]byte(req.Method + " " + req.URL.Path + "?" + req.URL.Query().Encode() + "\n"))
+
+	for _, name := range varyHeaders {
+		h.Write([]byte(name + ":" + strings.Join(req.Header.Values(name), ",") + "\n"))
+	}
+
+	return CacheKeyPrefix + ctx.Path() + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheableHeader returns response header without per-request headers.
+func cacheableHeader(h http.Header) http.Header {
+	result := replayableHeader(h)
+
+	result.Del(web.HTTPKeyTraceParent)
+	result.Del(HeaderXCache)
+
+	return result
+}
+
+// parseCacheControl returns Cache-Control directives with their lowercased names.
+func parseCacheControl(v string) map[string]string {
+	directives := make(map[string]string)
+
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, value, _ := strings.Cut(part, "=")
+		directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+
+	return directives
+}
+
+// etagMatch reports whether `If-None-Match` value matches etag using weak comparison.
+func etagMatch(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,250 @@
+package echokit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/cachekit"
+	"github.com/adipurnama/go-toolkit/echokit"
+	"github.com/adipurnama/go-toolkit/tenantkit"
+)
+
+func TestCacheMiddleware(t *testing.T) {
+	var calls int32
+
+	store := cachekit.NewMemoryStore(0)
+
+	e := echo.New()
+	e.Use(echokit.CacheMiddleware(&echokit.CacheConfig{Store: store}))
+
+	e.GET("/products/:id", func(ctx echo.Context) error {
+		n := atomic.AddInt32(&calls, 1)
+
+		echokit.AddCacheTags(ctx, "product:"+ctx.Param("id"))
+
+		if ctx.QueryParam("private") != "" {
+			ctx.Response().Header().Set(echo.HeaderCacheControl, "private")
+		}
+
+		if ctx.QueryParam("public") != "" {
+			ctx.Response().Header().Set(echo.HeaderCacheControl, "public")
+		}
+
+		return ctx.JSON(http.StatusOK, map[string]interface{}{"id": ctx.Param("id"), "calls": n})
+	})
+
+	call := func(target string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	first := call("/products/1?b=2&a=1")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "MISS", first.Header().Get(echokit.HeaderXCache))
+
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	second := call("/products/1?a=1&b=2")
+	assert.Equal(t, "HIT", second.Header().Get(echokit.HeaderXCache), "query order doesn't matter")
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, etag, second.Header().Get("ETag"))
+	assert.NotEmpty(t, second.Header().Get("Age"))
+
+	notModified := call("/products/1?a=1&b=2", "If-None-Match", `"other", W/`+etag)
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+
+	fresh := call("/products/1?a=1&b=2", echo.HeaderCacheControl, "no-cache")
+	assert.Equal(t, "MISS", fresh.Header().Get(echokit.HeaderXCache))
+	assert.NotEqual(t, first.Body.String(), fresh.Body.String())
+
+	bypass := call("/products/1?a=1&b=2", echo.HeaderCacheControl, "no-store")
+	assert.Empty(t, bypass.Header().Get(echokit.HeaderXCache))
+
+	assert.Equal(t, "HIT", call("/products/1?a=1&b=2").Header().Get(echokit.HeaderXCache))
+
+	// private response isn't stored
+	call("/products/1?private=1")
+	assert.Equal(t, "MISS", call("/products/1?private=1").Header().Get(echokit.HeaderXCache))
+
+	// response of request with credentials isn't stored, unless it's public
+	call("/products/3", echo.HeaderCookie, "session=s3cr3t")
+	assert.Equal(t, "MISS", call("/products/3").Header().Get(echokit.HeaderXCache))
+
+	call("/products/4", "X-API-Key", "s3cr3t")
+	assert.Equal(t, "MISS", call("/products/4").Header().Get(echokit.HeaderXCache))
+
+	call("/products/5?public=1", echo.HeaderCookie, "session=s3cr3t")
+	assert.Equal(t, "HIT", call("/products/5?public=1").Header().Get(echokit.HeaderXCache))
+
+	// miss answers If-None-Match too
+	miss := call("/products/2", "If-None-Match", "*")
+	assert.Equal(t, http.StatusNotModified, miss.Code)
+	assert.Equal(t, "MISS", miss.Header().Get(echokit.HeaderXCache))
+
+	require.NoError(t, store.InvalidateTags(context.Background(), "product:1"))
+	assert.Equal(t, "MISS", call("/products/1?a=1&b=2").Header().Get(echokit.HeaderXCache))
+	assert.Equal(t, "HIT", call("/products/2").Header().Get(echokit.HeaderXCache), "other tag is kept")
+}
+
+func TestCacheMiddlewareTenant(t *testing.T) {
+	e := echo.New()
+	e.Use(tenantkit.EchoMiddleware(tenantkit.NewStaticResolver(
+		&tenantkit.Tenant{ID: "acme"},
+		&tenantkit.Tenant{ID: "globex"},
+	), nil))
+	e.Use(echokit.CacheMiddleware(&echokit.CacheConfig{Store: cachekit.NewMemoryStore(0)}))
+
+	e.GET("/catalog", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, tenantkit.IDFromContext(ctx.Request().Context()))
+	})
+
+	call := func(tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+		req.Header.Set(tenantkit.HTTPKeyTenantID, tenant)
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	call("acme")
+
+	rec := call("globex")
+	assert.Equal(t, "MISS", rec.Header().Get(echokit.HeaderXCache))
+	assert.Equal(t, "globex", rec.Body.String())
+	assert.Equal(t, "HIT", call("acme").Header().Get(echokit.HeaderXCache))
+}
+
+func TestCacheMiddlewareStampede(t *testing.T) {
+	var calls int32
+
+	release := make(chan struct{})
+
+	e := echo.New()
+	e.Use(echokit.CacheMiddleware(&echokit.CacheConfig{Store: cachekit.NewMemoryStore(0), TTL: time.Minute}))
+
+	e.GET("/slow", func(ctx echo.Context) error {
+		atomic.AddInt32(&calls, 1)
+		<-release
+
+		return ctx.String(http.StatusOK, "slow")
+	})
+
+	var wg sync.WaitGroup
+
+	recs := make([]*httptest.ResponseRecorder, 10)
+
+	for i := range recs {
+		recs[i] = httptest.NewRecorder()
+
+		wg.Add(1)
+
+		go func(rec *httptest.ResponseRecorder) {
+			defer wg.Done()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		}(recs[i])
+	}
+
+	// let requests join the in-flight call
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	for _, rec := range recs {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "slow", rec.Body.String())
+	}
+}
+
+func TestCacheMiddlewarePanic(t *testing.T) {
+	var calls int32
+
+	release := make(chan struct{})
+
+	e := echo.New()
+	e.Use(echokit.RecoverMiddleware(&echokit.RecoverConfig{}))
+	e.Use(echokit.CacheMiddleware(&echokit.CacheConfig{Store: cachekit.NewMemoryStore(0)}))
+
+	e.GET("/panic", func(ctx echo.Context) error {
+		<-release
+
+		if atomic.AddInt32(&calls, 1) == 1 {
+			panic("boom")
+		}
+
+		return ctx.String(http.StatusOK, "recovered")
+	})
+
+	var wg sync.WaitGroup
+
+	recs := make([]*httptest.ResponseRecorder, 5)
+
+	for i := range recs {
+		recs[i] = httptest.NewRecorder()
+
+		wg.Add(1)
+
+		go func(rec *httptest.ResponseRecorder) {
+			defer wg.Done()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+		}(recs[i])
+	}
+
+	// let requests join the in-flight call
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	codes := map[int]int{}
+	for _, rec := range recs {
+		codes[rec.Code]++
+	}
+
+	// only the panicking caller fails, waiting callers serve the request themselves
+	assert.Equal(t, map[int]int{http.StatusInternalServerError: 1, http.StatusOK: 4}, codes)
+}
+
+func TestCacheMiddlewareHandlerError(t *testing.T) {
+	var handled int32
+
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, ctx echo.Context) {
+		atomic.AddInt32(&handled, 1)
+		_ = ctx.String(http.StatusNotFound, err.Error())
+	}
+
+	e.Use(echokit.CacheMiddleware(&echokit.CacheConfig{Store: cachekit.NewMemoryStore(0)}))
+
+	e.GET("/missing", func(ctx echo.Context) error {
+		return echo.ErrNotFound
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "MISS", rec.Header().Get(echokit.HeaderXCache))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&handled), "error is handled once")
+}
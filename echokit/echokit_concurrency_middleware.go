@@ -0,0 +1,115 @@
+package echokit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"github.com/adipurnama/go-toolkit/concurrencykit"
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+)
+
+// ConcurrencyLimitConfig adaptive concurrency limit middleware configuration
+// default value:
+//   - Limiter: concurrencykit.New(Limit), when Limiter is not set
+//   - middleware.DefaultSkipper / apply to all url, except SSE & websocket requests
+//   - priority: concurrencykit.PriorityNormal, unless set in RoutePriorities
+//
+// RoutePriorities sets priority for specific route, keyed by
+// `METHOD /route/:path` or `/route/:path` (any method), e.g.
+//
+//	RoutePriorities: map[string]concurrencykit.Priority{
+//		"POST /payments": concurrencykit.PriorityCritical,
+//		"/reports":       concurrencykit.PrioritySheddable,
+//	}
+type ConcurrencyLimitConfig struct {
+	Limiter         *concurrencykit.Limiter            `json:"-"`
+	Limit           *concurrencykit.Config             `json:"limit,omitempty"`
+	RoutePriorities map[string]concurrencykit.Priority `json:"route_priorities,omitempty"`
+	Skipper         middleware.Skipper                 `json:"-"`
+}
+
+// ConcurrencyLimitMiddleware sheds load using cfg.Limiter, request above the current
+// concurrency limit is rejected early with errors.AppError CodeUnavailable (503).
+//
+// Request latency adjusts the limit. Timed out requests & 503 / 504 responses are
+// counted as dropped, decreasing the limit. Panicking request releases its slot without adjusting the limit.
+func ConcurrencyLimitMiddleware(cfg *ConcurrencyLimitConfig) echo.MiddlewareFunc {
+	if cfg.Limiter == nil && cfg.Limit == nil {
+		panic("echokit: ConcurrencyLimitConfig.Limiter or Limit is required")
+	}
+
+	if cfg.Limiter == nil {
+		cfg.Limiter = concurrencykit.New(cfg.Limit)
+	}
+
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+
+	routePriorities := normalizeRouteKeys(cfg.RoutePriorities)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			// SSE & websocket are long-lived
+			if skip := cfg.Skipper(ctx) || isStreamingRequest(ctx.Request()); skip {
+				return next(ctx)
+			}
+
+			priority, _ := routeValue(routePriorities, ctx)
+
+			token, ok := cfg.Limiter.Acquire(priority)
+			if !ok {
+				return apperrors.New(apperrors.CodeUnavailable).
+					WithDetail("reason", "concurrency limit exceeded")
+			}
+
+			var err error
+
+			panicked := true
+
+			defer func() {
+				switch {
+				case panicked:
+					// panic is recovered by outer RecoverMiddleware, it's unrelated to load
+					token.Ignore()
+				case isOverloaded(ctx, err):
+					token.Dropped()
+				default:
+					token.Success()
+				}
+			}()
+
+			err = next(ctx)
+			panicked = false
+
+			return err
+		}
+	}
+}
+
+// isOverloaded reports whether request failed due to server or downstream overload.
+func isOverloaded(ctx echo.Context, err error) bool {
+	if errors.Is(ctx.Request().Context().Err(), context.DeadlineExceeded) {
+		return true
+	}
+
+	status := ctx.Response().Status
+
+	if err != nil {
+		status = http.StatusInternalServerError
+
+		var errEchoHTTP *echo.HTTPError
+
+		if appErr, ok := apperrors.FromError(err); ok {
+			status = appErr.HTTPStatus
+		} else if errors.As(err, &errEchoHTTP) {
+			status = errEchoHTTP.Code
+		}
+	}
+
+	return status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
@@ -0,0 +1,99 @@
+package echokit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/concurrencykit"
+	"github.com/adipurnama/go-toolkit/echokit"
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+)
+
+func TestConcurrencyLimitMiddleware(t *testing.T) {
+	limiter := concurrencykit.New(&concurrencykit.Config{
+		Algorithm:    concurrencykit.AIMD,
+		InitialLimit: 2,
+		Registerer:   prometheus.NewRegistry(),
+	})
+
+	e := echo.New()
+	mid := echokit.ConcurrencyLimitMiddleware(&echokit.ConcurrencyLimitConfig{
+		Limiter: limiter,
+		RoutePriorities: map[string]concurrencykit.Priority{
+			"post /payments": concurrencykit.PriorityCritical,
+		},
+	})
+
+	call := func(method, path string, h echo.HandlerFunc) error {
+		req := httptest.NewRequest(method, path, nil)
+		ctx := e.NewContext(req, httptest.NewRecorder())
+		ctx.SetPath(path)
+
+		return mid(h)(ctx)
+	}
+
+	ok := func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	}
+
+	// holds one slot, normal priority may use 90% of limit 2
+	err := call(http.MethodGet, "/orders", func(ctx echo.Context) error {
+		assert.Equal(t, 1, limiter.InFlight())
+
+		err := call(http.MethodGet, "/orders", ok)
+		assert.Equal(t, apperrors.CodeUnavailable, apperrors.CodeOf(err))
+
+		assert.NoError(t, call(http.MethodPost, "/payments", ok), "critical route may use the whole limit")
+
+		return ctx.NoContent(http.StatusOK)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, limiter.InFlight())
+
+	// overloaded response decreases the limit
+	limit := limiter.Limit()
+
+	err = call(http.MethodGet, "/orders", func(ctx echo.Context) error {
+		return apperrors.New(apperrors.CodeUnavailable)
+	})
+	assert.Error(t, err)
+	assert.Less(t, limiter.Limit(), limit)
+}
+
+func TestConcurrencyLimitMiddlewarePanic(t *testing.T) {
+	limiter := concurrencykit.New(&concurrencykit.Config{
+		Algorithm:    concurrencykit.AIMD,
+		InitialLimit: 2,
+		Registerer:   prometheus.NewRegistry(),
+	})
+
+	e := echo.New()
+	recoverMid := echokit.RecoverMiddleware(&echokit.RecoverConfig{})
+	limitMid := echokit.ConcurrencyLimitMiddleware(&echokit.ConcurrencyLimitConfig{Limiter: limiter})
+
+	call := func(h echo.HandlerFunc) error {
+		ctx := e.NewContext(httptest.NewRequest(http.MethodGet, "/orders", nil), httptest.NewRecorder())
+
+		return recoverMid(limitMid(h))(ctx)
+	}
+
+	for i := 0; i < 5; i++ {
+		err := call(func(ctx echo.Context) error {
+			panic("boom")
+		})
+		assert.Equal(t, apperrors.CodeInternal, apperrors.CodeOf(err))
+	}
+
+	assert.Equal(t, 0, limiter.InFlight(), "panicking request releases its slot")
+	assert.Equal(t, 2, limiter.Limit(), "panic doesn't adjust the limit")
+
+	assert.NoError(t, call(func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	}))
+}
@@ -3,12 +3,14 @@ package echokit
 import (
 	"fmt"
 	"net/http"
+	"reflect"
 
 	echo "github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	apperrors "github.com/adipurnama/go-toolkit/errors"
 	"github.com/adipurnama/go-toolkit/log"
 	"github.com/adipurnama/go-toolkit/web"
 )
@@ -18,7 +20,7 @@ type defaultErrorResponse struct {
 	Message string `json:"message"`
 }
 
-func loggerHTTPErrorHandler(w echo.HTTPErrorHandler) echo.HTTPErrorHandler {
+func loggerHTTPErrorHandler(w echo.HTTPErrorHandler, problemDetails bool) echo.HTTPErrorHandler {
 	return func(err error, ctx echo.Context) {
 		logger := log.FromCtx(ctx.Request().Context())
 		msg := fmt.Sprintf("%s %s - request completed with error", ctx.Request().Method, ctx.Request().URL.Path)
@@ -40,6 +42,19 @@ func loggerHTTPErrorHandler(w echo.HTTPErrorHandler) echo.HTTPErrorHandler {
 
 		// found error & response not yet written
 
+		// check for errors.AppError returned from handler / controller
+		// must be checked before gRPC status since *AppError implements GRPCStatus()
+		if appErr, ok := apperrors.FromError(err); ok {
+			errWriteResp := writeAppError(ctx, appErr, problemDetails)
+			if errWriteResp != nil {
+				logger.WarnError(errWriteResp, "error writing JSON response", "path", ctx.Request().URL.Path)
+			}
+
+			logErrorAndResponse(logger, msg, err, ctx)
+
+			return
+		}
+
 		// check for gRPC API call returns
 		// convert it to *echo.HTTPError
 		st, ok := status.FromError(errors.Cause(err))
@@ -55,7 +70,14 @@ func loggerHTTPErrorHandler(w echo.HTTPErrorHandler) echo.HTTPErrorHandler {
 				err = errEchoHTTP.Internal
 			}
 
-			errWriteResp := ctx.JSON(errEchoHTTP.Code, errEchoHTTP)
+			var errWriteResp error
+
+			if problemDetails {
+				p := web.NewProblemDetails(errEchoHTTP.Code, fmt.Sprint(errEchoHTTP.Message))
+				errWriteResp = writeProblemDetails(ctx, p)
+			} else {
+				errWriteResp = ctx.JSON(errEchoHTTP.Code, errEchoHTTP)
+			}
 
 			if errWriteResp != nil {
 				logger.WarnError(errWriteResp, "error writing JSON response", "path", ctx.Request().URL.Path)
@@ -69,8 +91,27 @@ func loggerHTTPErrorHandler(w echo.HTTPErrorHandler) echo.HTTPErrorHandler {
 		// check for web.Validation error
 		var httpErr *web.HTTPError
 		if ok := errors.As(err, &httpErr); ok {
-			errWriteResp := ctx.JSON(httpErr.Code, httpErr)
+			var errWriteResp error
+
+			if problemDetails {
+				errWriteResp = writeProblemDetails(ctx, web.ProblemDetailsFromHTTPError(httpErr))
+			} else {
+				errWriteResp = ctx.JSON(httpErr.Code, httpErr)
+			}
+
+			if errWriteResp != nil {
+				logger.WarnError(errWriteResp, "error writing JSON response", "path", ctx.Request().URL.Path)
+			}
+
+			logErrorAndResponse(logger, msg, err, ctx)
+
+			return
+		}
 
+		// check for web.ProblemDetails returned directly from handler / controller
+		var problem *web.ProblemDetails
+		if ok := errors.As(err, &problem); ok {
+			errWriteResp := writeProblemDetails(ctx, problem)
 			if errWriteResp != nil {
 				logger.WarnError(errWriteResp, "error writing JSON response", "path", ctx.Request().URL.Path)
 			}
@@ -81,12 +122,20 @@ func loggerHTTPErrorHandler(w echo.HTTPErrorHandler) echo.HTTPErrorHandler {
 		}
 
 		// unhandled errors returned types from controller / handler
-		resp := defaultErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: http.StatusText(http.StatusInternalServerError),
+		var errWriteResp error
+
+		if problemDetails {
+			p := web.NewProblemDetails(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			errWriteResp = writeProblemDetails(ctx, p)
+		} else {
+			resp := defaultErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: http.StatusText(http.StatusInternalServerError),
+			}
+
+			errWriteResp = ctx.JSON(resp.Code, resp)
 		}
 
-		errWriteResp := ctx.JSON(resp.Code, resp)
 		if errWriteResp != nil {
 			logger.WarnError(errWriteResp, "error writing JSON response", "path", ctx.Request().URL.Path)
 		}
@@ -100,6 +149,88 @@ func loggerHTTPErrorHandler(w echo.HTTPErrorHandler) echo.HTTPErrorHandler {
 	}
 }
 
+type appErrorResponse struct {
+	ErrorCode apperrors.Code         `json:"error_code"`
+	Retryable bool                   `json:"retryable"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// writeAppError writes *errors.AppError with message localized using request's translator.
+func writeAppError(ctx echo.Context, appErr *apperrors.AppError, problemDetails bool) error {
+	code := appErr.StatusCode()
+	msg := appErr.LocalizedMessage(web.TranslatorFromContext(ctx.Request().Context()))
+
+	if problemDetails {
+		p := web.NewProblemDetails(code, msg).
+			SetExtension("code", appErr.Code).
+			SetExtension("retryable", appErr.Retryable)
+
+		if len(appErr.Details) > 0 {
+			p.SetExtension("details", appErr.Details)
+		}
+
+		return writeProblemDetails(ctx, p)
+	}
+
+	return ctx.JSON(code, web.HTTPError{
+		Code:    code,
+		Message: msg,
+		Response: appErrorResponse{
+			ErrorCode: appErr.Code,
+			Retryable: appErr.Retryable,
+			Details:   appErr.Details,
+		},
+	})
+}
+
+// writeProblemDetails writes RFC 7807 response body with `application/problem+json` content type.
+// instance, request_id & trace_id members are filled from current request if not set yet.
+func writeProblemDetails(ctx echo.Context, p *web.ProblemDetails) error {
+	if p.Type == "" {
+		p.Type = web.ProblemTypeBlank
+	}
+
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+
+	if p.Instance == "" {
+		p.Instance = ctx.Request().URL.Path
+	}
+
+	if p.RequestID == "" {
+		p.RequestID = headerValue(ctx, web.HTTPKeyRequestID)
+	}
+
+	if p.TraceID == "" {
+		p.TraceID = headerValue(ctx, web.HTTPKeyTraceID)
+	}
+
+	ctx.Response().Header().Set(echo.HeaderContentType, web.MIMEApplicationProblemJSON)
+
+	return ctx.JSON(p.Status, p)
+}
+
+// headerValue looks up response header first (set by RequestIDLoggerMiddleware), then request header.
+func headerValue(ctx echo.Context, key string) string {
+	if v := ctx.Response().Header().Get(key); v != "" {
+		return v
+	}
+
+	return ctx.Request().Header.Get(key)
+}
+
+// isDefaultHTTPErrorHandler reports whether h is echo's built-in error handler,
+// i.e. the app didn't replace e.HTTPErrorHandler.
+func isDefaultHTTPErrorHandler(e *echo.Echo, h echo.HTTPErrorHandler) bool {
+	if h == nil {
+		return true
+	}
+
+	// method values of the same method share their code pointer
+	return reflect.ValueOf(h).Pointer() == reflect.ValueOf(e.DefaultHTTPErrorHandler).Pointer()
+}
+
 func logErrorAndResponse(l *log.Logger, msg string, err error, ctx echo.Context) {
 	if ctx.Response().Status >= http.StatusInternalServerError {
 		l.Error(err, msg,
@@ -0,0 +1,56 @@
+package echokit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+)
+
+var errUnhandled = errors.New("unhandled")
+
+func TestProblemDetailsErrorHandler(t *testing.T) {
+	newServer := func(custom bool) *echo.Echo {
+		e := echo.New()
+		if custom {
+			e.HTTPErrorHandler = func(err error, ctx echo.Context) {
+				_ = ctx.String(http.StatusTeapot, "app handler")
+			}
+		}
+
+		require.NoError(t, echokit.ConfigureServer(e, &echokit.RuntimeConfig{
+			EnableProblemDetails: true,
+			Metrics:              &echokit.MetricsConfig{Registry: prometheus.NewRegistry()},
+			HealthCheckFunc:      func(context.Context) error { return nil },
+		}))
+
+		e.GET("/fail", func(ctx echo.Context) error {
+			return errUnhandled
+		})
+
+		return e
+	}
+
+	call := func(e *echo.Echo) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+		return rec
+	}
+
+	rec := call(newServer(false))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get(echo.HeaderContentType))
+
+	rec = call(newServer(true))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, "app handler", rec.Body.String())
+}
@@ -0,0 +1,438 @@
+package echokit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"google.golang.org/grpc/codes"
+
+	"github.com/adipurnama/go-toolkit/authkit"
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/tenantkit"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+const (
+	// HeaderIdempotencyKey is request header containing client generated idempotency key.
+	HeaderIdempotencyKey = "Idempotency-Key"
+
+	// HeaderIdempotentReplayed is response header set when response is replayed from previous request.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// idempotency error codes.
+const (
+	CodeIdempotencyKeyInUse  apperrors.Code = "IDEMPOTENCY_KEY_IN_USE"
+	CodeIdempotencyKeyReused apperrors.Code = "IDEMPOTENCY_KEY_REUSED"
+)
+
+const (
+	defaultIdempotencyTTL       = 24 * time.Hour
+	defaultIdempotencyLockTTL   = time.Minute
+	defaultIdempotencyKeyPrefix = "idempotency:"
+	defaultIdempotencyBodySize  = 1 << 20
+	defaultIdempotencyKeyLength = 255
+	idempotencyTokenSize        = 16
+
+	idempotencyStateInFlight  = "in_flight"
+	idempotencyStateCompleted = "completed"
+)
+
+// KEYS[1] idempotency key
+// ARGV[1] lock value held by the request.
+// deletes the key only when it's still locked by the request.
+var idempotencyReleaseScript = goredis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+
+return 0
+`)
+
+// KEYS[1] idempotency key
+// ARGV[1] lock value held by the request, ARGV[2] completed record, ARGV[3] ttl (ms).
+// replaces the lock only when it's still held by the request.
+var idempotencyCompleteScript = goredis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+	return 1
+end
+
+return 0
+`)
+
+var errIdempotencyBodyTooLarge = errors.New("echokit: idempotent request body is too large")
+
+func init() {
+	apperrors.Register(
+		apperrors.Definition{
+			Code:       CodeIdempotencyKeyInUse,
+			MessageKey: "error.idempotency_key_in_use",
+			HTTPStatus: http.StatusConflict,
+			GRPCCode:   codes.Aborted,
+			Retryable:  true,
+			Messages: map[string]string{
+				"en": "request with the same idempotency key is still being processed",
+				"id": "permintaan dengan idempotency key yang sama masih diproses",
+			},
+		},
+		apperrors.Definition{
+			Code:       CodeIdempotencyKeyReused,
+			MessageKey: "error.idempotency_key_reused",
+			HTTPStatus: http.StatusUnprocessableEntity,
+			GRPCCode:   codes.InvalidArgument,
+			Messages: map[string]string{
+				"en": "idempotency key was used for different request",
+				"id": "idempotency key sudah digunakan untuk permintaan lain",
+			},
+		},
+	)
+}
+
+// IdempotencyScopeFunc returns the caller scope of request's Idempotency-Key,
+// requests of different scopes never share stored responses even when they use the same key.
+type IdempotencyScopeFunc func(ctx echo.Context) string
+
+// IdempotencyScopeByCaller returns IdempotencyScopeFunc using tenant ID, see tenantkit,
+// and authenticated subject, see authkit, or Authorization header value when the request has no claims.
+// Requests without tenant & credentials share the same scope, use custom IdempotencyScopeFunc
+// for other credentials, e.g. API key header.
+func IdempotencyScopeByCaller() IdempotencyScopeFunc {
+	return func(ctx echo.Context) string {
+		rCtx := ctx.Request().Context()
+		caller := ctx.Request().Header.Get(echo.HeaderAuthorization)
+
+		if c, ok := authkit.ClaimsFromContext(rCtx); ok {
+			caller = "sub:" + c.Subject
+		}
+
+		return tenantkit.IDFromContext(rCtx) + "\n" + caller
+	}
+}
+
+// IdempotencyConfig idempotency middleware configuration
+// default value:
+//   - TTL: 24 hours, how long completed response is kept for replay
+//   - LockTTL: 1 minute, how long in-flight request holds the key, should be longer than request timeout
+//   - KeyPrefix: `idempotency:`, followed by hash of the key & its scope
+//   - ScopeFunc: IdempotencyScopeByCaller, register it after authkit & tenantkit middlewares
+//   - MaxKeyLength: 255, longer Idempotency-Key is rejected with 400
+//   - MaxBodySize: 1 MB, larger request body is rejected with 413 before reaching the handler.
+//     It's read into memory to fingerprint the request, keep it at or below the body limit middleware's limit
+//   - skips request without Idempotency-Key header & safe methods (GET, HEAD, OPTIONS)
+type IdempotencyConfig struct {
+	Client       goredis.Cmdable      `json:"-"`
+	TTL          time.Duration        `json:"ttl,omitempty"`
+	LockTTL      time.Duration        `json:"lock_ttl,omitempty"`
+	KeyPrefix    string               `json:"key_prefix,omitempty"`
+	ScopeFunc    IdempotencyScopeFunc `json:"-"`
+	MaxKeyLength int                  `json:"max_key_length,omitempty"`
+	MaxBodySize  int64                `json:"max_body_size,omitempty"`
+	Skipper      middleware.Skipper   `json:"-"`
+}
+
+type idempotencyRecord struct {
+	State       string      `json:"state"`
+	Fingerprint string      `json:"fingerprint"`
+	Token       string      `json:"token,omitempty"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// IdempotencyMiddleware makes request with Idempotency-Key header safe to retry.
+//
+// Keys are scoped by cfg.ScopeFunc & hashed before they're stored.
+// The first request locks the key in redis, its response (status, headers & body)
+// is stored and replayed for subsequent requests with the same key.
+// Duplicate request while the first one is still in-flight gets 409,
+// reused key with different method, path or body gets 422.
+// Response with 5xx status is not stored, so the client can retry it.
+// The lock holds a unique token, a request running longer than LockTTL
+// doesn't release or overwrite the key locked by another request.
+func IdempotencyMiddleware(cfg *IdempotencyConfig) echo.MiddlewareFunc {
+	if cfg.Client == nil {
+		panic("echokit: IdempotencyConfig.Client cannot be nil")
+	}
+
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultIdempotencyTTL
+	}
+
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = defaultIdempotencyLockTTL
+	}
+
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = defaultIdempotencyKeyPrefix
+	}
+
+	if cfg.ScopeFunc == nil {
+		cfg.ScopeFunc = IdempotencyScopeByCaller()
+	}
+
+	if cfg.MaxKeyLength <= 0 {
+		cfg.MaxKeyLength = defaultIdempotencyKeyLength
+	}
+
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultIdempotencyBodySize
+	}
+
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if cfg.Skipper(ctx) {
+				return next(ctx)
+			}
+
+			req := ctx.Request()
+
+			key := req.Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				return next(ctx)
+			}
+
+			switch req.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(ctx)
+			}
+
+			if len(key) > cfg.MaxKeyLength {
+				return apperrors.New(apperrors.CodeInvalidArgument).
+					WithDetail("reason", fmt.Sprintf("%s header is longer than %d characters", HeaderIdempotencyKey, cfg.MaxKeyLength))
+			}
+
+			fingerprint, err := requestFingerprint(req, cfg.MaxBodySize)
+			if errors.Is(err, errIdempotencyBodyTooLarge) {
+				return echo.ErrStatusRequestEntityTooLarge
+			}
+
+			if err != nil {
+				return apperrors.Wrap(err, apperrors.CodeInvalidArgument)
+			}
+
+			m := &idempotencyHandler{
+				cfg:         cfg,
+				key:         idempotencyStoreKey(cfg.KeyPrefix, cfg.ScopeFunc(ctx), key),
+				fingerprint: fingerprint,
+			}
+
+			return m.handle(ctx, next)
+		}
+	}
+}
+
+type idempotencyHandler struct {
+	cfg         *IdempotencyConfig
+	key         string
+	fingerprint string
+}
+
+func (m *idempotencyHandler) handle(ctx echo.Context, next echo.HandlerFunc) error {
+	rCtx := ctx.Request().Context()
+
+	token, err := newIdempotencyToken()
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternal)
+	}
+
+	lock, _ := json.Marshal(idempotencyRecord{
+		State:       idempotencyStateInFlight,
+		Fingerprint: m.fingerprint,
+		Token:       token,
+	})
+
+	acquired, err := m.cfg.Client.SetNX(rCtx, m.key, lock, m.cfg.LockTTL).Result()
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeUnavailable)
+	}
+
+	if !acquired {
+		return m.replay(ctx)
+	}
+
+	resp := ctx.Response()
+	rec := &responseRecorder{ResponseWriter: resp.Writer}
+	resp.Writer = rec
+
+	errHandler := next(ctx)
+	if errHandler != nil {
+		// write error response now, so it's stored as well
+		ctx.Error(errHandler)
+	}
+
+	resp.Writer = rec.ResponseWriter
+
+	if resp.Status >= http.StatusInternalServerError || !resp.Committed {
+		released, errDel := idempotencyReleaseScript.Run(rCtx, m.cfg.Client, []string{m.key}, lock).Int()
+		if errDel != nil {
+			log.FromCtx(rCtx).WarnError(errDel, "failed to release idempotency key", "idempotency_key", m.key)
+		} else if released == 0 {
+			log.FromCtx(rCtx).Warn("idempotency lock expired before request completed", "idempotency_key", m.key)
+		}
+
+		return errHandler
+	}
+
+	completed, _ := json.Marshal(idempotencyRecord{
+		State:       idempotencyStateCompleted,
+		Fingerprint: m.fingerprint,
+		Status:      resp.Status,
+		Header:      replayableHeader(resp.Header()),
+		Body:        rec.body.Bytes(),
+	})
+
+	stored, errSet := idempotencyCompleteScript.Run(rCtx, m.cfg.Client, []string{m.key},
+		lock, completed, m.cfg.TTL.Milliseconds()).Int()
+	if errSet != nil {
+		log.FromCtx(rCtx).WarnError(errSet, "failed to store idempotent response", "idempotency_key", m.key)
+	} else if stored == 0 {
+		log.FromCtx(rCtx).Warn("idempotency lock expired before request completed, response is not stored",
+			"idempotency_key", m.key)
+	}
+
+	return errHandler
+}
+
+func (m *idempotencyHandler) replay(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+
+	b, err := m.cfg.Client.Get(rCtx, m.key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		// previous request just released the key
+		return apperrors.New(CodeIdempotencyKeyInUse)
+	}
+
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.CodeUnavailable)
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(b, &record); err != nil {
+		return apperrors.Wrap(err, apperrors.CodeInternal)
+	}
+
+	if record.Fingerprint != m.fingerprint {
+		return apperrors.New(CodeIdempotencyKeyReused)
+	}
+
+	if record.State != idempotencyStateCompleted {
+		return apperrors.New(CodeIdempotencyKeyInUse)
+	}
+
+	h := ctx.Response().Header()
+	for k, vv := range record.Header {
+		h[k] = vv
+	}
+
+	h.Set(HeaderIdempotentReplayed, "true")
+
+	ctx.Response().WriteHeader(record.Status)
+
+	_, err = ctx.Response().Write(record.Body)
+
+	return err
+}
+
+// idempotencyStoreKey returns redis key of client key within scope,
+// hashed so the client can't choose the key or its length.
+func idempotencyStoreKey(prefix, scope, key string) string {
+	sum := sha256.Sum256([]byte(scope + "\n" + key))
+
+	return prefix + hex.EncodeToString(sum[:])
+}
+
+// requestFingerprint returns hash of request method, path & body up to maxBodySize bytes.
+// request body is restored, so it can be read by the handler.
+func requestFingerprint(req *http.Request, maxBodySize int64) (string, error) {
+	var body []byte
+
+	if req.Body != nil {
+		b, err := io.ReadAll(io.LimitReader(req.Body, maxBodySize+1))
+		if err != nil {
+			return "", err
+		}
+
+		if int64(len(b)) > maxBodySize {
+			return "", errIdempotencyBodyTooLarge
+		}
+
+		body = b
+		req.Body = io.NopCloser(bytes.NewReader(b))
+	}
+
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.RequestURI() + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// newIdempotencyToken returns random token identifying the request holding the lock.
+func newIdempotencyToken() (string, error) {
+	b := make([]byte, idempotencyTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// replayableHeader returns response header without per-request headers.
+func replayableHeader(h http.Header) http.Header {
+	result := h.Clone()
+
+	result.Del(web.HTTPKeyRequestID)
+	result.Del(web.HTTPKeyTraceID)
+	result.Del(echo.HeaderContentLength)
+
+	return result
+}
+
+// responseRecorder copies response body written by handler.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write implements http.ResponseWriter.
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker.
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	return hj.Hijack()
+}
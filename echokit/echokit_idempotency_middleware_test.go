@@ -0,0 +1,229 @@
+package echokit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis"
+	goredis "github.com/go-redis/redis/v8"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer mr.Close()
+
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	var (
+		calls   int32
+		release = make(chan struct{})
+		started = make(chan struct{})
+	)
+
+	e := echo.New()
+	mid := echokit.IdempotencyMiddleware(&echokit.IdempotencyConfig{Client: client})
+
+	handler := mid(func(ctx echo.Context) error {
+		n := atomic.AddInt32(&calls, 1)
+
+		if ctx.Request().Header.Get("X-Block") != "" {
+			close(started)
+			<-release
+		}
+
+		ctx.Response().Header().Set("X-Payment-Seq", "1")
+
+		return ctx.JSON(http.StatusCreated, map[string]int32{"seq": n})
+	})
+
+	call := func(key, body string, header ...string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(body))
+		req.Header.Set(echokit.HeaderIdempotencyKey, key)
+
+		if len(header) == 2 {
+			req.Header.Set(header[0], header[1])
+		}
+
+		rec := httptest.NewRecorder()
+
+		return rec, handler(e.NewContext(req, rec))
+	}
+
+	t.Run("replays first completed response", func(t *testing.T) {
+		first, err := call("key-1", `{"amount":100}`)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, first.Code)
+
+		second, err := call("key-1", `{"amount":100}`)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "1", second.Header().Get("X-Payment-Seq"))
+		assert.Equal(t, "true", second.Header().Get(echokit.HeaderIdempotentReplayed))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("rejects reused key with different body", func(t *testing.T) {
+		_, err := call("key-1", `{"amount":999}`)
+		assert.Equal(t, echokit.CodeIdempotencyKeyReused, apperrors.CodeOf(err))
+	})
+
+	t.Run("rejects concurrent in-flight duplicate", func(t *testing.T) {
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+
+			_, _ = call("key-2", `{"amount":5}`, "X-Block", "true")
+		}()
+
+		<-started
+
+		_, err := call("key-2", `{"amount":5}`)
+		assert.Equal(t, echokit.CodeIdempotencyKeyInUse, apperrors.CodeOf(err))
+
+		close(release)
+		<-done
+
+		rec, err := call("key-2", `{"amount":5}`)
+		assert.NoError(t, err)
+		assert.Equal(t, "true", rec.Header().Get(echokit.HeaderIdempotentReplayed))
+	})
+
+	t.Run("request without key is not stored", func(t *testing.T) {
+		before := atomic.LoadInt32(&calls)
+
+		_, err := call("", `{"amount":1}`)
+		assert.NoError(t, err)
+
+		_, err = call("", `{"amount":1}`)
+		assert.NoError(t, err)
+
+		assert.Equal(t, before+2, atomic.LoadInt32(&calls))
+	})
+}
+
+func TestIdempotencyMiddlewareLockExpired(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer mr.Close()
+
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	e := echo.New()
+	mid := echokit.IdempotencyMiddleware(&echokit.IdempotencyConfig{Client: client, MaxBodySize: 16})
+
+	handler := mid(func(ctx echo.Context) error {
+		// lock expired & the key was taken by another request
+		for _, key := range mr.Keys() {
+			mr.Set(key, "other")
+		}
+
+		if ctx.QueryParam("fail") != "" {
+			return ctx.NoContent(http.StatusServiceUnavailable)
+		}
+
+		return ctx.NoContent(http.StatusCreated)
+	})
+
+	call := func(target, key, body string) error {
+		mr.FlushAll()
+
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set(echokit.HeaderIdempotencyKey, key)
+
+		return handler(e.NewContext(req, httptest.NewRecorder()))
+	}
+
+	// single key, taken by other request
+	lockedByOther := func() string {
+		keys := mr.Keys()
+		if len(keys) != 1 {
+			return ""
+		}
+
+		v, _ := mr.Get(keys[0])
+
+		return v
+	}
+
+	assert.NoError(t, call("/payments", "key-1", "{}"))
+	assert.Equal(t, "other", lockedByOther(), "response doesn't overwrite other request's lock")
+
+	assert.NoError(t, call("/payments?fail=1", "key-2", "{}"))
+	assert.Equal(t, "other", lockedByOther(), "failed request doesn't release other request's lock")
+
+	err = call("/payments", "key-3", `{"amount":1000000}`)
+	assert.Equal(t, echo.ErrStatusRequestEntityTooLarge, err)
+	assert.Empty(t, mr.Keys())
+}
+
+func TestIdempotencyMiddlewareKeyScope(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer mr.Close()
+
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	var calls int32
+
+	e := echo.New()
+	mid := echokit.IdempotencyMiddleware(&echokit.IdempotencyConfig{Client: client, MaxKeyLength: 16})
+
+	handler := mid(func(ctx echo.Context) error {
+		n := atomic.AddInt32(&calls, 1)
+
+		return ctx.JSON(http.StatusCreated, map[string]int32{"seq": n})
+	})
+
+	call := func(key, authorization string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(`{"amount":100}`))
+		req.Header.Set(echokit.HeaderIdempotencyKey, key)
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+
+		rec := httptest.NewRecorder()
+
+		return rec, handler(e.NewContext(req, rec))
+	}
+
+	alice, err := call("key-1", "Bearer alice")
+	assert.NoError(t, err)
+
+	bob, err := call("key-1", "Bearer bob")
+	assert.NoError(t, err)
+	assert.Empty(t, bob.Header().Get(echokit.HeaderIdempotentReplayed), "other caller's response isn't replayed")
+	assert.NotEqual(t, alice.Body.String(), bob.Body.String())
+
+	replayed, err := call("key-1", "Bearer alice")
+	assert.NoError(t, err)
+	assert.Equal(t, alice.Body.String(), replayed.Body.String())
+
+	for _, key := range mr.Keys() {
+		assert.NotContains(t, key, "key-1", "client key is hashed")
+	}
+
+	_, err = call(strings.Repeat("k", 17), "Bearer alice")
+	assert.Equal(t, apperrors.CodeInvalidArgument, apperrors.CodeOf(err))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,234 @@
+package echokit
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	defaultMetricsPath      = "/metrics"
+	defaultMetricsSubsystem = "echo"
+
+	kb = 1024
+	mb = 1024 * kb
+
+	// MetricsRouteUnmatched is route label of request not matching any registered route.
+	MetricsRouteUnmatched = "unmatched"
+)
+
+// MetricsConfig prometheus HTTP metrics middleware configuration
+// default value:
+//   - Subsystem: `echo`, RunServerWithContext uses RuntimeConfig.Name
+//   - Buckets: prometheus.DefBuckets, request duration histogram buckets in seconds
+//   - SizeBuckets: 1 KB to 10 MB, request & response size histogram buckets in bytes
+//   - RouteLabelFunc: `url` label value, route template e.g. `/orders/:id`, MetricsRouteUnmatched for unknown route
+//   - SkipPaths: none, RunServerWithContext adds healthcheck & metrics path
+//   - Registry: nil, uses prometheus.DefaultRegisterer & prometheus.DefaultGatherer
+//
+// Collectors are registered to the same registry, so they're exposed on the same endpoint.
+// Apps can also register their own collectors to Registry directly.
+type MetricsConfig struct {
+	Namespace      string                    `json:"namespace,omitempty"`
+	Subsystem      string                    `json:"subsystem,omitempty"`
+	Buckets        []float64                 `json:"buckets,omitempty"`
+	SizeBuckets    []float64                 `json:"size_buckets,omitempty"`
+	ConstLabels    prometheus.Labels         `json:"const_labels,omitempty"`
+	SkipPaths      []string                  `json:"skip_paths,omitempty"`
+	Skipper        middleware.Skipper        `json:"-"`
+	RouteLabelFunc func(echo.Context) string `json:"-"`
+	Registry       *prometheus.Registry      `json:"-"`
+	Collectors     []prometheus.Collector    `json:"-"`
+}
+
+// defaultMetricsSizeBuckets are echo-contrib prometheus middleware size buckets, 1 KB to 10 MB.
+var defaultMetricsSizeBuckets = []float64{
+	1 * kb, 2 * kb, 5 * kb, 10 * kb, 100 * kb, 500 * kb, 1 * mb, 2.5 * mb, 5 * mb, 10 * mb,
+}
+
+func (cfg *MetricsConfig) registerer() prometheus.Registerer {
+	if cfg.Registry != nil {
+		return cfg.Registry
+	}
+
+	return prometheus.DefaultRegisterer
+}
+
+// MetricsHandler serves metrics gathered from cfg.Registry in prometheus exposition format.
+func MetricsHandler(cfg *MetricsConfig) echo.HandlerFunc {
+	if cfg.Registry == nil {
+		return echo.WrapHandler(promhttp.Handler())
+	}
+
+	return echo.WrapHandler(promhttp.InstrumentMetricHandler(
+		cfg.Registry,
+		promhttp.HandlerFor(cfg.Registry, promhttp.HandlerOpts{}),
+	))
+}
+
+// MetricsMiddleware records HTTP metrics labeled by status code, method & url (route template):
+//   - <namespace>_<subsystem>_requests_total counter, also labeled by host
+//   - <namespace>_<subsystem>_request_duration_seconds histogram
+//   - <namespace>_<subsystem>_request_size_bytes histogram, approximated from request line, headers & content length
+//   - <namespace>_<subsystem>_response_size_bytes histogram
+//   - <namespace>_<subsystem>_requests_in_flight gauge, without labels
+//
+// Metric names, labels & default buckets are the same as echo-contrib prometheus middleware
+// previously used by RunServerWithContext, so existing dashboards & alerts keep working.
+// Differences: request not matching any route is labeled `url="unmatched"` instead of its raw path,
+// requests_in_flight is new.
+//
+// Route template label keeps cardinality bounded, set RouteLabelFunc to use another label value,
+// e.g. raw URL path for a small set of static routes.
+func MetricsMiddleware(cfg *MetricsConfig) echo.MiddlewareFunc {
+	if cfg.Subsystem == "" {
+		cfg.Subsystem = defaultMetricsSubsystem
+	}
+
+	if len(cfg.Buckets) == 0 {
+		cfg.Buckets = prometheus.DefBuckets
+	}
+
+	if len(cfg.SizeBuckets) == 0 {
+		cfg.SizeBuckets = defaultMetricsSizeBuckets
+	}
+
+	if cfg.RouteLabelFunc == nil {
+		cfg.RouteLabelFunc = metricsRouteTemplate
+	}
+
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+
+	reg := cfg.registerer()
+	labels := []string{"code", "method", "url"}
+
+	requests := registerCollector(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   cfg.Namespace,
+		Subsystem:   cfg.Subsystem,
+		Name:        "requests_total",
+		Help:        "Number of HTTP requests served.",
+		ConstLabels: cfg.ConstLabels,
+	}, []string{"code", "method", "host", "url"}))
+
+	duration := registerCollector(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   cfg.Namespace,
+		Subsystem:   cfg.Subsystem,
+		Name:        "request_duration_seconds",
+		Help:        "HTTP request latency in seconds.",
+		ConstLabels: cfg.ConstLabels,
+		Buckets:     cfg.Buckets,
+	}, labels))
+
+	reqSize := registerCollector(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   cfg.Namespace,
+		Subsystem:   cfg.Subsystem,
+		Name:        "request_size_bytes",
+		Help:        "HTTP request size in bytes.",
+		ConstLabels: cfg.ConstLabels,
+		Buckets:     cfg.SizeBuckets,
+	}, labels))
+
+	size := registerCollector(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   cfg.Namespace,
+		Subsystem:   cfg.Subsystem,
+		Name:        "response_size_bytes",
+		Help:        "HTTP response size in bytes.",
+		ConstLabels: cfg.ConstLabels,
+		Buckets:     cfg.SizeBuckets,
+	}, labels))
+
+	inFlight := registerCollector(reg, prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   cfg.Namespace,
+		Subsystem:   cfg.Subsystem,
+		Name:        "requests_in_flight",
+		Help:        "Number of HTTP requests being served.",
+		ConstLabels: cfg.ConstLabels,
+	}))
+
+	for _, c := range cfg.Collectors {
+		registerCollector(reg, c)
+	}
+
+	skipPaths := make(map[string]struct{}, len(cfg.SkipPaths))
+	for _, p := range cfg.SkipPaths {
+		skipPaths[p] = struct{}{}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if _, skip := skipPaths[ctx.Path()]; skip || cfg.Skipper(ctx) {
+				return next(ctx)
+			}
+
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			start := time.Now()
+			reqSz := approximateRequestSize(ctx.Request())
+
+			err := next(ctx)
+			if err != nil {
+				// write error response now to get its status & size
+				ctx.Error(err)
+			}
+
+			req := ctx.Request()
+			resp := ctx.Response()
+			code, url := strconv.Itoa(resp.Status), cfg.RouteLabelFunc(ctx)
+			lv := []string{code, req.Method, url}
+
+			requests.WithLabelValues(code, req.Method, req.Host, url).Inc()
+			duration.WithLabelValues(lv...).Observe(time.Since(start).Seconds())
+			reqSize.WithLabelValues(lv...).Observe(float64(reqSz))
+			size.WithLabelValues(lv...).Observe(float64(resp.Size))
+
+			return err
+		}
+	}
+}
+
+// approximateRequestSize returns request size from request line, headers & content length,
+// same as echo-contrib prometheus middleware.
+func approximateRequestSize(r *http.Request) int {
+	s := len(r.Method) + len(r.Proto) + len(r.Host)
+
+	if r.URL != nil {
+		s += len(r.URL.Path)
+	}
+
+	for name, values := range r.Header {
+		s += len(name)
+
+		for _, v := range values {
+			s += len(v)
+		}
+	}
+
+	if r.ContentLength > 0 {
+		s += int(r.ContentLength)
+	}
+
+	return s
+}
+
+// metricsRouteTemplate returns matched route template,
+// echo sets raw request path as ctx.Path() when no route matches.
+func metricsRouteTemplate(ctx echo.Context) string {
+	if ctx.Path() == "" || (ctx.Response().Status == http.StatusNotFound && isNotFoundHandler(ctx.Handler())) {
+		return MetricsRouteUnmatched
+	}
+
+	return ctx.Path()
+}
+
+func isNotFoundHandler(h echo.HandlerFunc) bool {
+	return h != nil && reflect.ValueOf(h).Pointer() == reflect.ValueOf(echo.NotFoundHandler).Pointer()
+}
@@ -0,0 +1,106 @@
+package echokit_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+)
+
+var errOrderFailed = errors.New("order failed")
+
+func TestMetricsMiddleware(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	orders := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "orders_created_total",
+		Help: "Number of created orders.",
+	})
+
+	cfg := &echokit.MetricsConfig{
+		Namespace:   "shop",
+		Buckets:     []float64{0.1, 1},
+		SizeBuckets: []float64{10, 1000},
+		SkipPaths:   []string{"/metrics"},
+		Registry:    registry,
+		Collectors:  []prometheus.Collector{orders},
+	}
+
+	e := echo.New()
+	e.Use(echokit.MetricsMiddleware(cfg))
+
+	e.GET("/orders/:id", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, "order")
+	})
+	e.POST("/orders", func(ctx echo.Context) error {
+		orders.Inc()
+
+		return errOrderFailed
+	})
+	e.GET("/metrics", echokit.MetricsHandler(cfg))
+
+	for _, r := range []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/orders/1"},
+		{http.MethodGet, "/orders/2"},
+		{http.MethodPost, "/orders"},
+		{http.MethodGet, "/unknown/path"},
+		{http.MethodGet, "/metrics"},
+	} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(r.method, r.path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	b, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	body := string(b)
+
+	assert.Contains(t, body, `shop_echo_requests_total{code="200",host="example.com",method="GET",url="/orders/:id"} 2`)
+	assert.Contains(t, body, `shop_echo_requests_total{code="500",host="example.com",method="POST",url="/orders"} 1`)
+	assert.Contains(t, body, `shop_echo_requests_total{code="404",host="example.com",method="GET",url="unmatched"} 1`)
+	assert.Contains(t, body, `shop_echo_request_duration_seconds_bucket{code="200",method="GET",url="/orders/:id",le="0.1"} 2`)
+	assert.Contains(t, body, `shop_echo_request_size_bytes_bucket{code="200",method="GET",url="/orders/:id",le="1000"} 2`)
+	assert.Contains(t, body, `shop_echo_response_size_bytes_bucket{code="200",method="GET",url="/orders/:id",le="10"} 2`)
+	assert.Contains(t, body, `shop_echo_requests_in_flight 0`)
+	assert.Contains(t, body, `orders_created_total 1`)
+	assert.NotContains(t, body, `url="/metrics"`)
+	assert.NotContains(t, body, `/unknown/path`)
+}
+
+func TestMetricsRouteLabelFunc(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	cfg := &echokit.MetricsConfig{
+		Registry: registry,
+		RouteLabelFunc: func(ctx echo.Context) string {
+			return ctx.Request().URL.Path
+		},
+	}
+
+	e := echo.New()
+	e.Use(echokit.MetricsMiddleware(cfg))
+	e.GET("/orders/:id", func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusNoContent)
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, echokit.MetricsHandler(cfg)(e.NewContext(httptest.NewRequest(http.MethodGet, "/metrics", nil), rec)))
+
+	assert.Contains(t, rec.Body.String(), `echo_requests_total{code="204",host="example.com",method="GET",url="/orders/1"} 1`)
+}
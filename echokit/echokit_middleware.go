@@ -3,18 +3,12 @@ package echokit
 
 import (
 	"context"
-	"fmt"
-	"strings"
-	"time"
-
-	shortuuid "github.com/lithammer/shortuuid/v3"
+	"net/http"
 
 	"github.com/adipurnama/go-toolkit/log"
 
 	"github.com/adipurnama/go-toolkit/web"
 
-	"github.com/labstack/echo/v4/middleware"
-
 	echo "github.com/labstack/echo/v4"
 )
 
@@ -23,10 +17,18 @@ import (
 // RequestIDLoggerMiddleware - adds request ID for incoming http request.
 // it also set request with new context with logger
 // it's useful when you want to using log package with requestID.
+//
+// trace ID is taken from W3C `traceparent`, Elastic APM `elastic-apm-traceparent`
+// or Zipkin B3 headers, then from trace ID header, so it matches the APM trace.
+// Otherwise new W3C compliant trace ID is generated. The request's span is written
+// as `traceparent` response header & its TraceContext is added to request context,
+// see web.TraceContextFromContext.
 func RequestIDLoggerMiddleware(cfg *RuntimeConfig, o ...Option) echo.MiddlewareFunc {
 	opts := options{
-		rIDKey: web.HTTPKeyRequestID,
-		tIDKey: web.HTTPKeyTraceID,
+		rIDKey:      web.HTTPKeyRequestID,
+		tIDKey:      web.HTTPKeyTraceID,
+		rIDProvider: web.DefaultRequestIDProvider(),
+		tIDGen:      web.DefaultTraceIDGenerator(),
 	}
 
 	for _, o := range o {
@@ -34,18 +36,22 @@ func RequestIDLoggerMiddleware(cfg *RuntimeConfig, o ...Option) echo.MiddlewareF
 	}
 
 	m := &rIDLoggerMiddleware{
-		rIDKey: opts.rIDKey,
-		tIDKey: opts.tIDKey,
-		cfg:    cfg,
+		rIDKey:      opts.rIDKey,
+		tIDKey:      opts.tIDKey,
+		rIDProvider: opts.rIDProvider,
+		tIDGen:      opts.tIDGen,
+		cfg:         cfg,
 	}
 
 	return m.handle
 }
 
 type rIDLoggerMiddleware struct {
-	rIDKey string
-	tIDKey string
-	cfg    *RuntimeConfig
+	rIDKey      string
+	tIDKey      string
+	rIDProvider web.RequestIDProvider
+	tIDGen      web.TraceIDGenerator
+	cfg         *RuntimeConfig
 }
 
 func (m *rIDLoggerMiddleware) handle(next echo.HandlerFunc) echo.HandlerFunc {
@@ -62,10 +68,9 @@ func (m *rIDLoggerMiddleware) handle(next echo.HandlerFunc) echo.HandlerFunc {
 		logger := log.FromCtx(ctx.Request().Context())
 
 		// trace ID
-		tID := ctx.Request().Header.Get(m.tIDKey)
+		tc, tID := m.traceContext(ctx.Request().Header)
 
-		if tID == "" {
-			tID = shortuuid.New()
+		if ctx.Request().Header.Get(m.tIDKey) == "" {
 			ctx.Request().Header.Add(web.HTTPKeyTraceID, tID)
 
 			if web.HTTPKeyTraceID != m.tIDKey {
@@ -74,13 +79,15 @@ func (m *rIDLoggerMiddleware) handle(next echo.HandlerFunc) echo.HandlerFunc {
 		}
 
 		ctx.Response().Header().Set(web.HTTPKeyTraceID, tID)
+		ctx.Response().Header().Set(web.HTTPKeyTraceParent, tc.TraceParent())
 		logger.AddField("trace_id", tID)
+		logger.AddField("span_id", tc.SpanID)
 
 		// request ID
 		rID := ctx.Request().Header.Get(m.rIDKey)
 
 		if rID == "" {
-			rID = shortuuid.New()
+			rID = m.rIDProvider.NewRequestID()
 
 			ctx.Request().Header.Add(web.HTTPKeyRequestID, rID)
 
@@ -93,6 +100,9 @@ func (m *rIDLoggerMiddleware) handle(next echo.HandlerFunc) echo.HandlerFunc {
 		logger.AddField("request_id", rID)
 
 		rCtx = log.AddToContext(rCtx, logger)
+		rCtx = web.ContextWithTraceContext(rCtx, tc)
+		rCtx = context.WithValue(rCtx, web.ContextKeyTraceID, tID)
+		rCtx = context.WithValue(rCtx, web.ContextKeyRequestID, rID)
 
 		ctx.SetRequest(ctx.Request().WithContext(rCtx))
 
@@ -100,9 +110,34 @@ func (m *rIDLoggerMiddleware) handle(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
+// traceContext returns the request's span, child of caller's trace context if any, and its trace ID.
+// legacy trace ID header value which isn't W3C compliant is kept as trace ID.
+func (m *rIDLoggerMiddleware) traceContext(h http.Header) (web.TraceContext, string) {
+	if parent, ok := web.TraceContextFromHeader(h); ok {
+		tc := parent.ChildOf(m.tIDGen)
+
+		return tc, tc.TraceID
+	}
+
+	tc := web.NewTraceContext(m.tIDGen)
+
+	tID := h.Get(m.tIDKey)
+	if tID == "" {
+		return tc, tc.TraceID
+	}
+
+	if legacy := (web.TraceContext{TraceID: tID, SpanID: tc.SpanID}); legacy.IsValid() {
+		tc.TraceID = tID
+	}
+
+	return tc, tID
+}
+
 type options struct {
-	rIDKey string
-	tIDKey string
+	rIDKey      string
+	tIDKey      string
+	rIDProvider web.RequestIDProvider
+	tIDGen      web.TraceIDGenerator
 }
 
 // Option sets options for request middleware.
@@ -128,64 +163,22 @@ func WithTraceIDKey(key string) Option {
 	}
 }
 
-// BodyDumpHandler logs incoming request & outgoing response body.
-func BodyDumpHandler(skipper middleware.Skipper) echo.MiddlewareFunc {
-	return middleware.BodyDumpWithConfig(
-		middleware.BodyDumpConfig{
-			Skipper: skipper,
-			Handler: bodyDumpHandlerFunc,
-		},
-	)
-}
-
-func bodyDumpHandlerFunc(c echo.Context, reqBody []byte, respBody []byte) {
-	l := log.FromCtx(c.Request().Context())
-	respStr := strings.Replace(string(respBody), "}\n", "}", 1)
-	reqStr := strings.Replace(string(reqBody), "}\n", "}", 1)
-	msg := fmt.Sprintf("%s %s - http request completed", c.Request().Method, c.Request().URL.Path)
-
-	l.Debug(msg,
-		"http.response", respStr,
-		"http.request", reqStr,
-		"http.status_code", c.Response().Status,
-		"http.header", c.Request().Header,
-	)
-}
-
-// TimeoutConfig request timeout configuration
-// default value:
-//   - timeout: 7 seconds
-//   - middleware.DefaultSkipper / apply to all url
-type TimeoutConfig struct {
-	Timeout time.Duration      `json:"timeout,omitempty"`
-	Skipper middleware.Skipper `json:"-"`
-}
-
-// TimeoutMiddleware sets upstream request context's timeout.
-func TimeoutMiddleware(cfg *TimeoutConfig) echo.MiddlewareFunc {
-	// setup default value
-	if cfg.Skipper == nil {
-		cfg.Skipper = middleware.DefaultSkipper
-	}
-
-	if cfg.Timeout <= 0 {
-		cfg.Timeout = defaultReqTimeout
+// WithRequestIDProvider returns an Option which sets p as request-ID generator
+// for request without request-ID, defaults to web.DefaultRequestIDProvider.
+func WithRequestIDProvider(p web.RequestIDProvider) Option {
+	return func(o *options) {
+		if p != nil {
+			o.rIDProvider = p
+		}
 	}
+}
 
-	return func(next echo.HandlerFunc) echo.HandlerFunc {
-		return func(ctx echo.Context) error {
-			if skip := cfg.Skipper(ctx); skip {
-				return next(ctx)
-			}
-
-			req := ctx.Request()
-
-			rCtx, cancel := context.WithTimeout(req.Context(), cfg.Timeout)
-			defer cancel()
-
-			ctx.SetRequest(req.WithContext(rCtx))
-
-			return next(ctx)
+// WithTraceIDGenerator returns an Option which sets g as trace & span ID generator,
+// defaults to web.DefaultTraceIDGenerator.
+func WithTraceIDGenerator(g web.TraceIDGenerator) Option {
+	return func(o *options) {
+		if g != nil {
+			o.tIDGen = g
 		}
 	}
 }
@@ -9,6 +9,8 @@ import (
 	"github.com/adipurnama/go-toolkit/echokit"
 	"github.com/adipurnama/go-toolkit/web"
 	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -72,3 +74,66 @@ func TestRequestIDMiddleware(t *testing.T) {
 		}
 	})
 }
+
+type fixedIDProvider struct{}
+
+func (fixedIDProvider) NewRequestID() string { return "req-1" }
+func (fixedIDProvider) NewTraceID() string   { return "0af7651916cd43dd8448eb211c80319c" }
+func (fixedIDProvider) NewSpanID() string    { return "b7ad6b7169203331" }
+
+func TestRequestIDMiddlewareTraceContext(t *testing.T) {
+	e := echo.New()
+
+	var got web.TraceContext
+
+	handler := func(ctx echo.Context) error {
+		got, _ = web.TraceContextFromContext(ctx.Request().Context())
+
+		return nil
+	}
+
+	t.Run("continues W3C traceparent", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(web.HTTPKeyTraceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		rec := httptest.NewRecorder()
+		mid := echokit.RequestIDLoggerMiddleware(&echokit.RuntimeConfig{})
+
+		require.NoError(t, mid(handler)(e.NewContext(req, rec)))
+
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", got.TraceID)
+		assert.Equal(t, "00f067aa0ba902b7", got.ParentSpanID)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", rec.Header().Get(web.HTTPKeyTraceID))
+		assert.Equal(t, got.TraceParent(), rec.Header().Get(web.HTTPKeyTraceParent))
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", req.Header.Get(web.HTTPKeyTraceID))
+	})
+
+	t.Run("generates IDs using custom providers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		mid := echokit.RequestIDLoggerMiddleware(&echokit.RuntimeConfig{},
+			echokit.WithRequestIDProvider(fixedIDProvider{}),
+			echokit.WithTraceIDGenerator(fixedIDProvider{}),
+		)
+
+		require.NoError(t, mid(handler)(e.NewContext(req, rec)))
+
+		assert.Equal(t, "req-1", rec.Header().Get(web.HTTPKeyRequestID))
+		assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", rec.Header().Get(web.HTTPKeyTraceID))
+		assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", rec.Header().Get(web.HTTPKeyTraceParent))
+	})
+
+	t.Run("keeps legacy trace ID header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(web.HTTPKeyTraceID, "legacy-trace-id")
+
+		rec := httptest.NewRecorder()
+		mid := echokit.RequestIDLoggerMiddleware(&echokit.RuntimeConfig{})
+
+		require.NoError(t, mid(handler)(e.NewContext(req, rec)))
+
+		assert.Equal(t, "legacy-trace-id", rec.Header().Get(web.HTTPKeyTraceID))
+		assert.True(t, got.IsValid())
+		assert.Equal(t, got.TraceParent(), rec.Header().Get(web.HTTPKeyTraceParent))
+	})
+}
@@ -0,0 +1,347 @@
+package echokit
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	echo "github.com/labstack/echo/v4"
+
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+const (
+	defaultOpenAPIPath = "/actuator/openapi.json"
+	openAPIVersion     = "3.0.3"
+
+	schemaHTTPError      = "HTTPError"
+	schemaProblemDetails = "ProblemDetails"
+)
+
+// OpenAPIOperation describes route's request & response types for OpenAPI document.
+//
+// Request fields tagged with `param`, `query` & `header` are documented as parameters,
+// other fields as JSON request body. Schema required flags & constraints are derived
+// from `validate` tags, e.g. required, min, max, oneof, email.
+type OpenAPIOperation struct {
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+
+	// Request is request DTO value, e.g. `CreateUserRequest{}`
+	Request interface{}
+	// Response is success response DTO value, nil for empty response
+	Response interface{}
+	// Envelope wraps Response inside web.Response, see echokit.Handle
+	Envelope bool
+	// Status is success response status code, default 200
+	Status int
+	// Errors are documented error response status codes, default 400 & 500
+	Errors []int
+}
+
+// OpenAPI builds OpenAPI 3 document from echo routes & their documented operations.
+type OpenAPI struct {
+	info           openapi3.Info
+	problemDetails bool
+
+	mu         sync.RWMutex
+	operations map[string]OpenAPIOperation
+}
+
+// NewOpenAPI returns new *OpenAPI document builder.
+func NewOpenAPI(title, version string) *OpenAPI {
+	return &OpenAPI{
+		info: openapi3.Info{
+			Title:   title,
+			Version: version,
+		},
+		operations: make(map[string]OpenAPIOperation),
+	}
+}
+
+// WithProblemDetails documents error responses as RFC 7807 problem details,
+// see RuntimeConfig.EnableProblemDetails.
+func (o *OpenAPI) WithProblemDetails(enabled bool) *OpenAPI {
+	o.problemDetails = enabled
+
+	return o
+}
+
+// Document annotates route r with op & returns r, e.g.
+//
+//	api.Document(e.POST("/users", h.CreateUser), echokit.OpenAPIOperation{
+//		Request:  dto.CreateUserRequest{},
+//		Response: dto.CreateUserResponse{},
+//		Status:   http.StatusCreated,
+//	})
+func (o *OpenAPI) Document(r *echo.Route, op OpenAPIOperation) *echo.Route {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.operations[r.Method+" "+r.Path] = op
+
+	return r
+}
+
+// Spec returns OpenAPI document of all e routes,
+// undocumented routes only have their path params & default responses.
+func (o *OpenAPI) Spec(e *echo.Echo) *openapi3.T {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	info := o.info
+
+	doc := &openapi3.T{
+		OpenAPI:    openAPIVersion,
+		Info:       &info,
+		Paths:      make(openapi3.Paths),
+		Components: &openapi3.Components{},
+	}
+
+	g := newSchemaGenerator()
+	errResp := o.errorResponse(g)
+
+	routes := e.Routes()
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].Path < routes[j].Path
+	})
+
+	for _, r := range routes {
+		if !IsOpenAPIMethod(r.Method) {
+			continue
+		}
+
+		path := OpenAPIRoutePath(r.Path)
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &openapi3.PathItem{}
+			doc.Paths[path] = item
+		}
+
+		item.SetOperation(r.Method, o.operation(g, r, errResp))
+	}
+
+	doc.Components.Schemas = g.schemas
+
+	return doc
+}
+
+// Handler returns echo.HandlerFunc serving e's OpenAPI JSON document.
+func (o *OpenAPI) Handler(e *echo.Echo) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		return ctx.JSON(http.StatusOK, o.Spec(e))
+	}
+}
+
+func (o *OpenAPI) operation(g *schemaGenerator, r *echo.Route, errResp *openapi3.Response) *openapi3.Operation {
+	op := o.operations[r.Method+" "+r.Path]
+
+	result := openapi3.NewOperation()
+	result.OperationID = op.OperationID
+	result.Summary = op.Summary
+	result.Description = op.Description
+	result.Tags = op.Tags
+	result.Deprecated = op.Deprecated
+	result.Responses = openapi3.NewResponses()
+	// NewResponses contains default response
+	delete(result.Responses, "default")
+
+	var reqType reflect.Type
+
+	if op.Request != nil {
+		reqType = reflect.TypeOf(op.Request)
+		for reqType.Kind() == reflect.Ptr {
+			reqType = reqType.Elem()
+		}
+	}
+
+	result.Parameters = parameters(g, r.Path, reqType)
+
+	if reqType != nil && reqType.Kind() == reflect.Struct && hasBodyFields(reqType) {
+		body := openapi3.NewRequestBody().
+			WithRequired(true).
+			WithJSONSchema(g.schema(reqType, true))
+		result.RequestBody = &openapi3.RequestBodyRef{Value: body}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	success := openapi3.NewResponse().WithDescription(http.StatusText(status))
+
+	if op.Response != nil {
+		respSchema := g.schemaRef(reflect.TypeOf(op.Response))
+
+		if op.Envelope {
+			envelope := g.schema(reflect.TypeOf(web.Response{}), false)
+			envelope.Properties["data"] = respSchema
+			respSchema = openapi3.NewSchemaRef("", envelope)
+		}
+
+		success.Content = openapi3.NewContentWithJSONSchemaRef(respSchema)
+	}
+
+	result.Responses[strconv.Itoa(status)] = &openapi3.ResponseRef{Value: success}
+
+	errCodes := op.Errors
+	if len(errCodes) == 0 {
+		errCodes = []int{http.StatusBadRequest, http.StatusInternalServerError}
+	}
+
+	for _, code := range errCodes {
+		desc := http.StatusText(code)
+		resp := *errResp
+		resp.Description = &desc
+		result.Responses[strconv.Itoa(code)] = &openapi3.ResponseRef{Value: &resp}
+	}
+
+	return result
+}
+
+// errorResponse returns error response documented from web.HTTPError or web.ProblemDetails.
+func (o *OpenAPI) errorResponse(g *schemaGenerator) *openapi3.Response {
+	if o.problemDetails {
+		g.schemas[schemaProblemDetails] = openapi3.NewSchemaRef("", g.schema(reflect.TypeOf(web.ProblemDetails{}), false))
+
+		return openapi3.NewResponse().WithContent(openapi3.NewContentWithSchemaRef(
+			openapi3.NewSchemaRef(componentSchemaRefPrefix+schemaProblemDetails, nil),
+			[]string{web.MIMEApplicationProblemJSON},
+		))
+	}
+
+	s := g.schema(reflect.TypeOf(web.HTTPError{}), false)
+	// validation & binding errors details
+	s.Properties["response"] = g.schemaRef(reflect.TypeOf(web.ErrorDetails{}))
+	g.schemas[schemaHTTPError] = openapi3.NewSchemaRef("", s)
+
+	return openapi3.NewResponse().WithJSONSchemaRef(openapi3.NewSchemaRef(componentSchemaRefPrefix+schemaHTTPError, nil))
+}
+
+// parameters returns path params of route path & request's `param`, `query` & `header` fields.
+func parameters(g *schemaGenerator, path string, reqType reflect.Type) openapi3.Parameters {
+	var (
+		result   openapi3.Parameters
+		declared = make(map[string]bool)
+	)
+
+	if reqType != nil && reqType.Kind() == reflect.Struct {
+		forEachField(reqType, func(f reflect.StructField) {
+			for _, in := range []string{openapi3.ParameterInPath, openapi3.ParameterInQuery, openapi3.ParameterInHeader} {
+				tag := in
+				if in == openapi3.ParameterInPath {
+					tag = "param"
+				}
+
+				name := f.Tag.Get(tag)
+				if name == "" {
+					continue
+				}
+
+				p := &openapi3.Parameter{
+					Name:     name,
+					In:       in,
+					Required: in == openapi3.ParameterInPath || parseValidateTag(f.Tag.Get("validate")).has("required"),
+					Schema:   openapi3.NewSchemaRef("", g.schema(f.Type, false)),
+				}
+				applyValidateRules(p.Schema.Value, parseValidateTag(f.Tag.Get("validate")))
+
+				declared[in+":"+name] = true
+				result = append(result, &openapi3.ParameterRef{Value: p})
+			}
+		})
+	}
+
+	// path params without request field
+	for _, segment := range strings.Split(path, "/") {
+		name := pathParamName(segment)
+		if name == "" || declared[openapi3.ParameterInPath+":"+name] {
+			continue
+		}
+
+		p := openapi3.NewPathParameter(name).WithSchema(openapi3.NewStringSchema())
+		result = append(result, &openapi3.ParameterRef{Value: p})
+	}
+
+	return result
+}
+
+func hasBodyFields(t reflect.Type) bool {
+	found := false
+
+	forEachField(t, func(f reflect.StructField) {
+		if _, omit := jsonFieldName(f); omit {
+			return
+		}
+
+		if f.Tag.Get("json") != "" || !isBoundOutsideBody(f) {
+			found = true
+		}
+	})
+
+	return found
+}
+
+// forEachField calls fn for t's fields, including embedded struct fields.
+func forEachField(t reflect.Type, fn func(f reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if f.Anonymous && ft.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+			forEachField(ft, fn)
+			continue
+		}
+
+		fn(f)
+	}
+}
+
+// OpenAPIRoutePath converts echo route path into OpenAPI path, e.g. `/users/:id` to `/users/{id}`.
+func OpenAPIRoutePath(path string) string {
+	segments := strings.Split(path, "/")
+
+	for i, s := range segments {
+		if name := pathParamName(s); name != "" {
+			segments[i] = "{" + name + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func pathParamName(segment string) string {
+	switch {
+	case strings.HasPrefix(segment, ":"):
+		return segment[1:]
+	case segment == "*":
+		return "wildcard"
+	default:
+		return ""
+	}
+}
+
+// IsOpenAPIMethod reports whether routes of method are documented by OpenAPI,
+// e.g. echo.Any also registers WebDAV methods, which aren't.
+func IsOpenAPIMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,285 @@
+package echokit
+
+import (
+	"encoding"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const componentSchemaRefPrefix = "#/components/schemas/"
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	invalidComponentNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+)
+
+// schemaGenerator generates OpenAPI schema from go types,
+// named struct types are stored as components schemas.
+type schemaGenerator struct {
+	schemas openapi3.Schemas
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{schemas: make(openapi3.Schemas)}
+}
+
+// schemaRef returns schema of t, named struct is returned as component reference.
+func (g *schemaGenerator) schemaRef(t reflect.Type) *openapi3.SchemaRef {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || t.Name() == "" || t == timeType {
+		return openapi3.NewSchemaRef("", g.schema(t, false))
+	}
+
+	name := componentName(t)
+
+	if _, ok := g.schemas[name]; !ok {
+		// placeholder prevents infinite recursion for recursive types
+		g.schemas[name] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema())
+		g.schemas[name].Value = g.schema(t, false)
+	}
+
+	return openapi3.NewSchemaRef(componentSchemaRefPrefix+name, nil)
+}
+
+// schema returns inline schema of t.
+// bodyOnly excludes fields bound from path, query or header without `json` tag.
+func (g *schemaGenerator) schema(t reflect.Type, bodyOnly bool) *openapi3.Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return openapi3.NewDateTimeSchema()
+	}
+
+	if t.Kind() != reflect.Struct && reflect.PtrTo(t).Implements(textMarshalerType) {
+		return openapi3.NewStringSchema()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return openapi3.NewBoolSchema()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return openapi3.NewInt32Schema()
+	case reflect.Int64, reflect.Uint64:
+		return openapi3.NewInt64Schema()
+	case reflect.Float32, reflect.Float64:
+		return openapi3.NewFloat64Schema()
+	case reflect.String:
+		return openapi3.NewStringSchema()
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return openapi3.NewBytesSchema()
+		}
+
+		s := openapi3.NewArraySchema()
+		s.Items = g.schemaRef(t.Elem())
+
+		return s
+	case reflect.Map:
+		s := openapi3.NewObjectSchema()
+		s.AdditionalProperties = openapi3.AdditionalProperties{Schema: g.schemaRef(t.Elem())}
+
+		return s
+	case reflect.Struct:
+		s := openapi3.NewObjectSchema()
+		g.addProperties(s, t, bodyOnly)
+
+		return s
+	default:
+		// interface{} accepts any value
+		return openapi3.NewSchema()
+	}
+}
+
+func (g *schemaGenerator) addProperties(s *openapi3.Schema, t reflect.Type, bodyOnly bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name, omit := jsonFieldName(f)
+		if omit {
+			continue
+		}
+
+		if bodyOnly && f.Tag.Get("json") == "" && isBoundOutsideBody(f) {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				g.addProperties(s, ft, bodyOnly)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := g.schemaRef(f.Type)
+
+		rules := parseValidateTag(f.Tag.Get("validate"))
+		if rules.has("required") {
+			s.Required = append(s.Required, name)
+		}
+
+		if prop.Ref == "" {
+			applyValidateRules(prop.Value, rules)
+		}
+
+		s.Properties[name] = prop
+	}
+}
+
+// jsonFieldName returns field's json name, omit is true for unexported or `json:"-"` field.
+func jsonFieldName(f reflect.StructField) (name string, omit bool) {
+	if !f.IsExported() && !f.Anonymous {
+		return "", true
+	}
+
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+
+	name, _, _ = strings.Cut(tag, ",")
+
+	return name, false
+}
+
+func isBoundOutsideBody(f reflect.StructField) bool {
+	for _, tag := range []string{"param", "query", "header"} {
+		if f.Tag.Get(tag) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateRules is parsed go-playground/validator `validate` struct tag.
+type validateRules map[string]string
+
+func parseValidateTag(tag string) validateRules {
+	rules := make(validateRules)
+
+	for _, r := range strings.Split(tag, ",") {
+		// rules after `dive` belongs to slice items
+		if r == "dive" {
+			break
+		}
+
+		name, param, _ := strings.Cut(r, "=")
+		rules[strings.TrimSpace(name)] = param
+	}
+
+	return rules
+}
+
+func (r validateRules) has(name string) bool {
+	_, ok := r[name]
+
+	return ok
+}
+
+// applyValidateRules maps common validate tags into schema constraints.
+func applyValidateRules(s *openapi3.Schema, rules validateRules) {
+	for name, param := range rules {
+		switch name {
+		case "email":
+			s.Format = "email"
+		case "uuid", "uuid4":
+			s.Format = "uuid"
+		case "url", "uri":
+			s.Format = "uri"
+		case "datetime":
+			s.Format = "date-time"
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, enumValue(s.Type, v))
+			}
+		case "min", "gte":
+			setMin(s, param, false)
+		case "gt":
+			setMin(s, param, true)
+		case "max", "lte":
+			setMax(s, param, false)
+		case "lt":
+			setMax(s, param, true)
+		case "len":
+			setMin(s, param, false)
+			setMax(s, param, false)
+		}
+	}
+}
+
+func setMin(s *openapi3.Schema, param string, exclusive bool) {
+	switch s.Type {
+	case openapi3.TypeString:
+		if n, err := strconv.ParseUint(param, 10, 64); err == nil {
+			s.MinLength = n
+		}
+	case openapi3.TypeArray:
+		if n, err := strconv.ParseUint(param, 10, 64); err == nil {
+			s.MinItems = n
+		}
+	case openapi3.TypeInteger, openapi3.TypeNumber:
+		if n, err := strconv.ParseFloat(param, 64); err == nil {
+			s.Min = &n
+			s.ExclusiveMin = exclusive
+		}
+	}
+}
+
+func setMax(s *openapi3.Schema, param string, exclusive bool) {
+	switch s.Type {
+	case openapi3.TypeString:
+		if n, err := strconv.ParseUint(param, 10, 64); err == nil {
+			s.MaxLength = &n
+		}
+	case openapi3.TypeArray:
+		if n, err := strconv.ParseUint(param, 10, 64); err == nil {
+			s.MaxItems = &n
+		}
+	case openapi3.TypeInteger, openapi3.TypeNumber:
+		if n, err := strconv.ParseFloat(param, 64); err == nil {
+			s.Max = &n
+			s.ExclusiveMax = exclusive
+		}
+	}
+}
+
+func enumValue(schemaType, v string) interface{} {
+	switch schemaType {
+	case openapi3.TypeInteger:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case openapi3.TypeNumber:
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+
+	return v
+}
+
+// componentName returns valid components schema name for named type t.
+func componentName(t reflect.Type) string {
+	return invalidComponentNameChars.ReplaceAllString(t.Name(), "_")
+}
@@ -0,0 +1,137 @@
+package echokit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+	"github.com/adipurnama/go-toolkit/echokit/echotestkit"
+)
+
+type createOrderRequest struct {
+	TenantID string      `header:"X-Tenant-ID" validate:"required"`
+	DryRun   bool        `query:"dry_run"`
+	Currency string      `json:"currency" validate:"required,oneof=IDR USD"`
+	Items    []orderItem `json:"items" validate:"required,min=1,dive"`
+	Note     string      `json:"note,omitempty" validate:"max=140"`
+}
+
+type orderItem struct {
+	SKU      string `json:"sku" validate:"required"`
+	Quantity int    `json:"quantity" validate:"gte=1"`
+}
+
+type orderResponse struct {
+	ID        string      `json:"id"`
+	Items     []orderItem `json:"items"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type getOrderRequest struct {
+	ID int64 `param:"id"`
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	noop := func(ctx echo.Context) error { return nil }
+
+	e := echo.New()
+	api := echokit.NewOpenAPI("orders", "1.0.0")
+
+	api.Document(e.POST("/orders", noop), echokit.OpenAPIOperation{
+		Summary:  "create order",
+		Request:  createOrderRequest{},
+		Response: orderResponse{},
+		Envelope: true,
+		Status:   http.StatusCreated,
+		Errors:   []int{http.StatusBadRequest, http.StatusConflict},
+	})
+	api.Document(e.GET("/orders/:id", noop), echokit.OpenAPIOperation{
+		Request:  getOrderRequest{},
+		Response: orderResponse{},
+	})
+	e.DELETE("/orders/:id", noop)
+	// echo.Any also registers WebDAV methods, which aren't documented nor checked
+	e.Any("/files/:name", noop)
+
+	e.GET("/actuator/openapi.json", api.Handler(e))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/actuator/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	spec, err := openapi3.NewLoader().LoadFromData(rec.Body.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, spec.Validate(context.Background()), rec.Body.String())
+
+	echotestkit.AssertRoutesDocumented(t, e, spec, "/actuator")
+
+	create := spec.Paths.Find("/orders").Post
+	require.NotNil(t, create)
+	assert.Equal(t, "create order", create.Summary)
+
+	params := map[string]*openapi3.Parameter{}
+	for _, p := range create.Parameters {
+		params[p.Value.In+":"+p.Value.Name] = p.Value
+	}
+
+	require.Contains(t, params, "header:X-Tenant-ID")
+	assert.True(t, params["header:X-Tenant-ID"].Required)
+	require.Contains(t, params, "query:dry_run")
+	assert.Equal(t, openapi3.TypeBoolean, params["query:dry_run"].Schema.Value.Type)
+
+	body := create.RequestBody.Value.Content.Get(echo.MIMEApplicationJSON).Schema.Value
+	assert.ElementsMatch(t, []string{"currency", "items"}, body.Required)
+	assert.NotContains(t, body.Properties, "TenantID")
+	assert.Equal(t, []interface{}{"IDR", "USD"}, body.Properties["currency"].Value.Enum)
+	assert.Equal(t, uint64(1), body.Properties["items"].Value.MinItems)
+	assert.Equal(t, uint64(140), *body.Properties["note"].Value.MaxLength)
+	assert.Equal(t, "#/components/schemas/orderItem", body.Properties["items"].Value.Items.Ref)
+
+	created := create.Responses.Get(http.StatusCreated).Value.Content.Get(echo.MIMEApplicationJSON).Schema.Value
+	assert.Equal(t, "#/components/schemas/orderResponse", created.Properties["data"].Ref)
+	assert.Equal(t, "#/components/schemas/HTTPError",
+		create.Responses.Get(http.StatusConflict).Value.Content.Get(echo.MIMEApplicationJSON).Schema.Ref)
+
+	get := spec.Paths.Find("/orders/{id}").Get
+	require.NotNil(t, get)
+	require.Len(t, get.Parameters, 1)
+	assert.Equal(t, "int64", get.Parameters[0].Value.Schema.Value.Format)
+
+	files := spec.Paths.Find("/files/{name}")
+	require.NotNil(t, files)
+	assert.NotNil(t, files.Put)
+	assert.Len(t, files.Operations(), 8, "only standard methods are documented")
+	assert.True(t, echokit.IsOpenAPIMethod(http.MethodPut))
+	assert.False(t, echokit.IsOpenAPIMethod("PROPFIND"))
+
+	itemSchema := spec.Components.Schemas["orderItem"].Value
+	assert.Equal(t, float64(1), *itemSchema.Properties["quantity"].Value.Min)
+
+	t.Run("undocumented route fails the check", func(t *testing.T) {
+		e.PATCH("/orders/:id", noop)
+
+		fake := &fakeTB{TB: t}
+		assert.False(t, echotestkit.AssertRoutesDocumented(fake, e, spec, "/actuator"))
+		assert.True(t, fake.failed)
+	})
+}
+
+// fakeTB records assertion failure instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.failed = true
+}
+
+func (f *fakeTB) Helper() {}
@@ -0,0 +1,341 @@
+package echokit
+
+import (
+	"bytes"
+	"encoding/json"
+	stdErrors "errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	ut "github.com/go-playground/universal-translator"
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/pkg/errors"
+
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+const (
+	exceptionRequestContract  = "request contract violation found"
+	exceptionResponseContract = "response contract violation found"
+)
+
+var openAPIPathParam = regexp.MustCompile(`\{[^}]*\}`)
+
+// OpenAPIValidatorConfig OpenAPI contract validator middleware configuration
+// default value:
+//   - AuthenticationFunc: openapi3filter.NoopAuthenticationFunc, security schemes are left to auth middleware, e.g. authkit
+//   - ValidateResponse: false, validating response buffers the whole response body, enable it in tests
+type OpenAPIValidatorConfig struct {
+	Skipper middleware.Skipper `json:"-"`
+
+	// Spec is OpenAPI document, see LoadOpenAPIFile & LoadOpenAPIFS
+	Spec *openapi3.T `json:"-"`
+
+	// ValidateResponse replaces off-contract handler response with 500 error response
+	ValidateResponse bool `json:"validate_response,omitempty"`
+
+	AuthenticationFunc openapi3filter.AuthenticationFunc `json:"-"`
+}
+
+// LoadOpenAPIFile loads & validates OpenAPI 3 document (JSON or YAML) from file path,
+// external `$ref` relative to the file are resolved.
+func LoadOpenAPIFile(path string) (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+
+	spec, err := loader.LoadFromFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load openapi document %s", path)
+	}
+
+	if err := spec.Validate(loader.Context); err != nil {
+		return nil, errors.Wrapf(err, "invalid openapi document %s", path)
+	}
+
+	return spec, nil
+}
+
+// LoadOpenAPIFS loads & validates OpenAPI 3 document (JSON or YAML) from fsys, e.g. embed.FS,
+// external `$ref` relative to the document are resolved within fsys.
+func LoadOpenAPIFS(fsys fs.FS, name string) (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(_ *openapi3.Loader, location *url.URL) ([]byte, error) {
+		return fs.ReadFile(fsys, path.Clean(strings.TrimPrefix(location.Path, "/")))
+	}
+
+	spec, err := loader.LoadFromURI(&url.URL{Path: name})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load openapi document %s", name)
+	}
+
+	if err := spec.Validate(loader.Context); err != nil {
+		return nil, errors.Wrapf(err, "invalid openapi document %s", name)
+	}
+
+	return spec, nil
+}
+
+// OpenAPIValidatorMiddleware validates request path params, query params, headers & body
+// against cfg.Spec operation of the matched echo route.
+//
+// Request violating the contract gets *web.HTTPError (400) with translated field errors,
+// the same format as Bind. Routes not in the document are not validated,
+// see echotestkit.AssertRoutesDocumented.
+func OpenAPIValidatorMiddleware(cfg *OpenAPIValidatorConfig) echo.MiddlewareFunc {
+	if cfg.Spec == nil {
+		panic("echokit: OpenAPIValidatorConfig.Spec cannot be nil")
+	}
+
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+
+	if cfg.AuthenticationFunc == nil {
+		cfg.AuthenticationFunc = openapi3filter.NoopAuthenticationFunc
+	}
+
+	// spec paths indexed by template without param names, e.g. `/users/{}`
+	paths := make(map[string]string, len(cfg.Spec.Paths))
+	for p := range cfg.Spec.Paths {
+		paths[openAPIPathParam.ReplaceAllString(p, "{}")] = p
+	}
+
+	opts := &openapi3filter.Options{
+		MultiError:         true,
+		AuthenticationFunc: cfg.AuthenticationFunc,
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if cfg.Skipper(ctx) {
+				return next(ctx)
+			}
+
+			input := requestValidationInput(ctx, cfg.Spec, paths, opts)
+			if input == nil {
+				return next(ctx)
+			}
+
+			req := ctx.Request()
+			trans := web.TranslatorFromContext(req.Context())
+
+			if err := openapi3filter.ValidateRequest(req.Context(), input); err != nil {
+				return newContractError(trans, http.StatusBadRequest, exceptionRequestContract, err)
+			}
+
+			if !cfg.ValidateResponse {
+				return next(ctx)
+			}
+
+			return validateResponse(ctx, next, input, trans)
+		}
+	}
+}
+
+// requestValidationInput returns validation input of ctx's route operation, nil if it's not documented.
+func requestValidationInput(ctx echo.Context, spec *openapi3.T, paths map[string]string, opts *openapi3filter.Options) *openapi3filter.RequestValidationInput {
+	specPath, ok := paths[openAPIPathParam.ReplaceAllString(OpenAPIRoutePath(ctx.Path()), "{}")]
+	if !ok {
+		return nil
+	}
+
+	item := spec.Paths[specPath]
+
+	req := ctx.Request()
+
+	op := item.GetOperation(req.Method)
+	if op == nil {
+		return nil
+	}
+
+	// echo & spec param names may differ, they're matched by position
+	pathParams := make(map[string]string)
+	values := ctx.ParamValues()
+
+	for i, name := range openAPIPathParam.FindAllString(specPath, -1) {
+		if i < len(values) {
+			pathParams[strings.Trim(name, "{}")] = values[i]
+		}
+	}
+
+	return &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route: &routers.Route{
+			Spec:      spec,
+			Path:      specPath,
+			PathItem:  item,
+			Method:    req.Method,
+			Operation: op,
+		},
+		Options: opts,
+	}
+}
+
+// validateResponse buffers handler response & replaces it with 500 error response when it's off-contract.
+func validateResponse(ctx echo.Context, next echo.HandlerFunc, input *openapi3filter.RequestValidationInput, trans ut.Translator) error {
+	resp := ctx.Response()
+	buf := &bufferedResponseWriter{ResponseWriter: resp.Writer}
+	resp.Writer = buf
+
+	err := next(ctx)
+	if err != nil {
+		// write error response now, so it's validated as well
+		ctx.Error(err)
+	}
+
+	resp.Writer = buf.ResponseWriter
+
+	if !resp.Committed {
+		return err
+	}
+
+	rCtx := ctx.Request().Context()
+
+	errValidate := openapi3filter.ValidateResponse(rCtx, &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 resp.Status,
+		Header:                 resp.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(buf.body.Bytes())),
+		Options:                &openapi3filter.Options{MultiError: true, IncludeResponseStatus: true},
+	})
+	if errValidate == nil {
+		return buf.flush(resp.Status)
+	}
+
+	log.FromCtx(rCtx).Error(errValidate, "handler response violates openapi contract",
+		"method", ctx.Request().Method,
+		"route", ctx.Path(),
+		"status", resp.Status,
+	)
+
+	httpErr := newContractError(trans, http.StatusInternalServerError, exceptionResponseContract, errValidate)
+
+	h := resp.Header()
+	h.Del(echo.HeaderContentLength)
+	h.Del(echo.HeaderContentEncoding)
+	h.Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+
+	b, errEnc := json.Marshal(httpErr)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	resp.Status = httpErr.Code
+
+	buf.body.Reset()
+	buf.body.Write(b)
+
+	return buf.flush(httpErr.Code)
+}
+
+// newContractError returns *web.HTTPError with field errors from openapi3filter validation error.
+func newContractError(trans ut.Translator, code int, exception string, err error) *web.HTTPError {
+	fields := contractViolations(trans, err, bindFieldBody)
+	if len(fields) == 0 {
+		fields = []web.ErrorField{{Field: bindFieldBody, Message: translate(trans, bindKeyContractViolation, bindFieldBody, err.Error())}}
+	}
+
+	return &web.HTTPError{
+		Code:    code,
+		Message: fields[0].Message,
+		Response: web.ErrorDetails{
+			Exception: exception,
+			Errors:    fields,
+		},
+	}
+}
+
+// contractViolations flattens openapi3filter validation error into field errors.
+func contractViolations(trans ut.Translator, err error, field string) []web.ErrorField {
+	var (
+		multi     openapi3.MultiError
+		errReq    *openapi3filter.RequestError
+		errResp   *openapi3filter.ResponseError
+		errSchema *openapi3.SchemaError
+		errParse  *openapi3filter.ParseError
+	)
+
+	switch {
+	case stdErrors.As(err, &multi):
+		var result []web.ErrorField
+		for _, e := range multi {
+			result = append(result, contractViolations(trans, e, field)...)
+		}
+
+		return result
+	case stdErrors.As(err, &errReq) && errReq.Err != nil:
+		name := field
+		if errReq.Parameter != nil {
+			name = errReq.Parameter.Name
+		}
+
+		if stdErrors.Is(errReq.Err, openapi3filter.ErrInvalidRequired) {
+			return []web.ErrorField{{Field: name, Message: translate(trans, bindKeyRequired, name)}}
+		}
+
+		return contractViolations(trans, errReq.Err, name)
+	case stdErrors.As(err, &errReq):
+		name := field
+		if errReq.Parameter != nil {
+			name = errReq.Parameter.Name
+		}
+
+		return []web.ErrorField{{Field: name, Message: translate(trans, bindKeyContractViolation, name, errReq.Reason)}}
+	case stdErrors.As(err, &errResp) && errResp.Err != nil:
+		return contractViolations(trans, errResp.Err, field)
+	case stdErrors.As(err, &errResp):
+		return []web.ErrorField{{Field: field, Message: translate(trans, bindKeyContractViolation, field, errResp.Reason)}}
+	case stdErrors.As(err, &errSchema):
+		name := field
+		if ptr := errSchema.JSONPointer(); len(ptr) > 0 {
+			name = strings.Join(ptr, ".")
+			if field != bindFieldBody {
+				name = field + "." + name
+			}
+		}
+
+		if errSchema.SchemaField == "required" {
+			return []web.ErrorField{{Field: name, Message: translate(trans, bindKeyRequired, name)}}
+		}
+
+		return []web.ErrorField{{Field: name, Message: translate(trans, bindKeyContractViolation, name, errSchema.Reason)}}
+	case stdErrors.As(err, &errParse):
+		return []web.ErrorField{{Field: field, Message: translate(trans, bindKeyInvalidValue, field)}}
+	default:
+		return []web.ErrorField{{Field: field, Message: translate(trans, bindKeyContractViolation, field, err.Error())}}
+	}
+}
+
+// bufferedResponseWriter holds response status & body until flushed.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *bufferedResponseWriter) WriteHeader(int) {}
+
+// Write implements http.ResponseWriter.
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferedResponseWriter) flush(code int) error {
+	w.ResponseWriter.WriteHeader(code)
+
+	_, err := w.ResponseWriter.Write(w.body.Bytes())
+
+	return err
+}
@@ -0,0 +1,197 @@
+package echokit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+const petsOpenAPI = `
+openapi: 3.0.3
+info:
+  title: pets
+  version: 1.0.0
+paths:
+  /pets/{petId}:
+    put:
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema:
+            type: integer
+        - name: X-Tenant-ID
+          in: header
+          required: true
+          schema:
+            type: string
+        - name: notify
+          in: query
+          schema:
+            type: boolean
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: 'schemas.yaml#/Pet'
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: 'schemas.yaml#/Pet'
+`
+
+const petsSchemas = `
+Pet:
+  type: object
+  required: [name, tags]
+  properties:
+    name:
+      type: string
+      minLength: 2
+    tags:
+      type: array
+      items:
+        type: string
+`
+
+type pet struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func TestOpenAPIValidatorMiddleware(t *testing.T) {
+	spec, err := echokit.LoadOpenAPIFS(fstest.MapFS{
+		"api/openapi.yaml": {Data: []byte(petsOpenAPI)},
+		"api/schemas.yaml": {Data: []byte(petsSchemas)},
+	}, "api/openapi.yaml")
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(echokit.OpenAPIValidatorMiddleware(&echokit.OpenAPIValidatorConfig{
+		Spec:             spec,
+		ValidateResponse: true,
+	}))
+	e.HTTPErrorHandler = func(err error, ctx echo.Context) {
+		if ctx.Response().Committed {
+			return
+		}
+
+		var errHTTP *web.HTTPError
+		if errors.As(err, &errHTTP) {
+			_ = ctx.JSON(errHTTP.Code, errHTTP)
+			return
+		}
+
+		e.DefaultHTTPErrorHandler(err, ctx)
+	}
+
+	e.PUT("/pets/:id", func(ctx echo.Context) error {
+		var body pet
+		if err := (&echo.DefaultBinder{}).BindBody(ctx, &body); err != nil {
+			return err
+		}
+
+		if ctx.QueryParam("notify") == "true" {
+			// off-contract response
+			return ctx.JSON(http.StatusOK, map[string]interface{}{"name": 1})
+		}
+
+		return ctx.JSON(http.StatusOK, body)
+	})
+	e.GET("/undocumented", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, "ok")
+	})
+
+	call := func(path, body string, header ...string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		var result map[string]interface{}
+		_ = json.Unmarshal(rec.Body.Bytes(), &result)
+
+		return rec.Code, result
+	}
+
+	fieldErrors := func(t *testing.T, result map[string]interface{}) map[string]string {
+		t.Helper()
+
+		response, ok := result["response"].(map[string]interface{})
+		require.True(t, ok, result)
+
+		fields := map[string]string{}
+		for _, f := range response["errors"].([]interface{}) {
+			f := f.(map[string]interface{})
+			fields[f["field"].(string)] = f["message"].(string)
+		}
+
+		return fields
+	}
+
+	t.Run("valid request", func(t *testing.T) {
+		code, result := call("/pets/1", `{"name":"kitty","tags":["cat"]}`, "X-Tenant-ID", "acme")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "kitty", result["name"])
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		code, result := call("/pets/abc?notify=maybe", `{"name":"k","tags":[1]}`)
+		require.Equal(t, http.StatusBadRequest, code)
+
+		fields := fieldErrors(t, result)
+		assert.Equal(t, "petId has invalid value", fields["petId"])
+		assert.Equal(t, "X-Tenant-ID is required", fields["X-Tenant-ID"])
+		assert.Contains(t, fields, "notify")
+		assert.Contains(t, fields["name"], "name does not match API contract")
+		assert.Contains(t, fields, "tags.0")
+	})
+
+	t.Run("missing required property", func(t *testing.T) {
+		code, result := call("/pets/1", `{"name":"kitty"}`, "X-Tenant-ID", "acme")
+		require.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, "tags is required", fieldErrors(t, result)["tags"])
+	})
+
+	t.Run("off-contract response", func(t *testing.T) {
+		code, result := call("/pets/1?notify=true", `{"name":"kitty","tags":[]}`, "X-Tenant-ID", "acme")
+		require.Equal(t, http.StatusInternalServerError, code)
+		assert.Equal(t, "response contract violation found", result["response"].(map[string]interface{})["exception"])
+	})
+
+	t.Run("undocumented route", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/undocumented", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestLoadOpenAPIFile(t *testing.T) {
+	_, err := echokit.LoadOpenAPIFile("not-found.yaml")
+	assert.Error(t, err)
+
+	_, err = echokit.LoadOpenAPIFS(fstest.MapFS{
+		"openapi.yaml": {Data: []byte("openapi: 3.0.3\ninfo:\n  title: invalid\n")},
+	}, "openapi.yaml")
+	assert.Error(t, err)
+}
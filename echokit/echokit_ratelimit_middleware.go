@@ -0,0 +1,114 @@
+package echokit
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/ratelimitkit"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+// rate limit response headers
+// see https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers/
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+)
+
+// RateLimitKeyFunc extracts rate limit key from request.
+// empty key means the request is not rate limited.
+type RateLimitKeyFunc func(ctx echo.Context) string
+
+// RateLimitKeyByIP returns RateLimitKeyFunc using client IP from web.GetIP.
+func RateLimitKeyByIP() RateLimitKeyFunc {
+	return func(ctx echo.Context) string {
+		return "ip:" + web.GetIP(ctx.Request())
+	}
+}
+
+// RateLimitKeyByHeader returns RateLimitKeyFunc using request header value, e.g. `X-API-Key`.
+// The value is hashed, see ratelimitkit.HashedKey.
+func RateLimitKeyByHeader(header string) RateLimitKeyFunc {
+	return func(ctx echo.Context) string {
+		v := ctx.Request().Header.Get(header)
+		if v == "" {
+			return ""
+		}
+
+		return ratelimitkit.HashedKey(header, v)
+	}
+}
+
+// RateLimitConfig rate limit middleware configuration
+// default value:
+//   - key func: RateLimitKeyByIP
+//   - middleware.DefaultSkipper / apply to all url
+type RateLimitConfig struct {
+	Limiter ratelimitkit.Limiter `json:"-"`
+	KeyFunc RateLimitKeyFunc     `json:"-"`
+	Skipper middleware.Skipper   `json:"-"`
+}
+
+// RateLimitMiddleware limits request rate per key using cfg.Limiter.
+// It writes RateLimit-* response headers, and returns errors.AppError with
+// CodeResourceExhausted (429) & Retry-After header when the limit is reached.
+// Limiter error (e.g. redis is down) is logged and the request is allowed.
+func RateLimitMiddleware(cfg *RateLimitConfig) echo.MiddlewareFunc {
+	if cfg.Limiter == nil {
+		panic("echokit: RateLimitConfig.Limiter cannot be nil")
+	}
+
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = RateLimitKeyByIP()
+	}
+
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if cfg.Skipper(ctx) {
+				return next(ctx)
+			}
+
+			key := cfg.KeyFunc(ctx)
+			if key == "" {
+				return next(ctx)
+			}
+
+			res, err := cfg.Limiter.Allow(ctx.Request().Context(), key)
+			if err != nil {
+				log.FromCtx(ctx.Request().Context()).WarnError(err, "rate limiter failed, request is allowed", "key", key)
+
+				return next(ctx)
+			}
+
+			h := ctx.Response().Header()
+			h.Set(HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+			h.Set(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+			h.Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(res.Reset)))
+
+			if !res.Allowed {
+				retryAfter := ceilSeconds(res.RetryAfter)
+				h.Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfter))
+
+				return apperrors.New(apperrors.CodeResourceExhausted).
+					WithDetail("retry_after_seconds", retryAfter)
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
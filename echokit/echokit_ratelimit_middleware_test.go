@@ -0,0 +1,64 @@
+package echokit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+	"github.com/adipurnama/go-toolkit/ratelimitkit"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	limiter, err := ratelimitkit.NewMemoryLimiter(ratelimitkit.TokenBucket, ratelimitkit.Limit{
+		Rate:   1,
+		Period: time.Minute,
+		Burst:  2,
+	})
+	require.NoError(t, err)
+
+	e := echo.New()
+	mid := echokit.RateLimitMiddleware(&echokit.RateLimitConfig{
+		Limiter: limiter,
+		KeyFunc: echokit.RateLimitKeyByHeader("X-API-Key"),
+	})
+
+	handler := mid(func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	})
+
+	call := func(apiKey string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+
+		rec := httptest.NewRecorder()
+
+		return rec, handler(e.NewContext(req, rec))
+	}
+
+	for i := 0; i < 2; i++ {
+		rec, err := call("key-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "2", rec.Header().Get(echokit.HeaderRateLimitLimit))
+		assert.Equal(t, strconv.Itoa(1-i), rec.Header().Get(echokit.HeaderRateLimitRemaining))
+	}
+
+	rec, err := call("key-1")
+	assert.Equal(t, apperrors.CodeResourceExhausted, apperrors.CodeOf(err))
+	assert.Equal(t, "0", rec.Header().Get(echokit.HeaderRateLimitRemaining))
+	assert.Equal(t, "60", rec.Header().Get(echo.HeaderRetryAfter))
+
+	// request without key is not limited
+	rec, err = call("")
+	assert.NoError(t, err)
+	assert.Empty(t, rec.Header().Get(echokit.HeaderRateLimitLimit))
+}
@@ -0,0 +1,70 @@
+package echokit
+
+import (
+	"net/http"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/runtimekit"
+)
+
+// RecoverConfig panic recovery middleware configuration
+// default value:
+//   - middleware.DefaultSkipper / apply to all url
+//   - reporter: nil, panic is only logged
+type RecoverConfig struct {
+	Skipper  middleware.Skipper       `json:"-"`
+	Reporter runtimekit.PanicReporter `json:"-"`
+}
+
+// RecoverMiddleware recovers panic from the handler chain, logs it with its stack trace
+// using logger from request context and returns errors.AppError with CodeInternal (500).
+// The panic value is not exposed in the response.
+//
+// cfg.Reporter is called with the recovered panic, e.g. to send it to error tracker.
+// http.ErrAbortHandler is re-panicked so net/http aborts the response.
+func RecoverMiddleware(cfg *RecoverConfig) echo.MiddlewareFunc {
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) (err error) {
+			if cfg.Skipper(ctx) {
+				return next(ctx)
+			}
+
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+
+				// net/http sentinel to abort response silently
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+
+				pErr := runtimekit.NewPanicError(r)
+				rCtx := ctx.Request().Context()
+
+				log.FromCtx(rCtx).Error(
+					pErr,
+					"recovered from panic",
+					"panic_stack", pErr.Stack,
+				)
+
+				if cfg.Reporter != nil {
+					cfg.Reporter(rCtx, pErr)
+				}
+
+				err = apperrors.Wrap(pErr, apperrors.CodeInternal)
+			}()
+
+			return next(ctx)
+		}
+	}
+}
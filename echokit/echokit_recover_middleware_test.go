@@ -0,0 +1,95 @@
+package echokit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/runtimekit"
+)
+
+var errOrderNotLoaded = errors.New("order is not loaded")
+
+func TestRecoverMiddleware(t *testing.T) {
+	var (
+		buf      bytes.Buffer
+		reported *runtimekit.PanicError
+	)
+
+	logger := &log.Logger{Level: log.LevelDebug, StdLog: zerolog.New(&buf), ErrLog: zerolog.New(&buf)}
+
+	e := echo.New()
+	mid := echokit.RecoverMiddleware(&echokit.RecoverConfig{
+		Reporter: func(ctx context.Context, err *runtimekit.PanicError) {
+			reported = err
+		},
+	})
+
+	call := func(h echo.HandlerFunc) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+		req = req.WithContext(log.AddToContext(req.Context(), logger))
+		rec := httptest.NewRecorder()
+
+		return rec, mid(h)(e.NewContext(req, rec))
+	}
+
+	t.Run("panic with error", func(t *testing.T) {
+		buf.Reset()
+
+		_, err := call(func(ctx echo.Context) error {
+			panic(errOrderNotLoaded)
+		})
+
+		assert.Equal(t, apperrors.CodeInternal, apperrors.CodeOf(err))
+		assert.ErrorIs(t, err, errOrderNotLoaded)
+
+		appErr, ok := apperrors.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusInternalServerError, appErr.HTTPStatus)
+
+		require.NotNil(t, reported)
+		assert.Equal(t, errOrderNotLoaded, reported.Value)
+		assert.Contains(t, buf.String(), "recovered from panic")
+		assert.Contains(t, buf.String(), "panic_stack")
+	})
+
+	t.Run("panic with value", func(t *testing.T) {
+		_, err := call(func(ctx echo.Context) error {
+			panic("nil map")
+		})
+
+		var pErr *runtimekit.PanicError
+
+		require.ErrorAs(t, err, &pErr)
+		assert.Equal(t, "nil map", pErr.Value)
+		assert.Contains(t, string(pErr.Stack), "echokit_recover_middleware_test.go")
+	})
+
+	t.Run("no panic", func(t *testing.T) {
+		rec, err := call(func(ctx echo.Context) error {
+			return ctx.NoContent(http.StatusNoContent)
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("abort handler", func(t *testing.T) {
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			_, _ = call(func(ctx echo.Context) error {
+				panic(http.ErrAbortHandler)
+			})
+		})
+	})
+}
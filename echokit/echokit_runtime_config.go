@@ -1,9 +1,16 @@
 package echokit
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	"github.com/spf13/cast"
+
+	"github.com/adipurnama/go-toolkit/concurrencykit"
 	"github.com/adipurnama/go-toolkit/config"
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/tlskit"
 )
 
 /*
@@ -15,8 +22,31 @@ with layout:
 		restapi:
 		  port: 8088
 		  request-timeout: 10s
+		  request-timeout-enforced: true
+		  request-timeout-status-code: 504
+		  request-timeout-routes:
+		    "POST /reports/:id": 30s
 		  healthcheck-path: /health/info
 		  info-path: /actuator/info
+		  problem-details-enabled: true
+		  h2c-enabled: false
+		  metrics-path: /metrics
+		  metrics:
+		    namespace: shop
+		    buckets: [0.01, 0.05, 0.1, 0.5, 1, 5]
+		    skip-paths: [/actuator/info]
+		  concurrency-limit:
+		    algorithm: gradient
+		    initial-limit: 50
+		    max-limit: 500
+		    route-priorities:
+		      "POST /payments": critical
+		      "/reports": sheddable
+		  tls:
+		    cert-file: /etc/tls/tls.crt
+		    key-file: /etc/tls/tls.key
+		    client-ca-file: /etc/tls/ca.crt
+		    min-version: "1.2"
 		  shutdown:
 			wait-duration: 3s
 			timeout-duration: 5s
@@ -28,12 +58,48 @@ func NewRuntimeConfig(cfg config.KVStore, path string) *RuntimeConfig {
 
 	r.Port = cfg.GetInt(fmt.Sprintf("%s.port", path))
 	r.RequestTimeoutConfig = &TimeoutConfig{
-		Timeout: cfg.GetDuration(fmt.Sprintf("%s.request-timeout", path)),
+		Timeout:       cfg.GetDuration(fmt.Sprintf("%s.request-timeout", path)),
+		Enforced:      cfg.GetBool(fmt.Sprintf("%s.request-timeout-enforced", path)),
+		StatusCode:    cfg.GetInt(fmt.Sprintf("%s.request-timeout-status-code", path)),
+		RouteTimeouts: make(map[string]time.Duration),
+	}
+
+	for route, t := range cfg.GetStringMap(fmt.Sprintf("%s.request-timeout-routes", path)) {
+		r.RequestTimeoutConfig.RouteTimeouts[route] = cast.ToDuration(t)
 	}
 	r.ShutdownTimeoutDuration = cfg.GetDuration(fmt.Sprintf("%s.shutdown.timeout-duration", path))
 	r.ShutdownWaitDuration = cfg.GetDuration(fmt.Sprintf("%s.shutdown.wait-duration", path))
 	r.HealthCheckPath = cfg.GetString(fmt.Sprintf("%s.healthcheck-path", path))
 	r.InfoCheckPath = cfg.GetString(fmt.Sprintf("%s.info-path", path))
+	r.EnableProblemDetails = cfg.GetBool(fmt.Sprintf("%s.problem-details-enabled", path))
+	r.H2CEnabled = cfg.GetBool(fmt.Sprintf("%s.h2c-enabled", path))
+	r.TLS = tlskit.NewConfig(cfg, fmt.Sprintf("%s.tls", path))
+	r.MetricsPath = cfg.GetString(fmt.Sprintf("%s.metrics-path", path))
+	r.Metrics = &MetricsConfig{
+		Namespace: cfg.GetString(fmt.Sprintf("%s.metrics.namespace", path)),
+		SkipPaths: cfg.GetStringSlice(fmt.Sprintf("%s.metrics.skip-paths", path)),
+	}
+
+	for _, b := range cast.ToSlice(cfg.Get(fmt.Sprintf("%s.metrics.buckets", path))) {
+		r.Metrics.Buckets = append(r.Metrics.Buckets, cast.ToFloat64(b))
+	}
+
+	if clPath := fmt.Sprintf("%s.concurrency-limit", path); cfg.IsSet(clPath) {
+		r.ConcurrencyLimit = &ConcurrencyLimitConfig{
+			Limit:           concurrencykit.NewConfig(cfg, clPath),
+			RoutePriorities: make(map[string]concurrencykit.Priority),
+		}
+
+		for route, p := range cfg.GetStringMap(fmt.Sprintf("%s.route-priorities", clPath)) {
+			priority, err := concurrencykit.ParsePriority(cast.ToString(p))
+			if err != nil {
+				log.FromCtx(context.Background()).WarnError(err, "invalid route priority is ignored", "route", route)
+				continue
+			}
+
+			r.ConcurrencyLimit.RoutePriorities[route] = priority
+		}
+	}
 
 	return &r
 }
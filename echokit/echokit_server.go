@@ -9,14 +9,15 @@ import (
 	"strings"
 	"time"
 
-	validator "github.com/go-playground/validator/v10"
 	"github.com/iancoleman/strcase"
-	echo_prometheus "github.com/labstack/echo-contrib/prometheus"
 	echo "github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/net/http2"
 
+	apperrors "github.com/adipurnama/go-toolkit/errors"
 	"github.com/adipurnama/go-toolkit/log"
 	"github.com/adipurnama/go-toolkit/runtimekit"
+	"github.com/adipurnama/go-toolkit/tlskit"
 	"github.com/adipurnama/go-toolkit/web"
 )
 
@@ -31,14 +32,22 @@ const (
 
 // RuntimeConfig defines echo REST API runtime config with healthcheck.
 type RuntimeConfig struct {
-	Port                    int            `json:"port,omitempty"`
-	Name                    string         `json:"name,omitempty"`
-	BuildInfo               string         `json:"build_info,omitempty"`
-	ShutdownWaitDuration    time.Duration  `json:"shutdown_wait_duration,omitempty"`
-	ShutdownTimeoutDuration time.Duration  `json:"shutdown_timeout_duration,omitempty"`
-	RequestTimeoutConfig    *TimeoutConfig `json:"request_timeout_config,omitempty"`
-	HealthCheckPath         string         `json:"health_check_path,omitempty"`
-	InfoCheckPath           string         `json:"info_check_path,omitempty"`
+	Port                    int                     `json:"port,omitempty"`
+	Name                    string                  `json:"name,omitempty"`
+	BuildInfo               string                  `json:"build_info,omitempty"`
+	ShutdownWaitDuration    time.Duration           `json:"shutdown_wait_duration,omitempty"`
+	ShutdownTimeoutDuration time.Duration           `json:"shutdown_timeout_duration,omitempty"`
+	RequestTimeoutConfig    *TimeoutConfig          `json:"request_timeout_config,omitempty"`
+	HealthCheckPath         string                  `json:"health_check_path,omitempty"`
+	InfoCheckPath           string                  `json:"info_check_path,omitempty"`
+	EnableProblemDetails    bool                    `json:"enable_problem_details,omitempty"`
+	OpenAPIPath             string                  `json:"openapi_path,omitempty"`
+	OpenAPI                 *OpenAPI                `json:"-"`
+	MetricsPath             string                  `json:"metrics_path,omitempty"`
+	Metrics                 *MetricsConfig          `json:"metrics,omitempty"`
+	ConcurrencyLimit        *ConcurrencyLimitConfig `json:"concurrency_limit,omitempty"`
+	TLS                     *tlskit.Config          `json:"tls,omitempty"`
+	H2CEnabled              bool                    `json:"h2c_enabled,omitempty"`
 	HealthCheckFunc         `json:"-"`
 }
 
@@ -65,11 +74,57 @@ func (cfg *RuntimeConfig) validate() {
 	if cfg.RequestTimeoutConfig.Skipper == nil {
 		cfg.RequestTimeoutConfig.Skipper = middleware.DefaultSkipper
 	}
+
+	// metrics
+	if cfg.MetricsPath == "" {
+		cfg.MetricsPath = defaultMetricsPath
+	}
+
+	if cfg.Metrics == nil {
+		cfg.Metrics = &MetricsConfig{}
+	}
+
+	if cfg.Metrics.Subsystem == "" {
+		cfg.Metrics.Subsystem = cfg.Name
+	}
+
+	if cfg.Metrics.Subsystem == "" {
+		cfg.Metrics.Subsystem = defaultMetricsSubsystem
+	}
+
+	cfg.Metrics.SkipPaths = append(cfg.Metrics.SkipPaths, cfg.HealthCheckPath, cfg.MetricsPath)
+
+	// load shedding, metrics share the same registry
+	if cl := cfg.ConcurrencyLimit; cl != nil {
+		if cl.Limiter == nil && cl.Limit != nil {
+			if cl.Limit.Name == "" {
+				cl.Limit.Name = cfg.Metrics.Subsystem
+			}
+
+			if cl.Limit.Registerer == nil {
+				cl.Limit.Registerer = cfg.Metrics.registerer()
+			}
+		}
+
+		skipper := cl.Skipper
+		if skipper == nil {
+			skipper = middleware.DefaultSkipper
+		}
+
+		cl.Skipper = func(c echo.Context) bool {
+			return c.Path() == cfg.HealthCheckPath || c.Path() == cfg.MetricsPath || skipper(c)
+		}
+	}
 }
 
 type healthStatus struct {
-	serving bool
-	Status  string `json:"status"`
+	Status  string         `json:"status"`
+	Details *healthDetails `json:"details,omitempty"`
+}
+
+type healthDetails struct {
+	InFlightRequests int  `json:"in_flight_requests"`
+	ShuttingDown     bool `json:"shutting_down"`
 }
 
 // HealthCheckFunc is healthcheck interface func.
@@ -84,49 +139,148 @@ func RunServer(e *echo.Echo, cfg *RuntimeConfig) {
 }
 
 // RunServerWithContext run graceful restapi server with existing background context
-// provides default '/actuator/health' as healthcheck endpoint
-// provides '/metrics' as prometheus metrics endpoint.
-// set echo.Validator using `web.Validator` from `web` package.
+// provides default '/actuator/health' as healthcheck endpoint, including in-flight requests count
+// provides '/metrics' as prometheus metrics endpoint, configurable using RuntimeConfig.MetricsPath & RuntimeConfig.Metrics,
+// set RuntimeConfig.Metrics.Registry to expose app collectors from custom registry.
+// set echo.Validator using `web.Validator` from `web` package,
+// set e.Validator with web.NewValidator before running to register custom rules & locales.
+// set RuntimeConfig.EnableProblemDetails to write all error responses
+// as RFC 7807 `application/problem+json`, custom e.HTTPErrorHandler still handles errors other than
+// echo.HTTPError, errors.AppError & web.ProblemDetails, echo's default handler is replaced.
+// set RuntimeConfig.OpenAPI to serve OpenAPI document at '/actuator/openapi.json'.
+// set RuntimeConfig.TLS to serve HTTPS (HTTP/2 negotiated unless e.DisableHTTP2),
+// with client CA file, verified client identity is added to request context, see tlskit.PeerIdentityFromContext.
+// set RuntimeConfig.ConcurrencyLimit to shed load above adaptive concurrency limit with 503 response.
+// set RuntimeConfig.H2CEnabled to serve HTTP/2 cleartext without TLS, e.g. for internal traffic.
+//
+// when appCtx is done, long-lived handlers are notified via ShutdownNotify & healthcheck returns 503,
+// then it waits up to RuntimeConfig.ShutdownWaitDuration, or until there's no in-flight request,
+// before shutting down the server. Requests still in-flight after RuntimeConfig.ShutdownTimeoutDuration are logged & dropped.
 func RunServerWithContext(appCtx context.Context, e *echo.Echo, cfg *RuntimeConfig) {
-	cfg.Name = strcase.ToSnake(cfg.Name)
-
 	logger := log.FromCtx(appCtx)
 
+	tracker, err := configureServer(e, cfg)
+	if err != nil {
+		logger.Error(err, "please provide healthcheck function to runtime config")
+		return
+	}
+
+	shutdownDone := make(chan struct{})
+
+	go func() {
+		defer close(shutdownDone)
+
+		<-appCtx.Done()
+
+		inFlight, _ := tracker.status()
+		logger.Info(fmt.Sprintf("shutting down REST HTTP server, waiting up to %d ms for %d in-flight requests",
+			cfg.ShutdownWaitDuration.Milliseconds(), inFlight))
+
+		tracker.drain(cfg.ShutdownWaitDuration)
+
+		// stop the server
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeoutDuration)
+		defer cancel()
+
+		if err := e.Shutdown(shutdownCtx); err != nil {
+			logger.Error(err, "shutdown http server")
+
+			tracker.logDropped(logger)
+
+			if err := e.Close(); err != nil {
+				logger.Error(err, "close http server")
+			}
+		}
+	}()
+
+	PrintRoutes(e)
+
+	// start server
+	logger.Info("serving REST HTTP server", "config", cfg)
+
+	if err := startServer(appCtx, e, cfg); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error(err, "starting http server")
+		return
+	}
+
+	// serve returns as soon as shutdown started, wait until in-flight requests are drained
+	<-shutdownDone
+}
+
+// ConfigureServer installs the same middlewares, healthcheck, info, OpenAPI & metrics routes
+// and error handler as RunServerWithContext on e, without starting the server,
+// e.g. to serve requests through real routing in tests, see echotestkit.NewServer.
+// It must be called once per *echo.Echo.
+func ConfigureServer(e *echo.Echo, cfg *RuntimeConfig) error {
+	_, err := configureServer(e, cfg)
+
+	return err
+}
+
+func configureServer(e *echo.Echo, cfg *RuntimeConfig) (*drainTracker, error) {
+	cfg.Name = strcase.ToSnake(cfg.Name)
+
 	e.HideBanner = true
-	validator := validator.New()
 
 	cfg.validate()
 
-	// request validator setup
-	e.Use(ValidatorTranslatorMiddleware(validator), TimeoutMiddleware(cfg.RequestTimeoutConfig))
-	e.Validator = web.NewValidator(validator)
+	// request validator setup, keeps *web.Validator set by app
+	// e.g. to register custom rules & locales
+	v, ok := e.Validator.(*web.Validator)
+	if !ok {
+		v = web.NewValidator(nil)
+	}
 
-	if cfg.HealthCheckFunc == nil {
-		log.FromCtx(appCtx).Error(errInvalidHealthCheckFunc, "please provide healthcheck function to runtime config")
-		return
+	// in-flight requests tracking, except healthcheck
+	tracker := newDrainTracker(cfg.Metrics.registerer(), cfg.Metrics.Subsystem, func(c echo.Context) bool {
+		return c.Path() == cfg.HealthCheckPath
+	})
+
+	e.Use(
+		tracker.middleware(),
+		MetricsMiddleware(cfg.Metrics),
+		RecoverMiddleware(&RecoverConfig{}),
+	)
+
+	if cfg.ConcurrencyLimit != nil {
+		e.Use(ConcurrencyLimitMiddleware(cfg.ConcurrencyLimit))
 	}
 
-	// healthcheck
-	hs := &healthStatus{
-		serving: true,
+	e.Use(TranslatorMiddleware(v), TimeoutMiddleware(cfg.RequestTimeoutConfig))
+	e.Validator = v
+
+	if cfg.TLS.Enabled() && cfg.TLS.ClientCAFile != "" {
+		e.Use(PeerIdentityMiddleware())
+	}
+
+	if cfg.HealthCheckFunc == nil {
+		return nil, errInvalidHealthCheckFunc
 	}
 
+	// healthcheck
 	e.GET(cfg.HealthCheckPath, func(c echo.Context) error {
-		if !hs.serving {
+		inFlight, shuttingDown := tracker.status()
+
+		hs := healthStatus{
+			Status: "UP",
+			Details: &healthDetails{
+				InFlightRequests: inFlight,
+				ShuttingDown:     shuttingDown,
+			},
+		}
+
+		if shuttingDown {
 			hs.Status = "OUT_OF_SERVICE"
 
 			return c.JSON(http.StatusServiceUnavailable, hs)
 		}
 
-		err := cfg.HealthCheckFunc(c.Request().Context())
-		if err != nil {
+		if err := cfg.HealthCheckFunc(c.Request().Context()); err != nil {
 			hs.Status = "OUT_OF_SERVICE"
 
 			return c.JSON(http.StatusServiceUnavailable, hs)
 		}
 
-		hs.Status = "UP"
-
 		return c.JSON(http.StatusOK, hs)
 	})
 
@@ -143,29 +297,22 @@ func RunServerWithContext(appCtx context.Context, e *echo.Echo, cfg *RuntimeConf
 		return c.JSON(http.StatusOK, v)
 	})
 
-	// prometheus
-	p := echo_prometheus.NewPrometheus(cfg.Name, nil)
-	p.Use(e)
-
-	go func() {
-		<-appCtx.Done()
-
-		hs.serving = false
-
-		logger.Info(fmt.Sprintf("shutting down REST HTTP server in %d ms", cfg.ShutdownWaitDuration.Milliseconds()))
-		<-time.After(cfg.ShutdownWaitDuration)
+	// OpenAPI document
+	if cfg.OpenAPI != nil {
+		if cfg.OpenAPIPath == "" {
+			cfg.OpenAPIPath = defaultOpenAPIPath
+		}
 
-		// stop the server
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeoutDuration)
-		defer cancel()
+		e.GET(cfg.OpenAPIPath, cfg.OpenAPI.Handler(e))
+	}
 
-		if err := e.Shutdown(shutdownCtx); err != nil {
-			logger.Error(err, "shutdown http server")
-		}
-	}()
+	// prometheus
+	e.GET(cfg.MetricsPath, MetricsHandler(cfg.Metrics))
 
 	// error fallback handler
 	originalErrHandler := e.HTTPErrorHandler
+	skipOriginalErrHandler := cfg.EnableProblemDetails && isDefaultHTTPErrorHandler(e, originalErrHandler)
+
 	e.HTTPErrorHandler = loggerHTTPErrorHandler(func(err error, respCtx echo.Context) {
 		var errEcho *echo.HTTPError
 
@@ -175,16 +322,54 @@ func RunServerWithContext(appCtx context.Context, e *echo.Echo, cfg *RuntimeConf
 			return
 		}
 
+		// echo's default handler doesn't write problem details response,
+		// let loggerHTTPErrorHandler writes it instead
+		if skipOriginalErrHandler {
+			return
+		}
+
+		var problem *web.ProblemDetails
+		if errors.As(err, &problem) {
+			return
+		}
+
+		if _, ok := apperrors.FromError(err); ok {
+			return
+		}
+
 		originalErrHandler(err, respCtx)
-	})
+	}, cfg.EnableProblemDetails)
 
-	PrintRoutes(e)
+	return tracker, nil
+}
 
-	// start server
-	logger.Info("serving REST HTTP server", "config", cfg)
+// startServer starts e as HTTPS, h2c or plain HTTP server based on cfg.
+func startServer(appCtx context.Context, e *echo.Echo, cfg *RuntimeConfig) error {
+	addr := fmt.Sprintf(":%d", cfg.Port)
 
-	if err := e.Start(fmt.Sprintf(":%d", cfg.Port)); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		logger.Error(err, "starting http server")
+	switch {
+	case cfg.TLS.Enabled():
+		if cfg.H2CEnabled {
+			log.FromCtx(appCtx).Warn("h2c is ignored, HTTP/2 is negotiated over TLS")
+		}
+
+		tlsCfg, err := cfg.TLS.ServerTLSConfig(appCtx)
+		if err != nil {
+			return err
+		}
+
+		if !e.DisableHTTP2 {
+			tlsCfg.NextProtos = []string{"h2", "http/1.1"}
+		}
+
+		e.TLSServer.Addr = addr
+		e.TLSServer.TLSConfig = tlsCfg
+
+		return e.StartServer(e.TLSServer)
+	case cfg.H2CEnabled:
+		return e.StartH2CServer(addr, &http2.Server{})
+	default:
+		return e.Start(addr)
 	}
 }
 
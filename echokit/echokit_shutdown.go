@@ -0,0 +1,191 @@
+package echokit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+// contextKeyShutdown to store/obtains server shutdown notification channel to/from request's context.
+var contextKeyShutdown = web.ContextKey("shutdownNotify")
+
+// ShutdownNotify returns channel closed when the server started by RunServerWithContext is shutting down.
+// Long-lived handlers, e.g. SSE or websocket, should finish when it's closed,
+// otherwise they're dropped when RuntimeConfig.ShutdownTimeoutDuration is reached.
+// Returns nil channel, which never closes, for request not served by RunServerWithContext.
+func ShutdownNotify(ctx context.Context) <-chan struct{} {
+	ch, _ := ctx.Value(contextKeyShutdown).(<-chan struct{})
+
+	return ch
+}
+
+type inFlightRequest struct {
+	method    string
+	uri       string
+	requestID string
+	startedAt time.Time
+}
+
+// drainTracker tracks in-flight requests, so shutdown can wait until they're done.
+type drainTracker struct {
+	skipper middleware.Skipper
+
+	mu           sync.Mutex
+	seq          uint64
+	active       map[uint64]inFlightRequest
+	shuttingDown bool
+	shutdown     chan struct{}
+	idle         chan struct{}
+
+	dropped      prometheus.Counter
+	drainSeconds prometheus.Gauge
+}
+
+func newDrainTracker(reg prometheus.Registerer, subsystem string, skipper middleware.Skipper) *drainTracker {
+	return &drainTracker{
+		skipper:  skipper,
+		active:   make(map[uint64]inFlightRequest),
+		shutdown: make(chan struct{}),
+		idle:     make(chan struct{}),
+		dropped: registerCollector(reg, prometheus.NewCounter(prometheus.CounterOpts{
+			Subsystem: subsystem,
+			Name:      "shutdown_dropped_requests_total",
+			Help:      "Number of in-flight HTTP requests dropped when shutdown timeout is reached.",
+		})),
+		drainSeconds: registerCollector(reg, prometheus.NewGauge(prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "shutdown_drain_duration_seconds",
+			Help:      "Time taken to drain in-flight HTTP requests on shutdown.",
+		})),
+	}
+}
+
+// registerCollector registers c to reg,
+// returns already registered collector, e.g. when server is restarted in tests.
+func registerCollector[T prometheus.Collector](reg prometheus.Registerer, c T) T {
+	err := reg.Register(c)
+
+	var errRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &errRegistered) {
+		if existing, ok := errRegistered.ExistingCollector.(T); ok {
+			return existing
+		}
+	}
+
+	return c
+}
+
+func (t *drainTracker) middleware() echo.MiddlewareFunc {
+	shutdown := (<-chan struct{})(t.shutdown)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if t.skipper(ctx) {
+				return next(ctx)
+			}
+
+			req := ctx.Request()
+
+			id := t.add(inFlightRequest{
+				method:    req.Method,
+				uri:       req.RequestURI,
+				requestID: req.Header.Get(web.HTTPKeyRequestID),
+				startedAt: time.Now(),
+			})
+			defer t.done(id)
+
+			ctx.SetRequest(req.WithContext(context.WithValue(req.Context(), contextKeyShutdown, shutdown)))
+
+			return next(ctx)
+		}
+	}
+}
+
+func (t *drainTracker) add(r inFlightRequest) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.seq++
+	t.active[t.seq] = r
+
+	return t.seq
+}
+
+func (t *drainTracker) done(id uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.active, id)
+
+	if t.shuttingDown && len(t.active) == 0 {
+		t.closeIdle()
+	}
+}
+
+// closeIdle must be called with t.mu held.
+func (t *drainTracker) closeIdle() {
+	select {
+	case <-t.idle:
+	default:
+		close(t.idle)
+	}
+}
+
+// status returns number of in-flight requests & whether shutdown has started.
+func (t *drainTracker) status() (inFlight int, shuttingDown bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.active), t.shuttingDown
+}
+
+// drain notifies long-lived handlers & waits until there's no in-flight request or maxWait elapsed.
+func (t *drainTracker) drain(maxWait time.Duration) {
+	start := time.Now()
+
+	t.mu.Lock()
+	if !t.shuttingDown {
+		t.shuttingDown = true
+		close(t.shutdown)
+
+		if len(t.active) == 0 {
+			t.closeIdle()
+		}
+	}
+	t.mu.Unlock()
+
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+
+	select {
+	case <-t.idle:
+	case <-timer.C:
+	}
+
+	t.drainSeconds.Set(time.Since(start).Seconds())
+}
+
+// logDropped logs requests still in-flight when shutdown timeout is reached.
+func (t *drainTracker) logDropped(logger *log.Logger) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, r := range t.active {
+		t.dropped.Inc()
+
+		logger.Warn("dropping in-flight request on shutdown",
+			"method", r.method,
+			"uri", r.uri,
+			"request_id", r.requestID,
+			"elapsed", time.Since(r.startedAt).String(),
+		)
+	}
+}
@@ -0,0 +1,206 @@
+package echokit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+	"github.com/adipurnama/go-toolkit/log"
+)
+
+// syncBuffer is bytes.Buffer safe for concurrent log writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// runServer runs e in background, returns its base URL & channel closed when RunServerWithContext returns.
+func runServer(t *testing.T, appCtx context.Context, e *echo.Echo, cfg *echokit.RuntimeConfig) (string, <-chan struct{}) {
+	t.Helper()
+
+	cfg.Port = freePort(t)
+	cfg.HealthCheckFunc = func(ctx context.Context) error { return nil }
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		echokit.RunServerWithContext(appCtx, e, cfg)
+	}()
+
+	baseURL := fmt.Sprintf("http://127.0.0.1:%d", cfg.Port)
+
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(baseURL + "/actuator/health")
+		if err != nil {
+			return false
+		}
+
+		resp.Body.Close()
+
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return baseURL, done
+}
+
+func healthInFlight(baseURL string) (int, int) {
+	resp, err := http.Get(baseURL + "/actuator/health")
+	if err != nil {
+		return 0, 0
+	}
+
+	defer resp.Body.Close()
+
+	var hs struct {
+		Details struct {
+			InFlightRequests int `json:"in_flight_requests"`
+		} `json:"details"`
+	}
+
+	_ = json.NewDecoder(resp.Body).Decode(&hs)
+
+	return resp.StatusCode, hs.Details.InFlightRequests
+}
+
+func TestGracefulShutdownDrain(t *testing.T) {
+	appCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	e := echo.New()
+	e.GET("/stream", func(ctx echo.Context) error {
+		<-echokit.ShutdownNotify(ctx.Request().Context())
+
+		return ctx.String(http.StatusOK, "bye")
+	})
+
+	baseURL, done := runServer(t, appCtx, e, &echokit.RuntimeConfig{
+		Name:                    "drain test",
+		ShutdownWaitDuration:    10 * time.Second,
+		ShutdownTimeoutDuration: time.Second,
+	})
+
+	streamed := make(chan string, 1)
+
+	go func() {
+		resp, err := http.Get(baseURL + "/stream")
+		if err != nil {
+			streamed <- err.Error()
+			return
+		}
+
+		defer resp.Body.Close()
+
+		b, _ := io.ReadAll(resp.Body)
+		streamed <- string(b)
+	}()
+
+	require.Eventually(t, func() bool {
+		code, inFlight := healthInFlight(baseURL)
+
+		return code == http.StatusOK && inFlight == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	start := time.Now()
+
+	cancel()
+
+	assert.Equal(t, "bye", <-streamed)
+
+	select {
+	case <-done:
+		// wait ended early once there's no in-flight request
+		assert.Less(t, time.Since(start), 5*time.Second)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server is not stopped after in-flight requests are drained")
+	}
+}
+
+func TestGracefulShutdownDropped(t *testing.T) {
+	var buf syncBuffer
+
+	logger := &log.Logger{Level: log.LevelDebug, StdLog: zerolog.New(&buf), ErrLog: zerolog.New(&buf)}
+
+	appCtx, cancel := context.WithCancel(log.AddToContext(context.Background(), logger))
+	defer cancel()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	e := echo.New()
+	e.GET("/stuck", func(ctx echo.Context) error {
+		// ignores shutdown notification
+		<-release
+
+		return ctx.NoContent(http.StatusOK)
+	})
+
+	baseURL, done := runServer(t, appCtx, e, &echokit.RuntimeConfig{
+		Name:                    "dropped test",
+		ShutdownWaitDuration:    50 * time.Millisecond,
+		ShutdownTimeoutDuration: 50 * time.Millisecond,
+	})
+
+	go func() {
+		resp, err := http.Get(baseURL + "/stuck?id=1")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	require.Eventually(t, func() bool {
+		_, inFlight := healthInFlight(baseURL)
+
+		return inFlight == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server is not stopped after shutdown timeout")
+	}
+
+	assert.Contains(t, buf.String(), "dropping in-flight request on shutdown")
+	assert.Contains(t, buf.String(), "/stuck?id=1")
+}
@@ -0,0 +1,246 @@
+package echokit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+const (
+	// MIMETextEventStream is Server-Sent Events content type.
+	MIMETextEventStream = "text/event-stream"
+
+	// HeaderLastEventID is request header containing the last event ID received by reconnecting SSE client.
+	HeaderLastEventID = "Last-Event-ID"
+
+	defaultSSEHeartbeatInterval = 15 * time.Second
+)
+
+// ErrSSEClosed is returned by SSESendFunc when the client is gone or the server is shutting down.
+var ErrSSEClosed = errors.New("echokit: SSE stream is closed")
+
+// ErrInvalidSSEEvent is returned by SSESendFunc when event name or ID contains line break.
+var ErrInvalidSSEEvent = errors.New("echokit: invalid SSE event")
+
+// SSESendFunc writes event with data & flushes it to client, empty event is sent as `message` event.
+// data of string or []byte is sent as is, SSEMessage sets the event ID,
+// others are JSON encoded.
+type SSESendFunc func(event string, data interface{}) error
+
+// SSEMessage is event data with ID, the client sends the last received ID
+// as Last-Event-ID header when it reconnects, see SSELastEventID.
+type SSEMessage struct {
+	ID   string
+	Data interface{}
+}
+
+// SSEConfig Server-Sent Events stream configuration
+// default value:
+//   - HeartbeatInterval: 15 seconds, comment line sent to keep idle connection open through proxies
+//   - Retry: 0, uses client's default reconnection delay
+type SSEConfig struct {
+	HeartbeatInterval time.Duration `json:"heartbeat_interval,omitempty"`
+	Retry             time.Duration `json:"retry,omitempty"`
+}
+
+// SSE streams Server-Sent Events using send until fn returns, e.g.
+//
+//	func (h *OrderHandler) Watch(ctx echo.Context) error {
+//		return echokit.SSE(ctx, func(send echokit.SSESendFunc) error {
+//			for update := range h.svc.Watch(ctx.Request().Context(), echokit.SSELastEventID(ctx)) {
+//				if err := send("order-status", echokit.SSEMessage{ID: update.Version, Data: update}); err != nil {
+//					return err
+//				}
+//			}
+//			return nil
+//		})
+//	}
+//
+// Request context, with its logger, is cancelled when the client is gone
+// or the server is shutting down, see ShutdownNotify.
+// Requests with `Accept: text/event-stream` header are excluded from TimeoutMiddleware & BodyDumpHandler.
+func SSE(ctx echo.Context, fn func(send SSESendFunc) error) error {
+	return SSEWithConfig(ctx, &SSEConfig{}, fn)
+}
+
+// SSEWithConfig is SSE with custom configuration.
+func SSEWithConfig(ctx echo.Context, cfg *SSEConfig, fn func(send SSESendFunc) error) error {
+	heartbeat := cfg.HeartbeatInterval
+	if heartbeat <= 0 {
+		heartbeat = defaultSSEHeartbeatInterval
+	}
+
+	req := ctx.Request()
+
+	streamCtx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+
+	ctx.SetRequest(req.WithContext(streamCtx))
+
+	resp := ctx.Response()
+
+	h := resp.Header()
+	h.Set(echo.HeaderContentType, MIMETextEventStream)
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	// disables nginx response buffering
+	h.Set("X-Accel-Buffering", "no")
+
+	resp.WriteHeader(http.StatusOK)
+
+	w := &sseWriter{resp: resp, ctx: streamCtx}
+
+	if cfg.Retry > 0 {
+		if err := w.write(fmt.Sprintf("retry: %d\n\n", cfg.Retry.Milliseconds())); err != nil {
+			return nil
+		}
+	} else {
+		resp.Flush()
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		w.keepAlive(heartbeat, ShutdownNotify(req.Context()), cancel)
+	}()
+
+	err := fn(w.send)
+
+	// no write after handler returns, echo.Context is reused
+	w.close()
+	cancel()
+	wg.Wait()
+
+	if errors.Is(err, ErrSSEClosed) || (errors.Is(err, context.Canceled) && streamCtx.Err() != nil) {
+		return nil
+	}
+
+	return err
+}
+
+// SSELastEventID returns the last event ID received by reconnecting client,
+// from Last-Event-ID header or `lastEventId` query param, used by EventSource polyfills.
+func SSELastEventID(ctx echo.Context) string {
+	if id := ctx.Request().Header.Get(HeaderLastEventID); id != "" {
+		return id
+	}
+
+	return ctx.QueryParam("lastEventId")
+}
+
+// sseWriter serializes event & heartbeat writes.
+type sseWriter struct {
+	resp *echo.Response
+	ctx  context.Context
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func (w *sseWriter) send(event string, data interface{}) error {
+	if strings.ContainsAny(event, "\r\n") {
+		return fmt.Errorf("%w name %q", ErrInvalidSSEEvent, event)
+	}
+
+	var b strings.Builder
+
+	if msg, ok := data.(SSEMessage); ok {
+		if strings.ContainsAny(msg.ID, "\r\n") {
+			return fmt.Errorf("%w ID %q", ErrInvalidSSEEvent, msg.ID)
+		}
+
+		b.WriteString("id: " + msg.ID + "\n")
+
+		data = msg.Data
+	}
+
+	if event != "" {
+		b.WriteString("event: " + event + "\n")
+	}
+
+	payload, err := sseData(data)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(payload, "\r\n", "\n"), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+
+	b.WriteString("\n")
+
+	return w.write(b.String())
+}
+
+func sseData(data interface{}) (string, error) {
+	switch v := data.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+
+		return string(b), nil
+	}
+}
+
+func (w *sseWriter) write(s string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed || w.ctx.Err() != nil {
+		return ErrSSEClosed
+	}
+
+	if _, err := w.resp.Write([]byte(s)); err != nil {
+		return err
+	}
+
+	w.resp.Flush()
+
+	return nil
+}
+
+func (w *sseWriter) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+}
+
+// keepAlive sends heartbeat comment until stream is done,
+// cancels stream on write failure or server shutdown.
+func (w *sseWriter) keepAlive(interval time.Duration, shutdown <-chan struct{}, cancel context.CancelFunc) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-shutdown:
+			cancel()
+			return
+		case <-ticker.C:
+			if err := w.write(": heartbeat\n\n"); err != nil {
+				cancel()
+				return
+			}
+		}
+	}
+}
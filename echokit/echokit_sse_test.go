@@ -0,0 +1,141 @@
+package echokit_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+)
+
+func sseRequest(t *testing.T, url string, header ...string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	req.Header.Set(echo.HeaderAccept, echokit.MIMETextEventStream)
+
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	return resp
+}
+
+func TestSSE(t *testing.T) {
+	e := echo.New()
+	// streaming request is excluded from request timeout
+	e.Use(echokit.TimeoutMiddleware(&echokit.TimeoutConfig{Timeout: 20 * time.Millisecond, Enforced: true}))
+
+	e.GET("/orders/events", func(ctx echo.Context) error {
+		return echokit.SSEWithConfig(ctx, &echokit.SSEConfig{Retry: 3 * time.Second}, func(send echokit.SSESendFunc) error {
+			time.Sleep(50 * time.Millisecond)
+
+			assert.ErrorIs(t, send("order\nstatus", "x"), echokit.ErrInvalidSSEEvent)
+			assert.ErrorIs(t, send("order-status", echokit.SSEMessage{ID: "v1\r"}), echokit.ErrInvalidSSEEvent)
+
+			if err := send("", "resumed after "+echokit.SSELastEventID(ctx)); err != nil {
+				return err
+			}
+
+			return send("order-status", echokit.SSEMessage{
+				ID:   "v2",
+				Data: map[string]string{"status": "PAID"},
+			})
+		})
+	})
+
+	e.GET("/orders/idle", func(ctx echo.Context) error {
+		return echokit.SSEWithConfig(ctx, &echokit.SSEConfig{HeartbeatInterval: 10 * time.Millisecond}, func(send echokit.SSESendFunc) error {
+			<-ctx.Request().Context().Done()
+
+			return ctx.Request().Context().Err()
+		})
+	})
+
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	t.Run("events", func(t *testing.T) {
+		resp := sseRequest(t, srv.URL+"/orders/events", echokit.HeaderLastEventID, "v1")
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, echokit.MIMETextEventStream, resp.Header.Get(echo.HeaderContentType))
+		assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, "retry: 3000\n\n"+
+			"data: resumed after v1\n\n"+
+			"id: v2\nevent: order-status\ndata: {\"status\":\"PAID\"}\n\n", string(body))
+	})
+
+	t.Run("heartbeat", func(t *testing.T) {
+		resp := sseRequest(t, srv.URL+"/orders/idle")
+		defer resp.Body.Close()
+
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, ": heartbeat\n", line)
+	})
+}
+
+func TestSSEShutdown(t *testing.T) {
+	appCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	e := echo.New()
+	e.GET("/orders/events", func(ctx echo.Context) error {
+		return echokit.SSE(ctx, func(send echokit.SSESendFunc) error {
+			if err := send("ready", "ok"); err != nil {
+				return err
+			}
+
+			<-ctx.Request().Context().Done()
+
+			// stream is closed after shutdown
+			return send("late", "event")
+		})
+	})
+
+	baseURL, done := runServer(t, appCtx, e, &echokit.RuntimeConfig{
+		Name:                    "sse test",
+		ShutdownWaitDuration:    10 * time.Second,
+		ShutdownTimeoutDuration: time.Second,
+	})
+
+	resp := sseRequest(t, baseURL+"/orders/events")
+	defer resp.Body.Close()
+
+	r := bufio.NewReader(resp.Body)
+
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: ready\n", line)
+
+	cancel()
+
+	rest, _ := io.ReadAll(r)
+	assert.False(t, strings.Contains(string(rest), "late"))
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SSE stream is not closed on shutdown")
+	}
+}
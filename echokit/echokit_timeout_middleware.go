@@ -0,0 +1,410 @@
+package echokit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/runtimekit"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+// TimeoutConfig request timeout configuration
+// default value:
+//   - timeout: 7 seconds
+//   - middleware.DefaultSkipper / apply to all url, except SSE & websocket requests
+//   - enforced: false, only sets request context's deadline
+//   - status code: 503, response status when enforced timeout is reached
+//
+// RouteTimeouts overrides timeout for specific route, keyed by
+// `METHOD /route/:path` or `/route/:path` (any method), e.g.
+//
+//	RouteTimeouts: map[string]time.Duration{
+//		"POST /reports/:id": 30 * time.Second,
+//		"/exports":          time.Minute,
+//	}
+type TimeoutConfig struct {
+	Timeout       time.Duration            `json:"timeout,omitempty"`
+	Enforced      bool                     `json:"enforced,omitempty"`
+	StatusCode    int                      `json:"status_code,omitempty"`
+	RouteTimeouts map[string]time.Duration `json:"route_timeouts,omitempty"`
+	Skipper       middleware.Skipper       `json:"-"`
+}
+
+// TimeoutMiddleware sets upstream request context's timeout.
+//
+// When cfg.Enforced is true, client receives RFC 7807 problem response
+// with cfg.StatusCode as soon as the deadline passes, even if the handler ignores
+// its context. The handler keeps running until it returns, its response writes
+// after the deadline are discarded.
+func TimeoutMiddleware(cfg *TimeoutConfig) echo.MiddlewareFunc {
+	// setup default value
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultReqTimeout
+	}
+
+	if cfg.StatusCode == 0 {
+		cfg.StatusCode = http.StatusServiceUnavailable
+	}
+
+	routeTimeouts := normalizeRouteTimeouts(cfg.RouteTimeouts)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			// SSE & websocket are long-lived
+			if skip := cfg.Skipper(ctx) || isStreamingRequest(ctx.Request()); skip {
+				return next(ctx)
+			}
+
+			timeout := cfg.Timeout
+
+			if t, ok := routeValue(routeTimeouts, ctx); ok {
+				timeout = t
+			}
+
+			req := ctx.Request()
+
+			rCtx, cancel := context.WithTimeout(req.Context(), timeout)
+			defer cancel()
+
+			ctx.SetRequest(req.WithContext(rCtx))
+
+			if !cfg.Enforced {
+				return next(ctx)
+			}
+
+			return enforceTimeout(ctx, next, rCtx, timeout, cfg.StatusCode)
+		}
+	}
+}
+
+func normalizeRouteTimeouts(in map[string]time.Duration) map[string]time.Duration {
+	valid := make(map[string]time.Duration, len(in))
+
+	for k, v := range in {
+		if v > 0 {
+			valid[k] = v
+		}
+	}
+
+	return normalizeRouteKeys(valid)
+}
+
+// normalizeRouteKeys normalizes `METHOD /route/:path` or `/route/:path` keys.
+func normalizeRouteKeys[T any](in map[string]T) map[string]T {
+	out := make(map[string]T, len(in))
+
+	for k, v := range in {
+		// config keys might be lowercased, e.g. by viper
+		if method, path, found := strings.Cut(strings.TrimSpace(k), " "); found {
+			k = strings.ToUpper(method) + " " + strings.TrimSpace(path)
+		}
+
+		out[k] = v
+	}
+
+	return out
+}
+
+// routeValue looks up value by `METHOD /route/:path` then `/route/:path` key.
+func routeValue[T any](values map[string]T, ctx echo.Context) (T, bool) {
+	if len(values) == 0 {
+		var zero T
+
+		return zero, false
+	}
+
+	if v, ok := values[ctx.Request().Method+" "+ctx.Path()]; ok {
+		return v, true
+	}
+
+	v, ok := values[ctx.Path()]
+
+	return v, ok
+}
+
+// enforceTimeout runs handler in current goroutine, so echo.Context is never used
+// after it's returned to echo's pool, and writes timeout response from watcher goroutine.
+func enforceTimeout(
+	ctx echo.Context,
+	next echo.HandlerFunc,
+	rCtx context.Context,
+	timeout time.Duration,
+	statusCode int,
+) error {
+	resp := ctx.Response()
+	originalWriter := resp.Writer
+
+	tw := &timeoutWriter{
+		w: originalWriter,
+		h: originalWriter.Header().Clone(),
+	}
+
+	resp.Writer = tw
+
+	defer func() {
+		resp.Writer = originalWriter
+	}()
+
+	abandoned := abandonedHandler{
+		method:    ctx.Request().Method,
+		path:      ctx.Request().URL.Path,
+		route:     ctx.Path(),
+		requestID: headerValue(ctx, web.HTTPKeyRequestID),
+		traceID:   headerValue(ctx, web.HTTPKeyTraceID),
+		echo:      ctx.Echo(),
+		gID:       runtimekit.GoroutineID(),
+		timeout:   timeout,
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-done:
+			return
+		case <-rCtx.Done():
+		}
+
+		if !errors.Is(rCtx.Err(), context.DeadlineExceeded) {
+			// client cancelled request
+			return
+		}
+
+		p := web.NewProblemDetails(statusCode, fmt.Sprintf("request exceeded %s timeout", timeout))
+		p.Instance = abandoned.path
+		p.RequestID = abandoned.requestID
+		p.TraceID = abandoned.traceID
+
+		if tw.timeout(statusCode, p) {
+			abandoned.logTimeout()
+		}
+	}()
+
+	start := time.Now()
+	err := next(ctx)
+
+	if !tw.finish() {
+		return err
+	}
+
+	// timeout response already written
+	resp.Committed = true
+	resp.Status = statusCode
+
+	abandoned.logCompleted(time.Since(start), err)
+
+	return nil
+}
+
+type abandonedHandler struct {
+	method    string
+	path      string
+	route     string
+	requestID string
+	traceID   string
+	echo      *echo.Echo
+	gID       uint64
+	timeout   time.Duration
+}
+
+func (a abandonedHandler) fields() []interface{} {
+	fields := []interface{}{
+		"path", a.path,
+		"route", a.method + " " + a.route,
+		"request_id", a.requestID,
+		"trace_id", a.traceID,
+		"timeout", a.timeout.String(),
+	}
+
+	// route name is registered handler's function name
+	for _, r := range a.echo.Routes() {
+		if r.Method == a.method && r.Path == a.route {
+			fields = append(fields, "handler", r.Name)
+			break
+		}
+	}
+
+	return fields
+}
+
+// logTimeout logs handler goroutine's stack at the time the timeout response is written.
+// it uses new logger, request's logger may be mutated by handler concurrently.
+func (a abandonedHandler) logTimeout() {
+	fields := append(a.fields(), "handler_stack", runtimekit.GoroutineStack(a.gID))
+
+	log.FromCtx(context.Background()).Warn(
+		fmt.Sprintf("%s %s - request timed out, handler abandoned", a.method, a.path),
+		fields...,
+	)
+}
+
+func (a abandonedHandler) logCompleted(elapsed time.Duration, err error) {
+	fields := append(a.fields(), "elapsed_ms", elapsed.Milliseconds())
+
+	if err != nil {
+		fields = append(fields, "error", err)
+	}
+
+	log.FromCtx(context.Background()).Warn(
+		fmt.Sprintf("%s %s - abandoned handler completed after timeout", a.method, a.path),
+		fields...,
+	)
+}
+
+// timeoutWriter guards underlying http.ResponseWriter
+// between handler and timeout watcher goroutine.
+// handler headers are kept in its own map until the header is written.
+type timeoutWriter struct {
+	mu          sync.Mutex
+	w           http.ResponseWriter
+	h           http.Header
+	wroteHeader bool
+	timedOut    bool
+	finished    bool
+}
+
+// Header implements http.ResponseWriter.
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.h
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+
+	tw.writeHeaderLocked(code)
+}
+
+// Write implements http.ResponseWriter.
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+
+	return tw.w.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return
+	}
+
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+
+	if f, ok := tw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker.
+func (tw *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+
+	hj, ok := tw.w.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	// hijacked connection is no longer ours to time out
+	tw.wroteHeader = true
+
+	return hj.Hijack()
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	dst := tw.w.Header()
+
+	for k := range dst {
+		if _, ok := tw.h[k]; !ok {
+			dst.Del(k)
+		}
+	}
+
+	for k, vv := range tw.h {
+		dst[k] = vv
+	}
+
+	tw.wroteHeader = true
+	tw.w.WriteHeader(code)
+}
+
+// timeout writes timeout response if handler hasn't written anything yet.
+func (tw *timeoutWriter) timeout(code int, p *web.ProblemDetails) bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.finished || tw.wroteHeader {
+		return false
+	}
+
+	tw.timedOut = true
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		body = []byte(http.StatusText(code))
+	}
+
+	h := tw.w.Header()
+	h.Set(echo.HeaderContentType, web.MIMEApplicationProblemJSON)
+	h.Set(echo.HeaderContentLength, strconv.Itoa(len(body)))
+
+	tw.w.WriteHeader(code)
+	_, _ = tw.w.Write(body)
+
+	if f, ok := tw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	return true
+}
+
+// finish marks handler as returned, reports whether timeout response was written.
+func (tw *timeoutWriter) finish() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.finished = true
+
+	return tw.timedOut
+}
@@ -0,0 +1,110 @@
+package echokit_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+func TestTimeoutMiddlewareEnforced(t *testing.T) {
+	handlerDone := make(chan struct{}, 2)
+
+	e := echo.New()
+	e.Use(echokit.TimeoutMiddleware(&echokit.TimeoutConfig{
+		Timeout:  50 * time.Millisecond,
+		Enforced: true,
+		RouteTimeouts: map[string]time.Duration{
+			"get /slow-but-allowed": time.Second,
+		},
+	}))
+
+	slowHandler := func(ctx echo.Context) error {
+		// ignores request context on purpose
+		time.Sleep(200 * time.Millisecond)
+
+		defer func() {
+			select {
+			case handlerDone <- struct{}{}:
+			default:
+			}
+		}()
+
+		return ctx.String(http.StatusOK, "too late")
+	}
+
+	e.GET("/slow", slowHandler)
+	e.GET("/slow-but-allowed", slowHandler)
+	e.GET("/fast", func(ctx echo.Context) error {
+		return ctx.String(http.StatusCreated, "ok")
+	})
+
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	t.Run("responds 503 problem when deadline passes", func(t *testing.T) {
+		start := time.Now()
+		resp := doGET(t, srv.URL+"/slow")
+
+		defer resp.Body.Close()
+
+		assert.Less(t, time.Since(start), 150*time.Millisecond)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, web.MIMEApplicationProblemJSON, resp.Header.Get(echo.HeaderContentType))
+
+		var p web.ProblemDetails
+
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&p))
+		assert.Equal(t, http.StatusServiceUnavailable, p.Status)
+		assert.Equal(t, "/slow", p.Instance)
+
+		<-handlerDone
+	})
+
+	t.Run("route timeout override", func(t *testing.T) {
+		resp := doGET(t, srv.URL+"/slow-but-allowed")
+
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "too late", string(body))
+	})
+
+	t.Run("completed handler response is kept", func(t *testing.T) {
+		resp := doGET(t, srv.URL+"/fast")
+
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "ok", string(body))
+	})
+}
+
+func doGET(t *testing.T, url string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resp
+}
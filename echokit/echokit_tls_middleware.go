@@ -0,0 +1,23 @@
+package echokit
+
+import (
+	echo "github.com/labstack/echo/v4"
+
+	"github.com/adipurnama/go-toolkit/tlskit"
+)
+
+// PeerIdentityMiddleware adds verified mTLS client certificate identity into request context,
+// obtain it using tlskit.PeerIdentityFromContext.
+func PeerIdentityMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+
+			if id, ok := tlskit.PeerIdentityFromConnectionState(req.TLS); ok {
+				ctx.SetRequest(req.WithContext(tlskit.NewContext(req.Context(), id)))
+			}
+
+			return next(ctx)
+		}
+	}
+}
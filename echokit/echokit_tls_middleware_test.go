@@ -0,0 +1,53 @@
+package echokit_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+	"github.com/adipurnama/go-toolkit/tlskit"
+)
+
+func TestPeerIdentityMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(echokit.PeerIdentityMiddleware())
+	e.GET("/", func(ctx echo.Context) error {
+		id, ok := tlskit.PeerIdentityFromContext(ctx.Request().Context())
+		if !ok {
+			return ctx.NoContent(http.StatusUnauthorized)
+		}
+
+		return ctx.String(http.StatusOK, id.CommonName)
+	})
+
+	t.Run("verified client certificate", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.TLS = &tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{{
+				Subject:      pkix.Name{CommonName: "payment"},
+				SerialNumber: big.NewInt(7),
+			}}},
+		}
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "payment", rec.Body.String())
+	})
+
+	t.Run("plain connection", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
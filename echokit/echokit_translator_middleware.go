@@ -2,18 +2,18 @@ package echokit
 
 import (
 	"context"
-	"strings"
 
-	"github.com/adipurnama/go-toolkit/web"
-	en_locale "github.com/go-playground/locales/en"
-	id_locale "github.com/go-playground/locales/id"
-	ut "github.com/go-playground/universal-translator"
 	validator "github.com/go-playground/validator/v10"
-	en_translations "github.com/go-playground/validator/v10/translations/en"
-	id_translations "github.com/go-playground/validator/v10/translations/id"
 	echo "github.com/labstack/echo/v4"
+
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/web"
 )
 
+// legacyHeaderAcceptLang is non-standard language header, still supported when Accept-Language is empty.
+const legacyHeaderAcceptLang = "Accept-Lang"
+
 // Validate validates request body for incoming echo.Context request
 // returns web.HTTPError contains field errors (if any).
 func Validate(ctx echo.Context, req interface{}) *web.HTTPError {
@@ -25,30 +25,34 @@ func Validate(ctx echo.Context, req interface{}) *web.HTTPError {
 }
 
 // ValidatorTranslatorMiddleware adds request body validator's translator
-// based on 'Accept-Lang' header.
-// currently only suuports ID & EN locale.
+// based on 'Accept-Language' header, supports ID & EN locale.
+// Use TranslatorMiddleware to support custom rules & additional locales.
 func ValidatorTranslatorMiddleware(v *validator.Validate) echo.MiddlewareFunc {
-	en := en_locale.New()
-	id := id_locale.New()
-	uni := ut.New(en, en, id)
+	return TranslatorMiddleware(web.NewValidator(v))
+}
 
-	transEN, _ := uni.GetTranslator("en")
-	transID, _ := uni.GetTranslator("id")
+// TranslatorMiddleware adds translator negotiated from 'Accept-Language' header
+// (or legacy 'Accept-Lang' header) to request's context, see web.TranslatorFromContext.
+// errors.AppError & Bind error messages are added to all v's locales.
+func TranslatorMiddleware(v *web.Validator) echo.MiddlewareFunc {
+	// errors.AppError messages
+	if err := v.AddTranslations(apperrors.RegisterTranslations); err != nil {
+		log.FromCtx(context.Background()).Error(err, "failed to register errors.AppError translations, messages are not translated")
+	}
 
-	_ = en_translations.RegisterDefaultTranslations(v, transEN)
-	_ = id_translations.RegisterDefaultTranslations(v, transID)
+	// echokit.Bind error messages
+	if err := v.AddTranslations(registerBindTranslations); err != nil {
+		log.FromCtx(context.Background()).Error(err, "failed to register echokit.Bind translations, messages are not translated")
+	}
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(ctx echo.Context) error {
-			trans := transEN
-
-			lang := ctx.Request().Header.Get("Accept-Lang")
-			if strings.ToLower(lang) == "id" ||
-				strings.ToLower(lang) == "id-id" {
-				trans = transID
+			lang := ctx.Request().Header.Get(web.HTTPKeyAcceptLanguage)
+			if lang == "" {
+				lang = ctx.Request().Header.Get(legacyHeaderAcceptLang)
 			}
 
-			rCtx := context.WithValue(ctx.Request().Context(), web.ContextKeyTranslator, trans)
+			rCtx := context.WithValue(ctx.Request().Context(), web.ContextKeyTranslator, v.Negotiate(lang))
 			req := ctx.Request().WithContext(rCtx)
 			ctx.SetRequest(req)
 
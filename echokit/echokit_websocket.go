@@ -0,0 +1,152 @@
+package echokit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	echo "github.com/labstack/echo/v4"
+)
+
+const (
+	defaultWebSocketPingInterval = 30 * time.Second
+
+	webSocketWriteWait  = 5 * time.Second
+	webSocketCloseGrace = time.Second
+)
+
+// WebSocketConfig websocket upgrade configuration
+// default value:
+//   - Upgrader: websocket.Upgrader, only accepts same origin request
+//   - PingInterval: 30 seconds, ping control message to keep idle connection open
+type WebSocketConfig struct {
+	Upgrader     *websocket.Upgrader `json:"-"`
+	PingInterval time.Duration       `json:"ping_interval,omitempty"`
+}
+
+// WebSocket upgrades request into websocket connection & serves it using fn until it returns, e.g.
+//
+//	e.GET("/orders/ws", func(ctx echo.Context) error {
+//		return echokit.WebSocket(ctx, func(rCtx context.Context, conn *websocket.Conn) error {
+//			for update := range svc.Watch(rCtx) {
+//				if err := conn.WriteJSON(update); err != nil {
+//					return err
+//				}
+//			}
+//			return nil
+//		})
+//	})
+//
+// rCtx is request context, with its logger, cancelled when the server is shutting down,
+// the connection is then closed with `1001 going away` status.
+// Websocket upgrade requests are excluded from TimeoutMiddleware & BodyDumpHandler.
+func WebSocket(ctx echo.Context, fn func(rCtx context.Context, conn *websocket.Conn) error) error {
+	return WebSocketWithConfig(ctx, &WebSocketConfig{}, fn)
+}
+
+// WebSocketWithConfig is WebSocket with custom configuration.
+func WebSocketWithConfig(ctx echo.Context, cfg *WebSocketConfig, fn func(rCtx context.Context, conn *websocket.Conn) error) error {
+	upgrader := cfg.Upgrader
+	if upgrader == nil {
+		upgrader = &websocket.Upgrader{}
+	}
+
+	pingInterval := cfg.PingInterval
+	if pingInterval <= 0 {
+		pingInterval = defaultWebSocketPingInterval
+	}
+
+	req := ctx.Request()
+
+	// upgrader writes error response on failure
+	conn, err := upgrader.Upgrade(ctx.Response(), req, nil)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	rCtx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+
+	var (
+		wg   sync.WaitGroup
+		done = make(chan struct{})
+	)
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		keepAliveWebSocket(conn, pingInterval, ShutdownNotify(req.Context()), done, cancel)
+	}()
+
+	err = fn(rCtx, conn)
+
+	close(done)
+	wg.Wait()
+
+	if rCtx.Err() != nil || isWebSocketClosed(err) {
+		return nil
+	}
+
+	return err
+}
+
+// keepAliveWebSocket pings conn until done, closes it when the server is shutting down.
+func keepAliveWebSocket(conn *websocket.Conn, interval time.Duration, shutdown, done <-chan struct{}, cancel context.CancelFunc) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-shutdown:
+			cancel()
+
+			msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server is shutting down")
+			_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(webSocketWriteWait))
+
+			// unblocks fn reading from conn if the client doesn't reply close message
+			_ = conn.SetReadDeadline(time.Now().Add(webSocketCloseGrace))
+
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(webSocketWriteWait)); err != nil {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+func isWebSocketClosed(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	return websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) ||
+		errors.Is(err, websocket.ErrCloseSent)
+}
+
+// isStreamingRequest reports whether req is SSE or websocket upgrade request,
+// which are long-lived & must not be buffered.
+func isStreamingRequest(req *http.Request) bool {
+	if websocket.IsWebSocketUpgrade(req) {
+		return true
+	}
+
+	for _, accept := range req.Header.Values(echo.HeaderAccept) {
+		if strings.Contains(accept, MIMETextEventStream) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,105 @@
+package echokit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+)
+
+func echoWebSocket(ctx echo.Context) error {
+	return echokit.WebSocket(ctx, func(rCtx context.Context, conn *websocket.Conn) error {
+		for {
+			msgType, msg, err := conn.ReadMessage()
+			if err != nil {
+				return err
+			}
+
+			if err := conn.WriteMessage(msgType, msg); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+func TestWebSocket(t *testing.T) {
+	e := echo.New()
+	e.Use(echokit.TimeoutMiddleware(&echokit.TimeoutConfig{Timeout: 20 * time.Millisecond, Enforced: true}))
+	e.GET("/ws", echoWebSocket)
+
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	// longer than request timeout
+	time.Sleep(50 * time.Millisecond)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("hello")))
+
+	_, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(msg))
+
+	t.Run("plain http request", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/ws")
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestWebSocketShutdown(t *testing.T) {
+	appCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	e := echo.New()
+	e.GET("/ws", echoWebSocket)
+
+	baseURL, done := runServer(t, appCtx, e, &echokit.RuntimeConfig{
+		Name:                    "websocket test",
+		ShutdownWaitDuration:    10 * time.Second,
+		ShutdownTimeoutDuration: time.Second,
+	})
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(baseURL, "http")+"/ws", nil)
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("ping")))
+
+	_, _, err = conn.ReadMessage()
+	require.NoError(t, err)
+
+	cancel()
+
+	_, _, err = conn.ReadMessage()
+
+	var errClose *websocket.CloseError
+	require.True(t, errors.As(err, &errClose), err)
+	assert.Equal(t, websocket.CloseGoingAway, errClose.Code)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("websocket connection is not closed on shutdown")
+	}
+}
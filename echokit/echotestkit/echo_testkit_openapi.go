@@ -0,0 +1,44 @@
+package echotestkit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	echo "github.com/labstack/echo/v4"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+)
+
+// AssertRoutesDocumented fails t for every e route missing from OpenAPI spec,
+// routes with path prefixed by one of ignoredPrefixes are not checked, e.g. `/actuator`, `/metrics`.
+func AssertRoutesDocumented(t testing.TB, e *echo.Echo, spec *openapi3.T, ignoredPrefixes ...string) bool {
+	t.Helper()
+
+	ok := true
+
+	for _, r := range e.Routes() {
+		if hasAnyPrefix(r.Path, ignoredPrefixes) || !echokit.IsOpenAPIMethod(r.Method) {
+			continue
+		}
+
+		item := spec.Paths.Find(echokit.OpenAPIRoutePath(r.Path))
+		if item == nil || item.GetOperation(r.Method) == nil {
+			t.Errorf("route %s %s is not documented in OpenAPI spec", r.Method, r.Path)
+
+			ok = false
+		}
+	}
+
+	return ok
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+
+	return false
+}
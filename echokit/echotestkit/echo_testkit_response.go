@@ -0,0 +1,280 @@
+package echotestkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// goldenDir is directory of golden files, relative to the test's package.
+const goldenDir = "testdata"
+
+// goldenIgnored replaces JSON values ignored in golden files.
+const goldenIgnored = "<ignored>"
+
+// updateGolden rewrites golden files with actual responses, run `go test ./... -update-golden`.
+var updateGolden = flag.Bool("update-golden", false, "echotestkit: update golden files")
+
+var invalidGoldenChars = regexp.MustCompile(`[^A-Za-z0-9_\-./]+`)
+
+// Response is recorded Server response with fluent assertions,
+// failed assertion marks the test as failed & continues, see testify's assert.
+type Response struct {
+	Recorder *httptest.ResponseRecorder
+
+	t testing.TB
+}
+
+// Status returns response status code.
+func (r *Response) Status() int {
+	return r.Recorder.Code
+}
+
+// Body returns response body.
+func (r *Response) Body() []byte {
+	return r.Recorder.Body.Bytes()
+}
+
+// DecodeJSON decodes JSON response body into v.
+func (r *Response) DecodeJSON(v interface{}) *Response {
+	r.t.Helper()
+
+	require.NoError(r.t, json.Unmarshal(r.Body(), v), "decoding JSON response body: %s", r.Body())
+
+	return r
+}
+
+// AssertStatus asserts response status code.
+func (r *Response) AssertStatus(code int) *Response {
+	r.t.Helper()
+
+	assert.Equal(r.t, code, r.Status(), "response status, body: %s", r.Body())
+
+	return r
+}
+
+// AssertHeader asserts response header value.
+func (r *Response) AssertHeader(key, value string) *Response {
+	r.t.Helper()
+
+	assert.Equal(r.t, value, r.Recorder.Header().Get(key), "response header %s", key)
+
+	return r
+}
+
+// AssertHeaderPresent asserts response header is set.
+func (r *Response) AssertHeaderPresent(key string) *Response {
+	r.t.Helper()
+
+	assert.NotEmpty(r.t, r.Recorder.Header().Get(key), "response header %s is missing", key)
+
+	return r
+}
+
+// AssertJSONPath asserts JSON response value at path equals want.
+// path is dot separated object keys & array indexes, e.g. `data.items.0.id` or `data.items[0].id`,
+// want is compared by its JSON value, so 1 equals 1.0 and structs equal their JSON objects.
+func (r *Response) AssertJSONPath(path string, want interface{}) *Response {
+	r.t.Helper()
+
+	got, err := jsonPath(r.decodeBody(), path)
+	if !assert.NoError(r.t, err, "response body: %s", r.Body()) {
+		return r
+	}
+
+	assert.Equal(r.t, normalizeJSON(r.t, want), got, "JSON path %s", path)
+
+	return r
+}
+
+// AssertJSONPathExists asserts JSON response has value at path.
+func (r *Response) AssertJSONPathExists(path string) *Response {
+	r.t.Helper()
+
+	_, err := jsonPath(r.decodeBody(), path)
+	assert.NoError(r.t, err, "response body: %s", r.Body())
+
+	return r
+}
+
+// AssertJSON asserts JSON response body equals want, ignoring formatting & object keys order.
+// want is JSON string, []byte or a value encoded into JSON.
+func (r *Response) AssertJSON(want interface{}) *Response {
+	r.t.Helper()
+
+	switch w := want.(type) {
+	case string:
+		assert.JSONEq(r.t, w, string(r.Body()))
+	case []byte:
+		assert.JSONEq(r.t, string(w), string(r.Body()))
+	default:
+		assert.Equal(r.t, normalizeJSON(r.t, want), r.decodeBody())
+	}
+
+	return r
+}
+
+// AssertGolden compares response body with golden file `testdata/<name>.golden`,
+// name defaults to test name. Run tests with `-update-golden` flag to write actual responses.
+// JSON body is stored indented, values at ignoredPaths (see AssertJSONPath)
+// are replaced with `<ignored>`, e.g. generated IDs & timestamps.
+func (r *Response) AssertGolden(name string, ignoredPaths ...string) *Response {
+	r.t.Helper()
+
+	if name == "" {
+		name = r.t.Name()
+	}
+
+	file := filepath.Join(goldenDir, invalidGoldenChars.ReplaceAllString(name, "_")+".golden")
+	actual := r.goldenBody(ignoredPaths)
+
+	if *updateGolden {
+		require.NoError(r.t, os.MkdirAll(filepath.Dir(file), 0o755))
+		require.NoError(r.t, os.WriteFile(file, actual, 0o600))
+
+		return r
+	}
+
+	expected, err := os.ReadFile(file)
+	if !assert.NoError(r.t, err, "reading golden file, run tests with -update-golden flag to create it") {
+		return r
+	}
+
+	assert.Equal(r.t, string(expected), string(actual), "golden file %s", file)
+
+	return r
+}
+
+func (r *Response) decodeBody() interface{} {
+	r.t.Helper()
+
+	var v interface{}
+
+	require.NoError(r.t, json.Unmarshal(r.Body(), &v), "decoding JSON response body: %s", r.Body())
+
+	return v
+}
+
+// goldenBody returns indented JSON body with ignoredPaths replaced, or raw body for non JSON response.
+func (r *Response) goldenBody(ignoredPaths []string) []byte {
+	r.t.Helper()
+
+	var v interface{}
+
+	if err := json.Unmarshal(r.Body(), &v); err != nil {
+		require.Empty(r.t, ignoredPaths, "ignored paths require JSON response body")
+
+		return r.Body()
+	}
+
+	for _, p := range ignoredPaths {
+		require.NoError(r.t, setJSONPath(v, p, goldenIgnored))
+	}
+
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	require.NoError(r.t, enc.Encode(v))
+
+	return buf.Bytes()
+}
+
+// normalizeJSON returns v decoded from its JSON encoding, comparable to decoded response body.
+func normalizeJSON(t testing.TB, v interface{}) interface{} {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	var n interface{}
+
+	require.NoError(t, json.Unmarshal(b, &n))
+
+	return n
+}
+
+func splitJSONPath(path string) []string {
+	path = strings.Trim(strings.NewReplacer("[", ".", "]", "").Replace(path), ".")
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(path, ".")
+}
+
+// jsonPath returns value of decoded JSON v at path.
+func jsonPath(v interface{}, path string) (interface{}, error) {
+	for _, key := range splitJSONPath(path) {
+		next, err := jsonChild(v, key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "JSON path %s", path)
+		}
+
+		v = next
+	}
+
+	return v, nil
+}
+
+// setJSONPath sets value of decoded JSON v at path, path must exist.
+func setJSONPath(v interface{}, path string, value interface{}) error {
+	keys := splitJSONPath(path)
+	if len(keys) == 0 {
+		return errors.Errorf("JSON path %q is empty", path)
+	}
+
+	parent, err := jsonPath(v, strings.Join(keys[:len(keys)-1], "."))
+	if err != nil {
+		return err
+	}
+
+	last := keys[len(keys)-1]
+
+	if _, err := jsonChild(parent, last); err != nil {
+		return errors.Wrapf(err, "JSON path %s", path)
+	}
+
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[last] = value
+	case []interface{}:
+		i, _ := strconv.Atoi(last)
+		p[i] = value
+	}
+
+	return nil
+}
+
+func jsonChild(v interface{}, key string) (interface{}, error) {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		child, ok := node[key]
+		if !ok {
+			return nil, errors.Errorf("key %q not found", key)
+		}
+
+		return child, nil
+	case []interface{}:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(node) {
+			return nil, errors.Errorf("index %q out of range [0, %d)", key, len(node))
+		}
+
+		return node[i], nil
+	default:
+		return nil, errors.Errorf("key %q not found in non object / array value", key)
+	}
+}
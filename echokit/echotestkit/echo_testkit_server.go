@@ -0,0 +1,192 @@
+package echotestkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/echokit"
+)
+
+// Server runs requests through real echo routing, using the same middlewares,
+// actuator routes & error handler installed by echokit.RunServerWithContext.
+// Register app routes & middlewares to Echo, e.g.
+//
+//	srv := echotestkit.NewServer(t, nil)
+//	srv.Echo.GET("/orders/:id", h.GetOrder)
+//
+//	srv.GET("/orders/1").
+//		WithHeader("X-Tenant-ID", "acme").
+//		Do().
+//		AssertStatus(http.StatusOK).
+//		AssertJSONPath("data.id", "1")
+type Server struct {
+	Echo *echo.Echo
+
+	t testing.TB
+}
+
+// NewServer returns Server configured using cfg,
+// cfg defaults to empty echokit.RuntimeConfig with healthy HealthCheckFunc.
+// Metrics are registered to a new prometheus registry unless cfg.Metrics.Registry is set,
+// so servers don't share metrics between tests.
+func NewServer(t testing.TB, cfg *echokit.RuntimeConfig) *Server {
+	t.Helper()
+
+	if cfg == nil {
+		cfg = &echokit.RuntimeConfig{}
+	}
+
+	if cfg.HealthCheckFunc == nil {
+		cfg.HealthCheckFunc = func(ctx context.Context) error { return nil }
+	}
+
+	if cfg.Metrics == nil {
+		cfg.Metrics = &echokit.MetricsConfig{}
+	}
+
+	if cfg.Metrics.Registry == nil {
+		cfg.Metrics.Registry = prometheus.NewRegistry()
+	}
+
+	e := echo.New()
+	require.NoError(t, echokit.ConfigureServer(e, cfg))
+
+	return &Server{Echo: e, t: t}
+}
+
+// GET returns GET request builder for target, e.g. `/orders?status=paid`.
+func (s *Server) GET(target string) *RequestBuilder {
+	return s.NewRequest(http.MethodGet, target)
+}
+
+// POST returns POST request builder for target.
+func (s *Server) POST(target string) *RequestBuilder {
+	return s.NewRequest(http.MethodPost, target)
+}
+
+// PUT returns PUT request builder for target.
+func (s *Server) PUT(target string) *RequestBuilder {
+	return s.NewRequest(http.MethodPut, target)
+}
+
+// PATCH returns PATCH request builder for target.
+func (s *Server) PATCH(target string) *RequestBuilder {
+	return s.NewRequest(http.MethodPatch, target)
+}
+
+// DELETE returns DELETE request builder for target.
+func (s *Server) DELETE(target string) *RequestBuilder {
+	return s.NewRequest(http.MethodDelete, target)
+}
+
+// NewRequest returns request builder of method & target.
+func (s *Server) NewRequest(method, target string) *RequestBuilder {
+	return &RequestBuilder{
+		srv:    s,
+		method: method,
+		target: target,
+		header: make(http.Header),
+		query:  make(url.Values),
+	}
+}
+
+// RequestBuilder builds request to Server.
+type RequestBuilder struct {
+	srv    *Server
+	method string
+	target string
+	header http.Header
+	query  url.Values
+	body   io.Reader
+	ctx    context.Context
+}
+
+// WithHeader sets request header.
+func (b *RequestBuilder) WithHeader(key, value string) *RequestBuilder {
+	b.header.Set(key, value)
+
+	return b
+}
+
+// WithQuery adds query param to request target.
+func (b *RequestBuilder) WithQuery(key, value string) *RequestBuilder {
+	b.query.Add(key, value)
+
+	return b
+}
+
+// WithContext sets request's context, e.g. containing values set by upstream middleware.
+func (b *RequestBuilder) WithContext(ctx context.Context) *RequestBuilder {
+	b.ctx = ctx
+
+	return b
+}
+
+// WithBody sets request body with contentType.
+func (b *RequestBuilder) WithBody(contentType string, body io.Reader) *RequestBuilder {
+	b.header.Set(echo.HeaderContentType, contentType)
+	b.body = body
+
+	return b
+}
+
+// WithJSON sets JSON request body, string & []byte are sent as is, other v is JSON encoded.
+func (b *RequestBuilder) WithJSON(v interface{}) *RequestBuilder {
+	var body []byte
+
+	switch v := v.(type) {
+	case string:
+		body = []byte(v)
+	case []byte:
+		body = v
+	default:
+		var err error
+
+		body, err = json.Marshal(v)
+		require.NoError(b.srv.t, err, "encoding JSON request body")
+	}
+
+	return b.WithBody(echo.MIMEApplicationJSON, bytes.NewReader(body))
+}
+
+// Do sends the request through Server's echo routing & middlewares.
+func (b *RequestBuilder) Do() *Response {
+	b.srv.t.Helper()
+
+	req := httptest.NewRequest(b.method, b.target, b.body)
+
+	if len(b.query) > 0 {
+		q := req.URL.Query()
+
+		for k, vals := range b.query {
+			for _, v := range vals {
+				q.Add(k, v)
+			}
+		}
+
+		req.URL.RawQuery = q.Encode()
+	}
+
+	for k, vals := range b.header {
+		req.Header[k] = vals
+	}
+
+	if b.ctx != nil {
+		req = req.WithContext(b.ctx)
+	}
+
+	rec := httptest.NewRecorder()
+	b.srv.Echo.ServeHTTP(rec, req)
+
+	return &Response{Recorder: rec, t: b.srv.t}
+}
@@ -0,0 +1,106 @@
+package echotestkit_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adipurnama/go-toolkit/echokit/echotestkit"
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+)
+
+type order struct {
+	ID        string    `json:"id"`
+	Items     []string  `json:"items"`
+	Total     float64   `json:"total"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newServer(t *testing.T) *echotestkit.Server {
+	srv := echotestkit.NewServer(t, nil)
+
+	srv.Echo.GET("/orders/:id", func(ctx echo.Context) error {
+		if ctx.Param("id") == "missing" {
+			return apperrors.New(apperrors.CodeNotFound)
+		}
+
+		ctx.Response().Header().Set("X-Order-Version", "3")
+
+		return ctx.JSON(http.StatusOK, map[string]interface{}{
+			"data": order{
+				ID:        ctx.Param("id"),
+				Items:     []string{"book", ctx.QueryParam("extra")},
+				Total:     12,
+				CreatedAt: time.Now(),
+			},
+		})
+	})
+
+	srv.Echo.POST("/orders", func(ctx echo.Context) error {
+		var o order
+		if err := ctx.Bind(&o); err != nil {
+			return err
+		}
+
+		return ctx.JSON(http.StatusCreated, o)
+	})
+
+	return srv
+}
+
+func TestServer(t *testing.T) {
+	srv := newServer(t)
+
+	srv.GET("/orders/42").
+		WithQuery("extra", "pen").
+		Do().
+		AssertStatus(http.StatusOK).
+		AssertHeader("X-Order-Version", "3").
+		AssertHeaderPresent(echo.HeaderContentType).
+		AssertJSONPath("data.id", "42").
+		AssertJSONPath("data.items[1]", "pen").
+		AssertJSONPath("data.total", 12).
+		AssertJSONPathExists("data.created_at")
+
+	srv.POST("/orders").
+		WithJSON(order{ID: "7", Items: []string{"cup"}}).
+		Do().
+		AssertStatus(http.StatusCreated).
+		AssertJSON(`{"id":"7","items":["cup"],"total":0,"created_at":"0001-01-01T00:00:00Z"}`)
+
+	srv.GET("/orders/missing").Do().AssertStatus(http.StatusNotFound)
+	srv.GET("/unknown").Do().AssertStatus(http.StatusNotFound)
+	srv.GET("/actuator/health").Do().AssertStatus(http.StatusOK).AssertJSONPath("status", "UP")
+}
+
+func TestServerGolden(t *testing.T) {
+	newServer(t).GET("/orders/42").
+		WithQuery("extra", "pen").
+		Do().
+		AssertGolden("", "data.created_at")
+}
+
+// recordingT records failed assertions instead of failing the test.
+type recordingT struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.failed = true
+}
+
+func TestResponseAssertionFailure(t *testing.T) {
+	rt := &recordingT{TB: t}
+
+	echotestkit.NewServer(rt, nil).GET("/actuator/health").Do().AssertJSONPath("status", "DOWN")
+	assert.True(t, rt.failed)
+
+	rt.failed = false
+
+	echotestkit.NewServer(rt, nil).GET("/actuator/health").Do().AssertJSONPathExists("details.missing")
+	assert.True(t, rt.failed)
+}
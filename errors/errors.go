@@ -0,0 +1,206 @@
+// Package errors provides typed application error
+// which maps consistently to HTTP & gRPC response
+package errors
+
+import (
+	stdErrors "errors"
+	"fmt"
+	"net/http"
+
+	ut "github.com/go-playground/universal-translator"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AppError is application / domain error with its transport mapping.
+// create it using New or Wrap so its attributes are taken from registered Definition.
+type AppError struct {
+	Code        Code
+	MessageKey  string
+	MessageArgs []string
+	Message     string
+	HTTPStatus  int
+	GRPCCode    codes.Code
+	Retryable   bool
+	Details     map[string]interface{}
+	cause       error
+}
+
+// New returns new *AppError using registered Definition for code.
+// unregistered code is treated as CodeInternal.
+func New(code Code) *AppError {
+	def, ok := Lookup(code)
+	if !ok {
+		def, _ = Lookup(CodeInternal)
+		def.Code = code
+	}
+
+	return &AppError{
+		Code:       def.Code,
+		MessageKey: def.MessageKey,
+		Message:    def.Messages[DefaultLocale],
+		HTTPStatus: def.HTTPStatus,
+		GRPCCode:   def.GRPCCode,
+		Retryable:  def.Retryable,
+	}
+}
+
+// Wrap returns new *AppError for code caused by err.
+func Wrap(err error, code Code) *AppError {
+	e := New(code)
+	e.cause = err
+
+	return e
+}
+
+// FromError returns *AppError found in err chain.
+func FromError(err error) (*AppError, bool) {
+	var appErr *AppError
+	if stdErrors.As(err, &appErr) {
+		return appErr, true
+	}
+
+	return nil, false
+}
+
+// CodeOf returns error Code found in err chain.
+// returns empty Code if err is nil and CodeInternal if err is not *AppError.
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+
+	if appErr, ok := FromError(err); ok {
+		return appErr.Code
+	}
+
+	return CodeInternal
+}
+
+// Error implements error interface.
+func (e *AppError) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.cause)
+	}
+
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Unwrap returns underlying cause.
+func (e *AppError) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is *AppError with the same Code,
+// e.g. errors.Is(err, errors.New(errors.CodeNotFound)).
+func (e *AppError) Is(target error) bool {
+	var t *AppError
+	if !stdErrors.As(target, &t) {
+		return false
+	}
+
+	return t.Code == e.Code
+}
+
+// WithMessage returns copy of e with literal message, it won't be localized.
+func (e *AppError) WithMessage(msg string) *AppError {
+	c := e.clone()
+	c.Message = msg
+	c.MessageKey = ""
+	c.MessageArgs = nil
+
+	return c
+}
+
+// WithMessageKey returns copy of e with translation key & its params, e.g. `{0}` in translation text.
+func (e *AppError) WithMessageKey(key string, args ...string) *AppError {
+	c := e.clone()
+	c.MessageKey = key
+	c.MessageArgs = args
+
+	return c
+}
+
+// WithDetail returns copy of e with additional detail.
+func (e *AppError) WithDetail(key string, value interface{}) *AppError {
+	c := e.clone()
+	c.Details[key] = value
+
+	return c
+}
+
+// WithCause returns copy of e caused by err.
+func (e *AppError) WithCause(err error) *AppError {
+	c := e.clone()
+	c.cause = err
+
+	return c
+}
+
+// LocalizedMessage returns error message translated using trans.
+// it falls back to registered Definition messages, then to e.Message.
+func (e *AppError) LocalizedMessage(trans ut.Translator) string {
+	if trans == nil || e.MessageKey == "" {
+		return e.Message
+	}
+
+	if msg, err := trans.T(e.MessageKey, e.MessageArgs...); err == nil && msg != "" {
+		return msg
+	}
+
+	if def, ok := Lookup(e.Code); ok && def.MessageKey == e.MessageKey {
+		if msg, ok := def.Messages[trans.Locale()]; ok {
+			return msg
+		}
+	}
+
+	return e.Message
+}
+
+// StatusCode returns http status code, defaults to http.StatusInternalServerError.
+func (e *AppError) StatusCode() int {
+	if e.HTTPStatus == 0 {
+		return http.StatusInternalServerError
+	}
+
+	return e.HTTPStatus
+}
+
+// GRPCStatus returns gRPC status with errdetails.ErrorInfo attached,
+// it makes *AppError recognized by grpc status.FromError.
+func (e *AppError) GRPCStatus() *status.Status {
+	return e.LocalizedGRPCStatus(nil)
+}
+
+// LocalizedGRPCStatus returns gRPC status with message translated using trans.
+func (e *AppError) LocalizedGRPCStatus(trans ut.Translator) *status.Status {
+	st := status.New(e.GRPCCode, e.LocalizedMessage(trans))
+
+	info := &errdetails.ErrorInfo{
+		Reason:   string(e.Code),
+		Metadata: map[string]string{"retryable": fmt.Sprint(e.Retryable)},
+	}
+
+	for k, v := range e.Details {
+		info.Metadata[k] = fmt.Sprint(v)
+	}
+
+	stWithDetails, err := st.WithDetails(info)
+	if err != nil {
+		return st
+	}
+
+	return stWithDetails
+}
+
+func (e *AppError) clone() *AppError {
+	c := *e
+
+	c.Details = make(map[string]interface{}, len(e.Details))
+	for k, v := range e.Details {
+		c.Details[k] = v
+	}
+
+	return &c
+}
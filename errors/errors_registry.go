@@ -0,0 +1,225 @@
+package errors
+
+import (
+	stdErrors "errors"
+	"net/http"
+	"sync"
+
+	ut "github.com/go-playground/universal-translator"
+	"google.golang.org/grpc/codes"
+)
+
+// Code is application error code, e.g. NOT_FOUND.
+type Code string
+
+// well-known error codes.
+const (
+	CodeInvalidArgument    Code = "INVALID_ARGUMENT"
+	CodeNotFound           Code = "NOT_FOUND"
+	CodeAlreadyExists      Code = "ALREADY_EXISTS"
+	CodeUnauthenticated    Code = "UNAUTHENTICATED"
+	CodePermissionDenied   Code = "PERMISSION_DENIED"
+	CodeFailedPrecondition Code = "FAILED_PRECONDITION"
+	CodeResourceExhausted  Code = "RESOURCE_EXHAUSTED"
+	CodeCanceled           Code = "CANCELED"
+	CodeDeadlineExceeded   Code = "DEADLINE_EXCEEDED"
+	CodeUnimplemented      Code = "UNIMPLEMENTED"
+	CodeUnavailable        Code = "UNAVAILABLE"
+	CodeInternal           Code = "INTERNAL"
+)
+
+const (
+	// DefaultLocale is locale used for AppError.Message.
+	DefaultLocale = "en"
+
+	// non-standard nginx response for client-cancelled operation.
+	httpStatusCancelled = 499
+)
+
+// Definition describes how Code is presented to http & gRPC client.
+// Messages contains message text for each locale, e.g. "en", "id".
+type Definition struct {
+	Code       Code
+	MessageKey string
+	Messages   map[string]string
+	HTTPStatus int
+	GRPCCode   codes.Code
+	Retryable  bool
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[Code]Definition)
+)
+
+func init() {
+	Register(
+		Definition{
+			Code:       CodeInvalidArgument,
+			MessageKey: "error.invalid_argument",
+			HTTPStatus: http.StatusBadRequest,
+			GRPCCode:   codes.InvalidArgument,
+			Messages: map[string]string{
+				"en": "invalid request argument",
+				"id": "argumen permintaan tidak valid",
+			},
+		},
+		Definition{
+			Code:       CodeNotFound,
+			MessageKey: "error.not_found",
+			HTTPStatus: http.StatusNotFound,
+			GRPCCode:   codes.NotFound,
+			Messages: map[string]string{
+				"en": "resource not found",
+				"id": "data tidak ditemukan",
+			},
+		},
+		Definition{
+			Code:       CodeAlreadyExists,
+			MessageKey: "error.already_exists",
+			HTTPStatus: http.StatusConflict,
+			GRPCCode:   codes.AlreadyExists,
+			Messages: map[string]string{
+				"en": "resource already exists",
+				"id": "data sudah ada",
+			},
+		},
+		Definition{
+			Code:       CodeUnauthenticated,
+			MessageKey: "error.unauthenticated",
+			HTTPStatus: http.StatusUnauthorized,
+			GRPCCode:   codes.Unauthenticated,
+			Messages: map[string]string{
+				"en": "authentication required",
+				"id": "autentikasi diperlukan",
+			},
+		},
+		Definition{
+			Code:       CodePermissionDenied,
+			MessageKey: "error.permission_denied",
+			HTTPStatus: http.StatusForbidden,
+			GRPCCode:   codes.PermissionDenied,
+			Messages: map[string]string{
+				"en": "permission denied",
+				"id": "akses ditolak",
+			},
+		},
+		Definition{
+			Code:       CodeFailedPrecondition,
+			MessageKey: "error.failed_precondition",
+			HTTPStatus: http.StatusPreconditionFailed,
+			GRPCCode:   codes.FailedPrecondition,
+			Messages: map[string]string{
+				"en": "request can't be processed in current state",
+				"id": "permintaan tidak dapat diproses pada kondisi saat ini",
+			},
+		},
+		Definition{
+			Code:       CodeResourceExhausted,
+			MessageKey: "error.resource_exhausted",
+			HTTPStatus: http.StatusTooManyRequests,
+			GRPCCode:   codes.ResourceExhausted,
+			Retryable:  true,
+			Messages: map[string]string{
+				"en": "too many requests",
+				"id": "terlalu banyak permintaan",
+			},
+		},
+		Definition{
+			Code:       CodeCanceled,
+			MessageKey: "error.canceled",
+			HTTPStatus: httpStatusCancelled,
+			GRPCCode:   codes.Canceled,
+			Messages: map[string]string{
+				"en": "request canceled",
+				"id": "permintaan dibatalkan",
+			},
+		},
+		Definition{
+			Code:       CodeDeadlineExceeded,
+			MessageKey: "error.deadline_exceeded",
+			HTTPStatus: http.StatusGatewayTimeout,
+			GRPCCode:   codes.DeadlineExceeded,
+			Retryable:  true,
+			Messages: map[string]string{
+				"en": "request timeout",
+				"id": "batas waktu permintaan habis",
+			},
+		},
+		Definition{
+			Code:       CodeUnimplemented,
+			MessageKey: "error.unimplemented",
+			HTTPStatus: http.StatusNotImplemented,
+			GRPCCode:   codes.Unimplemented,
+			Messages: map[string]string{
+				"en": "not implemented",
+				"id": "belum didukung",
+			},
+		},
+		Definition{
+			Code:       CodeUnavailable,
+			MessageKey: "error.unavailable",
+			HTTPStatus: http.StatusServiceUnavailable,
+			GRPCCode:   codes.Unavailable,
+			Retryable:  true,
+			Messages: map[string]string{
+				"en": "service unavailable",
+				"id": "layanan tidak tersedia",
+			},
+		},
+		Definition{
+			Code:       CodeInternal,
+			MessageKey: "error.internal",
+			HTTPStatus: http.StatusInternalServerError,
+			GRPCCode:   codes.Internal,
+			Messages: map[string]string{
+				"en": "internal server error",
+				"id": "terjadi kesalahan pada server",
+			},
+		},
+	)
+}
+
+// Register adds / replaces error code definitions.
+// call it during app initialization, e.g. in `init()` of your domain package.
+func Register(defs ...Definition) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, d := range defs {
+		registry[d.Code] = d
+	}
+}
+
+// Lookup returns registered Definition for code.
+func Lookup(code Code) (Definition, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	d, ok := registry[code]
+
+	return d, ok
+}
+
+// RegisterTranslations adds registered definition messages for trans locale to trans.
+// existing translation with the same key is kept.
+func RegisterTranslations(trans ut.Translator) error {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, d := range registry {
+		msg, ok := d.Messages[trans.Locale()]
+		if !ok || d.MessageKey == "" {
+			continue
+		}
+
+		err := trans.Add(d.MessageKey, msg, false)
+
+		var errConflict *ut.ErrConflictingTranslation
+		if err != nil && !stdErrors.As(err, &errConflict) {
+			return err
+		}
+	}
+
+	return nil
+}
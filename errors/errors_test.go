@@ -0,0 +1,92 @@
+package errors_test
+
+import (
+	stdErrors "errors"
+	"net/http"
+	"testing"
+
+	en_locale "github.com/go-playground/locales/en"
+	id_locale "github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	pkgErrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+)
+
+var errDB = stdErrors.New("db: connection refused")
+
+func TestNew(t *testing.T) {
+	err := apperrors.New(apperrors.CodeNotFound)
+
+	assert.Equal(t, http.StatusNotFound, err.StatusCode())
+	assert.Equal(t, codes.NotFound, err.GRPCCode)
+	assert.Equal(t, "resource not found", err.Message)
+	assert.False(t, err.Retryable)
+
+	unknown := apperrors.New(apperrors.Code("SOMETHING_ELSE"))
+	assert.Equal(t, apperrors.Code("SOMETHING_ELSE"), unknown.Code)
+	assert.Equal(t, http.StatusInternalServerError, unknown.StatusCode())
+}
+
+func TestWrapAndIs(t *testing.T) {
+	err := pkgErrors.Wrap(apperrors.Wrap(errDB, apperrors.CodeUnavailable), "repository.FindUser")
+
+	assert.ErrorIs(t, err, errDB)
+	assert.ErrorIs(t, err, apperrors.New(apperrors.CodeUnavailable))
+	assert.NotErrorIs(t, err, apperrors.New(apperrors.CodeNotFound))
+	assert.Equal(t, apperrors.CodeUnavailable, apperrors.CodeOf(err))
+	assert.Equal(t, apperrors.CodeInternal, apperrors.CodeOf(errDB))
+
+	appErr, ok := apperrors.FromError(err)
+	assert.True(t, ok)
+	assert.True(t, appErr.Retryable)
+}
+
+func TestWithDetailDoesNotMutate(t *testing.T) {
+	base := apperrors.New(apperrors.CodeInvalidArgument)
+	withDetail := base.WithDetail("field", "email")
+
+	assert.Empty(t, base.Details)
+	assert.Equal(t, "email", withDetail.Details["field"])
+}
+
+func TestLocalizedMessage(t *testing.T) {
+	en := en_locale.New()
+	id := id_locale.New()
+	uni := ut.New(en, en, id)
+
+	transEN, _ := uni.GetTranslator("en")
+	transID, _ := uni.GetTranslator("id")
+
+	assert.NoError(t, apperrors.RegisterTranslations(transID))
+	assert.NoError(t, transEN.Add("user.not_found", "user {0} not found", false))
+
+	err := apperrors.New(apperrors.CodeNotFound)
+
+	assert.Equal(t, "data tidak ditemukan", err.LocalizedMessage(transID))
+	assert.Equal(t, "resource not found", err.LocalizedMessage(nil))
+	assert.Equal(t, "user 42 not found", err.WithMessageKey("user.not_found", "42").LocalizedMessage(transEN))
+	assert.Equal(t, "custom", err.WithMessage("custom").LocalizedMessage(transID))
+}
+
+func TestGRPCStatus(t *testing.T) {
+	err := apperrors.New(apperrors.CodeResourceExhausted).WithDetail("limit", 10)
+
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	assert.Equal(t, "too many requests", st.Message())
+
+	details := st.Details()
+	assert.Len(t, details, 1)
+
+	info, ok := details[0].(*errdetails.ErrorInfo)
+	assert.True(t, ok)
+	assert.Equal(t, "RESOURCE_EXHAUSTED", info.Reason)
+	assert.Equal(t, "true", info.Metadata["retryable"])
+	assert.Equal(t, "10", info.Metadata["limit"])
+}
@@ -94,6 +94,8 @@ func main() {
 		grpc.StreamInterceptor(
 			grpc_middleware.ChainStreamServer(
 				grpcapmkit.NewStreamServerInterceptor(grpcServerOpts...),
+				grpckit.RequestIDStreamInterceptor(grpckit.DefaultRequestIDProvider()),
+				grpckit.LoggerStreamInterceptor(),
 			),
 		),
 	)
@@ -6,7 +6,6 @@ import (
 	"strconv"
 
 	echo "github.com/labstack/echo/v4"
-	"github.com/pkg/errors"
 
 	"github.com/adipurnama/go-toolkit/echokit"
 	user "github.com/adipurnama/go-toolkit/examples/echo-restapi/internal"
@@ -23,14 +22,8 @@ func CreateUser(svc *service.Service) echo.HandlerFunc {
 
 		c.SetRequest(c.Request().WithContext(ctx))
 
-		var req dto.CreateUserRequest
-
-		err := c.Bind(&req)
+		req, err := echokit.Bind[dto.CreateUserRequest](c)
 		if err != nil {
-			return errors.Wrap(err, "parse request body failed")
-		}
-
-		if err := echokit.Validate(c, req); err != nil {
 			return err
 		}
 
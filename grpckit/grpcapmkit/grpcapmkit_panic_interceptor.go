@@ -1,24 +1,19 @@
 package grpcapmkit
 
 import (
-	"fmt"
-	"runtime/debug"
-
 	"github.com/pinpoint-apm/pinpoint-go-agent"
-	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
-	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/grpckit"
+	"github.com/adipurnama/go-toolkit/runtimekit"
 
 	"go.elastic.co/apm"
 	"go.elastic.co/apm/module/apmgrpc"
 )
 
-var errPanicInternal = errors.New("found panic while serving request")
-
 // NewUnaryServerInterceptor returns a grpc.UnaryServerInterceptor that
 // traces gRPC requests with the given options.
 //
@@ -26,9 +21,8 @@ var errPanicInternal = errors.New("found panic while serving request")
 // each incoming request. The transaction will be added to the context,
 // so server methods can use apm.StartSpan with the provided context.
 //
-// By default, the interceptor will trace with apm.DefaultTracer,
-// and will not recover any panics. Use WithTracer to specify an
-// alternative tracer, and WithRecovery to enable panic recovery.
+// By default, the interceptor will trace with apm.DefaultTracer.
+// Panics are recovered using grpckit.RecoverInterceptor and reported to APM.
 func NewUnaryServerInterceptor(o ...ServerOption) grpc.UnaryServerInterceptor {
 	opts := serverOptions{
 		elasticTracer:  apm.DefaultTracer,
@@ -63,41 +57,12 @@ func NewUnaryServerInterceptor(o ...ServerOption) grpc.UnaryServerInterceptor {
 		}
 
 		defer func() {
-			r := recover()
-			if r != nil {
-				errStack := debug.Stack()
-
-				if tx != nil {
-					e := opts.elasticTracer.Recovered(r)
-					e.SetTransaction(tx)
-					e.Context.SetFramework("grpc", grpc.Version)
-					e.Handled = true
-
-					e.Send()
-				}
-
-				logErr, ok := r.(error)
-				if !ok {
-					err = errors.Wrap(errPanicInternal, fmt.Sprint(r))
-				} else if pt != nil {
-					pt.Span().SetError(logErr)
-				}
-
-				log.FromCtx(ctx).Error(
-					logErr,
-					"recovered from panic",
-					"panic_stack", errStack,
-				)
-
-				err = status.Errorf(codes.Internal, "%s", r)
-			}
-
 			setTransactionResult(tx, err)
 		}()
 
-		resp, err = handler(ctx, req)
+		recoverInterceptor := grpckit.RecoverInterceptor(panicReporter(opts.elasticTracer, tx, pt))
 
-		return resp, err
+		return recoverInterceptor(ctx, req, info, handler)
 	}
 }
 
@@ -108,9 +73,8 @@ func NewUnaryServerInterceptor(o ...ServerOption) grpc.UnaryServerInterceptor {
 // incoming stream request. The transaction will be added to the context, so
 // server methods can use apm.StartSpan with the provided context.
 //
-// By default, the interceptor will trace with apm.DefaultTracer, and will
-// not recover any panics. Use WithTracer to specify an alternative tracer,
-// and WithRecovery to enable panic recovery.
+// By default, the interceptor will trace with apm.DefaultTracer.
+// Panics are recovered using grpckit.RecoverStreamInterceptor and reported to APM.
 func NewStreamServerInterceptor(o ...ServerOption) grpc.StreamServerInterceptor {
 	opts := serverOptions{
 		elasticTracer: apm.DefaultTracer,
@@ -141,51 +105,42 @@ func NewStreamServerInterceptor(o ...ServerOption) grpc.StreamServerInterceptor
 		)
 
 		if opts.pinpointAgent != nil && opts.pinpointAgent.Enable() {
-			tracer := startPinpointSpan(stream.Context(), opts.pinpointAgent, info.FullMethod)
-			defer tracer.EndSpan()
-			defer tracer.NewSpanEvent(info.FullMethod).EndSpanEvent()
+			pt = startPinpointSpan(stream.Context(), opts.pinpointAgent, info.FullMethod)
+			defer pt.EndSpan()
+			defer pt.NewSpanEvent(info.FullMethod).EndSpanEvent()
 
-			ctx = pinpoint.NewContext(stream.Context(), tracer)
+			ctx = pinpoint.NewContext(stream.Context(), pt)
 			pinpointWrappedStream = &pinpointServerStream{stream, ctx}
 		}
 
 		defer func() {
-			r := recover()
-			if r != nil {
-				errStack := debug.Stack()
-
-				if tx != nil {
-					e := opts.elasticTracer.Recovered(r)
-					e.SetTransaction(tx)
-					e.Context.SetFramework("grpc", grpc.Version)
-					e.Handled = true
-					e.Send()
-				}
-
-				logErr, ok := r.(error)
-				if !ok {
-					err = errors.Wrap(errPanicInternal, fmt.Sprint(r))
-				} else if pt != nil {
-					pt.Span().SetError(logErr)
-				}
-
-				log.FromCtx(ctx).Error(
-					logErr,
-					"recovered from panic",
-					"panic_stack", errStack,
-				)
-
-				err = status.Errorf(codes.Internal, "%s", r)
-			}
-
 			setTransactionResult(tx, err)
 		}()
 
+		recoverInterceptor := grpckit.RecoverStreamInterceptor(panicReporter(opts.elasticTracer, tx, pt))
+
 		if pinpointWrappedStream != nil {
-			return handler(srv, pinpointWrappedStream)
+			return recoverInterceptor(srv, pinpointWrappedStream, info, handler)
 		}
 
-		return handler(srv, stream)
+		return recoverInterceptor(srv, stream, info, handler)
+	}
+}
+
+// panicReporter reports recovered panic to elastic transaction tx & pinpoint tracer pt, if any.
+func panicReporter(tracer *apm.Tracer, tx *apm.Transaction, pt pinpoint.Tracer) runtimekit.PanicReporter {
+	return func(_ context.Context, err *runtimekit.PanicError) {
+		if tx != nil {
+			e := tracer.Recovered(err.Value)
+			e.SetTransaction(tx)
+			e.Context.SetFramework("grpc", grpc.Version)
+			e.Handled = true
+			e.Send()
+		}
+
+		if pt != nil {
+			pt.Span().SetError(err)
+		}
 	}
 }
 
@@ -0,0 +1,203 @@
+package grpckit
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/adipurnama/go-toolkit/config"
+)
+
+const (
+	defaultClientTimeout    = 10 * time.Second
+	defaultMaxRetries       = 2
+	defaultRetryBackoff     = 100 * time.Millisecond
+	defaultRetryMaxBackoff  = 2 * time.Second
+	methodTimeoutSeparator  = "="
+	methodWildcardSeparator = "/*"
+)
+
+// ErrInvalidMethodTimeout is returned by NewClientConfig when method-timeouts entry isn't `method=duration`.
+var ErrInvalidMethodTimeout = errors.New("grpckit: invalid method timeout, expected `/package.Service/Method=duration`")
+
+// defaultIdempotentMethodPrefixes are AIP standard read methods, retried when IdempotentMethods is empty.
+var defaultIdempotentMethodPrefixes = []string{"Get", "List", "BatchGet", "Search"}
+
+// ClientConfig defines outgoing gRPC connection configuration used by Dial
+// default value:
+//   - Timeout: 10s, deadline of unary calls without caller's deadline. Streams only use MethodTimeouts
+//   - MethodTimeouts: per method deadline, keyed by `/package.Service/Method` or `/package.Service/*`,
+//     caller's deadline is kept when it's shorter
+//   - MaxRetries: 2, retries of idempotent unary calls failed with codes.Unavailable, negative disables retry
+//   - RetryBackoff: 100ms, doubled on each retry up to RetryMaxBackoff 2s, with jitter
+//   - IdempotentMethods: methods which are safe to retry, same format as MethodTimeouts keys.
+//     Empty means AIP standard read methods: Get*, List*, BatchGet* & Search*
+//   - Insecure: false, connection uses TLS with system root CAs
+type ClientConfig struct {
+	Target            string                   `json:"target,omitempty"`
+	Timeout           time.Duration            `json:"timeout,omitempty"`
+	MethodTimeouts    map[string]time.Duration `json:"method_timeouts,omitempty"`
+	MaxRetries        int                      `json:"max_retries,omitempty"`
+	RetryBackoff      time.Duration            `json:"retry_backoff,omitempty"`
+	RetryMaxBackoff   time.Duration            `json:"retry_max_backoff,omitempty"`
+	IdempotentMethods []string                 `json:"idempotent_methods,omitempty"`
+	Insecure          bool                     `json:"insecure,omitempty"`
+}
+
+/*
+NewClientConfig returns *ClientConfig based on viper configuration
+with layout:
+
+	given config file contents:
+
+		order-service:
+		  target: dns:///order-service:8288
+		  insecure: true
+		  timeout: 5s
+		  max-retries: 3
+		  retry-backoff: 50ms
+		  retry-max-backoff: 1s
+		  idempotent-methods:
+		    - /order.v1.OrderService/GetOrder
+		    - /order.v1.QueryService/*
+		  method-timeouts:
+		    - /order.v1.OrderService/CreateOrder=15s
+
+	call using `grpckit.NewClientConfig(v, "order-service")`.
+*/
+func NewClientConfig(cfg config.KVStore, path string) (*ClientConfig, error) {
+	c := ClientConfig{}
+
+	c.Target = cfg.GetString(fmt.Sprintf("%s.target", path))
+	c.Insecure = cfg.GetBool(fmt.Sprintf("%s.insecure", path))
+	c.Timeout = cfg.GetDuration(fmt.Sprintf("%s.timeout", path))
+	c.RetryBackoff = cfg.GetDuration(fmt.Sprintf("%s.retry-backoff", path))
+	c.RetryMaxBackoff = cfg.GetDuration(fmt.Sprintf("%s.retry-max-backoff", path))
+	c.IdempotentMethods = cfg.GetStringSlice(fmt.Sprintf("%s.idempotent-methods", path))
+
+	if key := fmt.Sprintf("%s.max-retries", path); cfg.IsSet(key) {
+		c.MaxRetries = cfg.GetInt(key)
+		if c.MaxRetries == 0 {
+			// explicit zero disables retry
+			c.MaxRetries = -1
+		}
+	}
+
+	// method names contain dots, which are viper key delimiters
+	for _, v := range cfg.GetStringSlice(fmt.Sprintf("%s.method-timeouts", path)) {
+		method, d, ok := strings.Cut(v, methodTimeoutSeparator)
+		if !ok {
+			return nil, errors.Wrap(ErrInvalidMethodTimeout, v)
+		}
+
+		timeout, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidMethodTimeout, "%s: %s", v, err)
+		}
+
+		if c.MethodTimeouts == nil {
+			c.MethodTimeouts = make(map[string]time.Duration)
+		}
+
+		c.MethodTimeouts[strings.TrimSpace(method)] = timeout
+	}
+
+	return &c, nil
+}
+
+func (c *ClientConfig) validate() {
+	if c.Timeout == 0 {
+		c.Timeout = defaultClientTimeout
+	}
+
+	if c.MaxRetries == 0 {
+		c.MaxRetries = defaultMaxRetries
+	}
+
+	if c.RetryBackoff == 0 {
+		c.RetryBackoff = defaultRetryBackoff
+	}
+
+	if c.RetryMaxBackoff == 0 {
+		c.RetryMaxBackoff = defaultRetryMaxBackoff
+	}
+}
+
+// methodTimeout returns deadline of fullMethod call, or false when it has no deadline.
+// Timeout is only used when useDefault is set, i.e. unary call without caller's deadline.
+func (c *ClientConfig) methodTimeout(fullMethod string, useDefault bool) (time.Duration, bool) {
+	if t, ok := c.MethodTimeouts[fullMethod]; ok {
+		return t, true
+	}
+
+	if t, ok := c.MethodTimeouts[serviceWildcard(fullMethod)]; ok {
+		return t, true
+	}
+
+	if !useDefault {
+		return 0, false
+	}
+
+	return c.Timeout, true
+}
+
+// isIdempotent reports whether fullMethod is safe to retry.
+func (c *ClientConfig) isIdempotent(fullMethod string) bool {
+	if len(c.IdempotentMethods) == 0 {
+		method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+
+		for _, p := range defaultIdempotentMethodPrefixes {
+			if strings.HasPrefix(method, p) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, m := range c.IdempotentMethods {
+		if m == fullMethod || m == serviceWildcard(fullMethod) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// serviceWildcard returns `/package.Service/*` of fullMethod.
+func serviceWildcard(fullMethod string) string {
+	return fullMethod[:strings.LastIndex(fullMethod, "/")] + methodWildcardSeparator
+}
+
+// Dial creates client connection to cfg.Target with toolkit client interceptors:
+// request & trace ID propagation, call logging, per method deadline and retry of idempotent methods.
+// opts are applied after the defaults, e.g. to set transport credentials
+// or add `tenantkit.UnaryClientInterceptor`, which runs on each retry attempt.
+func Dial(ctx context.Context, cfg *ClientConfig, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	cfg.validate()
+
+	creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	if cfg.Insecure {
+		creds = insecure.NewCredentials()
+	}
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(UnaryClientInterceptor(cfg)),
+		grpc.WithChainStreamInterceptor(StreamClientInterceptor(cfg)),
+	}
+
+	conn, err := grpc.DialContext(ctx, cfg.Target, append(dialOpts, opts...)...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "grpckit: failed to dial %s", cfg.Target)
+	}
+
+	return conn, nil
+}
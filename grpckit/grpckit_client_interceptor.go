@@ -0,0 +1,271 @@
+package grpckit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+// UnaryClientInterceptor propagates request & trace ID to outgoing call metadata, applies cfg deadline,
+// retries idempotent method failed with codes.Unavailable using exponential backoff & logs the call.
+// Usually used through Dial.
+func UnaryClientInterceptor(cfg *ClientConfig) grpc.UnaryClientInterceptor {
+	cfg.validate()
+
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		start := time.Now()
+		ctx = outgoingContext(ctx)
+
+		_, hasDeadline := ctx.Deadline()
+
+		if t, ok := cfg.methodTimeout(method, !hasDeadline); ok {
+			var cancel context.CancelFunc
+
+			ctx, cancel = context.WithTimeout(ctx, t)
+			defer cancel()
+		}
+
+		maxAttempts := 1
+		if cfg.MaxRetries > 0 && cfg.isIdempotent(method) {
+			maxAttempts += cfg.MaxRetries
+		}
+
+		var (
+			err     error
+			attempt int
+		)
+
+		for attempt = 1; ; attempt++ {
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			if status.Code(err) != codes.Unavailable || attempt == maxAttempts {
+				break
+			}
+
+			if !sleepCtx(ctx, retryBackoff(cfg, attempt)) {
+				break
+			}
+		}
+
+		logClientCall(ctx, method, cc.Target(), start, err,
+			"grpc.attempts", attempt,
+		)
+
+		return err
+	}
+}
+
+// StreamClientInterceptor propagates request & trace ID to outgoing stream metadata,
+// applies cfg.MethodTimeouts deadline & logs the stream when it's finished.
+// Streams aren't retried. Usually used through Dial.
+func StreamClientInterceptor(cfg *ClientConfig) grpc.StreamClientInterceptor {
+	cfg.validate()
+
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		start := time.Now()
+		ctx = outgoingContext(ctx)
+		cancel := context.CancelFunc(func() {})
+
+		if t, ok := cfg.methodTimeout(method, false); ok {
+			ctx, cancel = context.WithTimeout(ctx, t)
+		}
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			cancel()
+			logClientCall(ctx, method, cc.Target(), start, err)
+
+			return nil, err
+		}
+
+		return &clientStream{
+			ClientStream:  cs,
+			serverStreams: desc.ServerStreams,
+			finish: func(s *clientStream, err error) {
+				cancel()
+				logClientCall(ctx, method, cc.Target(), start, err,
+					"grpc.stream.msg_sent", atomic.LoadInt64(&s.sent),
+					"grpc.stream.msg_received", atomic.LoadInt64(&s.received),
+				)
+			},
+		}, nil
+	}
+}
+
+// outgoingContext adds context's request ID & W3C trace context to outgoing metadata,
+// unless they're already set.
+func outgoingContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+
+	var kv []string
+
+	if len(md.Get(keyRequestID)) == 0 {
+		if rID := requestIDFromContext(ctx); rID != "" {
+			kv = append(kv, keyRequestID, rID)
+		}
+	}
+
+	if tc, ok := web.TraceContextFromContext(ctx); ok && len(md.Get(web.HTTPKeyTraceParent)) == 0 {
+		kv = append(kv, web.HTTPKeyTraceParent, tc.TraceParent())
+
+		if tc.TraceState != "" {
+			kv = append(kv, web.HTTPKeyTraceState, tc.TraceState)
+		}
+	}
+
+	if len(kv) == 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+// requestIDFromContext returns request ID set by echokit middleware or incoming gRPC request metadata.
+func requestIDFromContext(ctx context.Context) string {
+	if rID, ok := ctx.Value(web.ContextKeyRequestID).(string); ok && rID != "" {
+		return rID
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if rIDs := md.Get(keyRequestID); len(rIDs) > 0 {
+			return rIDs[0]
+		}
+	}
+
+	return ""
+}
+
+// retryBackoff returns wait duration before retry after attempt, exponential with equal jitter.
+func retryBackoff(cfg *ClientConfig, attempt int) time.Duration {
+	d := cfg.RetryBackoff << (attempt - 1)
+	if d > cfg.RetryMaxBackoff || d <= 0 {
+		d = cfg.RetryMaxBackoff
+	}
+
+	//nolint:gosec // jitter doesn't need secure random
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// sleepCtx waits for d, returns false when ctx is done first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+func logClientCall(ctx context.Context, fullMethod, target string, start time.Time, err error, extra ...interface{}) {
+	code := status.Code(err)
+	fields := append([]interface{}{
+		"grpc.code", code.String(),
+		"grpc.time_ms", time.Since(start).Milliseconds(),
+		"grpc.service", path.Dir(fullMethod)[1:],
+		"grpc.method", path.Base(fullMethod),
+		"grpc.target", target,
+	}, extra...)
+
+	if err != nil {
+		msg := fmt.Sprintf("%s - gRPC client call completed with error", fullMethod)
+
+		if clientRequestErrorCode(code) {
+			fields = append(fields, "error", err)
+			log.FromCtx(ctx).Info(msg, fields...)
+		} else {
+			log.FromCtx(ctx).WarnError(err, msg, fields...)
+		}
+
+		return
+	}
+
+	log.FromCtx(ctx).Debug(fmt.Sprintf("%s - gRPC client call completed", fullMethod), fields...)
+}
+
+// clientStream wraps grpc.ClientStream to count messages & call finish once the stream is done.
+type clientStream struct {
+	grpc.ClientStream
+	serverStreams bool
+	sent          int64
+	received      int64
+	once          sync.Once
+	finish        func(s *clientStream, err error)
+}
+
+// SendMsg implements grpc.ClientStream.
+func (s *clientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		atomic.AddInt64(&s.sent, 1)
+	} else if err != io.EOF {
+		s.done(err)
+	}
+
+	return err
+}
+
+// RecvMsg implements grpc.ClientStream.
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	switch {
+	case err == nil:
+		atomic.AddInt64(&s.received, 1)
+
+		// client streaming call is done after receiving its single response
+		if !s.serverStreams {
+			s.done(nil)
+		}
+	case err == io.EOF:
+		s.done(nil)
+	default:
+		s.done(err)
+	}
+
+	return err
+}
+
+// Header implements grpc.ClientStream.
+func (s *clientStream) Header() (metadata.MD, error) {
+	md, err := s.ClientStream.Header()
+	if err != nil {
+		s.done(err)
+	}
+
+	return md, err
+}
+
+func (s *clientStream) done(err error) {
+	s.once.Do(func() {
+		s.finish(s, err)
+	})
+}
@@ -0,0 +1,372 @@
+package grpckit_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/adipurnama/go-toolkit/grpckit"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+// attempt is a call received by flakyService.
+type attempt struct {
+	at       time.Time
+	deadline time.Time
+	md       metadata.MD
+}
+
+// flakyService fails the first failures calls with Unavailable & records all received calls.
+type flakyService struct {
+	mu       sync.Mutex
+	failures int
+	attempts []attempt
+}
+
+func (s *flakyService) record(ctx context.Context) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := attempt{at: time.Now()}
+	a.deadline, _ = ctx.Deadline()
+	a.md, _ = metadata.FromIncomingContext(ctx)
+
+	s.attempts = append(s.attempts, a)
+
+	return len(s.attempts)
+}
+
+func (s *flakyService) calls() []attempt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]attempt(nil), s.attempts...)
+}
+
+func (s *flakyService) service() *itemService {
+	return &itemService{
+		getItem: func(ctx context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+			if s.record(ctx) <= s.failures {
+				return nil, status.Error(codes.Unavailable, "try again")
+			}
+
+			return req, nil
+		},
+		watchItems: func(req *wrapperspb.StringValue, ss grpc.ServerStream) error {
+			s.record(ss.Context())
+
+			return ss.SendMsg(req)
+		},
+	}
+}
+
+// dialClient returns connection to svc made by grpckit.Dial using cfg.
+func dialClient(t *testing.T, cfg *grpckit.ClientConfig, svc *itemService) *grpc.ClientConn {
+	t.Helper()
+
+	cfg.Target = "bufnet"
+	cfg.Insecure = true
+
+	conn, err := grpckit.Dial(context.Background(), cfg, newItemServer(t, svc))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestClientRetry(t *testing.T) {
+	t.Run("succeeds after retries", func(t *testing.T) {
+		svc := &flakyService{failures: 2}
+		conn := dialClient(t, &grpckit.ClientConfig{
+			Timeout:         5 * time.Second,
+			MaxRetries:      3,
+			RetryBackoff:    20 * time.Millisecond,
+			RetryMaxBackoff: time.Second,
+		}, svc.service())
+
+		item, err := getItem(context.Background(), conn, "1")
+		require.NoError(t, err)
+		assert.Equal(t, "1", item)
+
+		calls := svc.calls()
+		require.Len(t, calls, 3)
+
+		// backoff doubles on each retry, with equal jitter the wait is at least half of it
+		assert.GreaterOrEqual(t, calls[1].at.Sub(calls[0].at), 10*time.Millisecond)
+		assert.GreaterOrEqual(t, calls[2].at.Sub(calls[1].at), 20*time.Millisecond)
+
+		// all attempts share the call deadline
+		for _, c := range calls {
+			assert.False(t, c.deadline.IsZero())
+			assert.WithinDuration(t, calls[0].deadline, c.deadline, 50*time.Millisecond)
+		}
+	})
+
+	t.Run("stops after max retries", func(t *testing.T) {
+		svc := &flakyService{failures: 10}
+		conn := dialClient(t, &grpckit.ClientConfig{
+			MaxRetries:      2,
+			RetryBackoff:    time.Millisecond,
+			RetryMaxBackoff: time.Millisecond,
+		}, svc.service())
+
+		_, err := getItem(context.Background(), conn, "1")
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Len(t, svc.calls(), 3)
+	})
+
+	t.Run("caps overflowing backoff", func(t *testing.T) {
+		svc := &flakyService{failures: 10}
+		conn := dialClient(t, &grpckit.ClientConfig{
+			MaxRetries:      4,
+			RetryBackoff:    1 << 62,
+			RetryMaxBackoff: 5 * time.Millisecond,
+		}, svc.service())
+
+		start := time.Now()
+
+		_, err := getItem(context.Background(), conn, "1")
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Len(t, svc.calls(), 5)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("stops when context is done during backoff", func(t *testing.T) {
+		svc := &flakyService{failures: 10}
+		conn := dialClient(t, &grpckit.ClientConfig{
+			MaxRetries:      2,
+			RetryBackoff:    10 * time.Second,
+			RetryMaxBackoff: 10 * time.Second,
+		}, svc.service())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+
+		_, err := getItem(ctx, conn, "1")
+		assert.Equal(t, codes.Unavailable, status.Code(err), "last attempt error is returned")
+		assert.Len(t, svc.calls(), 1)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	tests := []struct {
+		name     string
+		cfg      grpckit.ClientConfig
+		attempts int
+	}{
+		{
+			name:     "default idempotent method prefix",
+			cfg:      grpckit.ClientConfig{},
+			attempts: 3,
+		},
+		{
+			name:     "idempotent method",
+			cfg:      grpckit.ClientConfig{IdempotentMethods: []string{methodGetItem}},
+			attempts: 3,
+		},
+		{
+			name:     "idempotent service wildcard",
+			cfg:      grpckit.ClientConfig{IdempotentMethods: []string{"/test.v1.ItemService/*"}},
+			attempts: 3,
+		},
+		{
+			name:     "non idempotent method",
+			cfg:      grpckit.ClientConfig{IdempotentMethods: []string{"/test.v1.ItemService/ListItems"}},
+			attempts: 1,
+		},
+		{
+			name:     "retry disabled",
+			cfg:      grpckit.ClientConfig{MaxRetries: -1},
+			attempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.RetryBackoff = time.Millisecond
+			tt.cfg.RetryMaxBackoff = time.Millisecond
+
+			svc := &flakyService{failures: 10}
+			conn := dialClient(t, &tt.cfg, svc.service())
+
+			_, err := getItem(context.Background(), conn, "1")
+			assert.Equal(t, codes.Unavailable, status.Code(err))
+			assert.Len(t, svc.calls(), tt.attempts)
+		})
+	}
+}
+
+func TestClientMethodTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     grpckit.ClientConfig
+		caller  time.Duration
+		timeout time.Duration
+	}{
+		{
+			name:    "default timeout",
+			cfg:     grpckit.ClientConfig{Timeout: 3 * time.Second},
+			timeout: 3 * time.Second,
+		},
+		{
+			name: "method timeout",
+			cfg: grpckit.ClientConfig{
+				Timeout:        3 * time.Second,
+				MethodTimeouts: map[string]time.Duration{methodGetItem: 5 * time.Second},
+			},
+			timeout: 5 * time.Second,
+		},
+		{
+			name: "service wildcard timeout",
+			cfg: grpckit.ClientConfig{
+				Timeout:        3 * time.Second,
+				MethodTimeouts: map[string]time.Duration{"/test.v1.ItemService/*": 7 * time.Second},
+			},
+			timeout: 7 * time.Second,
+		},
+		{
+			name:    "caller deadline longer than default timeout",
+			cfg:     grpckit.ClientConfig{Timeout: 3 * time.Second},
+			caller:  8 * time.Second,
+			timeout: 8 * time.Second,
+		},
+		{
+			name: "method timeout shorter than caller deadline",
+			cfg: grpckit.ClientConfig{
+				Timeout:        3 * time.Second,
+				MethodTimeouts: map[string]time.Duration{methodGetItem: 5 * time.Second},
+			},
+			caller:  8 * time.Second,
+			timeout: 5 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &flakyService{}
+			conn := dialClient(t, &tt.cfg, svc.service())
+
+			start := time.Now()
+			ctx := context.Background()
+
+			if tt.caller > 0 {
+				var cancel context.CancelFunc
+
+				ctx, cancel = context.WithTimeout(ctx, tt.caller)
+				defer cancel()
+			}
+
+			_, err := getItem(ctx, conn, "1")
+			require.NoError(t, err)
+
+			calls := svc.calls()
+			require.Len(t, calls, 1)
+			assert.WithinDuration(t, start.Add(tt.timeout), calls[0].deadline, 500*time.Millisecond)
+		})
+	}
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		conn := dialClient(t, &grpckit.ClientConfig{
+			MethodTimeouts: map[string]time.Duration{methodGetItem: 50 * time.Millisecond},
+		}, &itemService{
+			getItem: func(ctx context.Context, _ *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+				<-ctx.Done()
+
+				return nil, status.FromContextError(ctx.Err()).Err()
+			},
+		})
+
+		start := time.Now()
+
+		_, err := getItem(context.Background(), conn, "1")
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		svc := &flakyService{}
+		conn := dialClient(t, &grpckit.ClientConfig{Timeout: 3 * time.Second}, svc.service())
+
+		_, err := watchItems(context.Background(), conn, "1")
+		require.NoError(t, err)
+
+		conn = dialClient(t, &grpckit.ClientConfig{
+			MethodTimeouts: map[string]time.Duration{methodWatchItems: 5 * time.Second},
+		}, svc.service())
+
+		_, err = watchItems(context.Background(), conn, "1")
+		require.NoError(t, err)
+
+		calls := svc.calls()
+		require.Len(t, calls, 2)
+		assert.True(t, calls[0].deadline.IsZero(), "streams only use method timeouts")
+		assert.False(t, calls[1].deadline.IsZero())
+	})
+}
+
+func TestClientMetadataPropagation(t *testing.T) {
+	tc := web.NewTraceContext(nil)
+	tc.TraceState = "vendor=value"
+
+	ctx := context.WithValue(context.Background(), web.ContextKeyRequestID, "request-1")
+	ctx = web.ContextWithTraceContext(ctx, tc)
+
+	t.Run("unary", func(t *testing.T) {
+		svc := &flakyService{failures: 1}
+		conn := dialClient(t, &grpckit.ClientConfig{RetryBackoff: time.Millisecond}, svc.service())
+
+		_, err := getItem(ctx, conn, "1")
+		require.NoError(t, err)
+
+		calls := svc.calls()
+		require.Len(t, calls, 2)
+
+		for _, c := range calls {
+			assert.Equal(t, []string{"request-1"}, c.md.Get("x-request-id"))
+			assert.Equal(t, []string{tc.TraceParent()}, c.md.Get(web.HTTPKeyTraceParent))
+			assert.Equal(t, []string{"vendor=value"}, c.md.Get(web.HTTPKeyTraceState))
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		svc := &flakyService{}
+		conn := dialClient(t, &grpckit.ClientConfig{}, svc.service())
+
+		_, err := watchItems(ctx, conn, "1")
+		require.NoError(t, err)
+
+		calls := svc.calls()
+		require.Len(t, calls, 1)
+		assert.Equal(t, []string{"request-1"}, calls[0].md.Get("x-request-id"))
+		assert.Equal(t, []string{tc.TraceParent()}, calls[0].md.Get(web.HTTPKeyTraceParent))
+	})
+
+	t.Run("existing metadata is kept", func(t *testing.T) {
+		svc := &flakyService{}
+		conn := dialClient(t, &grpckit.ClientConfig{}, svc.service())
+
+		outCtx := metadata.AppendToOutgoingContext(ctx, "x-request-id", "client-set")
+
+		_, err := getItem(outCtx, conn, "1")
+		require.NoError(t, err)
+
+		calls := svc.calls()
+		require.Len(t, calls, 1)
+		assert.Equal(t, []string{"client-set"}, calls[0].md.Get("x-request-id"))
+	})
+}
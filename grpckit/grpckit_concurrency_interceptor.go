@@ -0,0 +1,76 @@
+package grpckit
+
+import (
+	"context"
+	"errors"
+	"path"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/adipurnama/go-toolkit/concurrencykit"
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+	"github.com/adipurnama/go-toolkit/internal/grpcutil"
+)
+
+// ConcurrencyLimitInterceptor sheds unary request load using limiter, request above the current
+// concurrency limit is rejected early with Unavailable status.
+//
+// priorities sets request priority keyed by full method e.g. `/order.v1.OrderService/CreateOrder`
+// or service e.g. `order.v1.OrderService`, defaults to concurrencykit.PriorityNormal.
+// DeadlineExceeded & Unavailable responses are counted as dropped, decreasing the limit.
+// Panicking request releases its slot without adjusting the limit.
+// Stream requests are long-lived, so they aren't limited.
+func ConcurrencyLimitInterceptor(limiter *concurrencykit.Limiter, priorities map[string]concurrencykit.Priority) grpc.UnaryServerInterceptor {
+	if limiter == nil {
+		panic("grpckit: concurrency limiter cannot be nil")
+	}
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		if grpcutil.IsHealthCheck(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		service := path.Dir(info.FullMethod)[1:]
+
+		priority, ok := priorities[info.FullMethod]
+		if !ok {
+			priority = priorities[service]
+		}
+
+		token, ok := limiter.Acquire(priority)
+		if !ok {
+			return nil, apperrors.New(apperrors.CodeUnavailable).
+				WithDetail("reason", "concurrency limit exceeded").
+				GRPCStatus().Err()
+		}
+
+		panicked := true
+
+		defer func() {
+			code := status.Code(err)
+
+			switch {
+			case panicked:
+				// panic is recovered by outer RecoverInterceptor, it's unrelated to load
+				token.Ignore()
+			case code == codes.DeadlineExceeded || code == codes.Unavailable ||
+				errors.Is(ctx.Err(), context.DeadlineExceeded):
+				token.Dropped()
+			default:
+				token.Success()
+			}
+		}()
+
+		resp, err = handler(ctx, req)
+		panicked = false
+
+		return resp, err
+	}
+}
@@ -0,0 +1,52 @@
+package grpckit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/adipurnama/go-toolkit/concurrencykit"
+	"github.com/adipurnama/go-toolkit/grpckit"
+)
+
+func TestConcurrencyLimitInterceptorPanic(t *testing.T) {
+	limiter := concurrencykit.New(&concurrencykit.Config{
+		Algorithm:    concurrencykit.AIMD,
+		InitialLimit: 2,
+		Registerer:   prometheus.NewRegistry(),
+	})
+
+	conn := dialItemServer(t, &itemService{
+		getItem: func(_ context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+			if req.GetValue() == "panic" {
+				panic("boom")
+			}
+
+			return req, nil
+		},
+	},
+		grpc.ChainUnaryInterceptor(
+			grpckit.RecoverInterceptor(nil),
+			grpckit.ConcurrencyLimitInterceptor(limiter, nil),
+		),
+	)
+
+	for i := 0; i < 5; i++ {
+		_, err := getItem(context.Background(), conn, "panic")
+		assert.Equal(t, codes.Internal, status.Code(err))
+	}
+
+	assert.Equal(t, 0, limiter.InFlight(), "panicking request releases its slot")
+	assert.Equal(t, 2, limiter.Limit(), "panic doesn't adjust the limit")
+
+	item, err := getItem(context.Background(), conn, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "1", item)
+}
@@ -7,12 +7,16 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+	"github.com/adipurnama/go-toolkit/web"
 )
 
 // GRPCErrorHandler transforms error to valid grpc standard protobuff status.
 type GRPCErrorHandler func(err error) *spb.Status
 
 // ErrorResponseWriterInterceptor writes grpc status based on error found from upstream call.
+// *errors.AppError found in error chain is written using its own gRPC code, message & details.
 func ErrorResponseWriterInterceptor(errHandler GRPCErrorHandler) grpc.UnaryServerInterceptor {
 	return func(
 		ctx context.Context,
@@ -25,6 +29,13 @@ func ErrorResponseWriterInterceptor(errHandler GRPCErrorHandler) grpc.UnaryServe
 			return resp, nil
 		}
 
+		// errors.AppError carries its own gRPC code & message
+		if appErr, ok := apperrors.FromError(err); ok {
+			trans := web.TranslatorFromContext(ctx)
+
+			return resp, appErr.LocalizedGRPCStatus(trans).Err()
+		}
+
 		code := status.Code(err)
 		if code != codes.Unknown {
 			return resp, err
@@ -5,8 +5,10 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"sync/atomic"
 	"time"
 
+	"github.com/adipurnama/go-toolkit/internal/grpcutil"
 	"github.com/adipurnama/go-toolkit/log"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -47,31 +49,81 @@ func LoggerInterceptor() grpc.UnaryServerInterceptor {
 			fields = append(fields, "metadata", md)
 		}
 
+		logCompletion(newCtx, info.FullMethod, err, fields)
+
 		if err != nil {
-			msg := fmt.Sprintf("%s - gRPC request completed with error", info.FullMethod)
+			return nil, err
+		}
 
-			if clientRequestErrorCode(code) {
-				fields = append(fields, "error", err)
-				log.FromCtx(newCtx).Info(msg, fields...)
-			} else {
-				log.FromCtx(newCtx).Error(err, msg, fields...)
-			}
+		return resp, nil
+	}
+}
 
-			return nil, err
+// LoggerStreamInterceptor adds logger to stream context.Context & logs the stream output:
+// duration, sent & received messages count and final code.
+func LoggerStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if grpcutil.IsHealthCheck(info.FullMethod) {
+			return handler(srv, ss)
 		}
 
-		msg := fmt.Sprintf("%s - gRPC request completed", info.FullMethod)
+		service := path.Dir(info.FullMethod)[1:]
+		start := time.Now()
+		stream := wrapServerStream(newCtxWithLogger(ss.Context(), info.FullMethod, start), ss)
 
-		switch codeToLogLevel(code) {
-		case log.LevelDebug:
-			log.FromCtx(newCtx).Debug(msg, fields...)
-		case log.LevelWarn:
-			log.FromCtx(newCtx).Warn(msg, fields...)
-		default:
-			log.FromCtx(newCtx).Info(msg, fields...)
+		err := handler(srv, stream)
+
+		fields := []interface{}{
+			"grpc.code", status.Code(err).String(),
+			"grpc.time_ms", time.Since(start).Milliseconds(),
+			"grpc.service", service,
+			"grpc.stream.client", info.IsClientStream,
+			"grpc.stream.server", info.IsServerStream,
+			"grpc.stream.msg_sent", atomic.LoadInt64(&stream.sent),
+			"grpc.stream.msg_received", atomic.LoadInt64(&stream.received),
 		}
 
-		return resp, nil
+		if md, ok := metadata.FromIncomingContext(ss.Context()); ok {
+			fields = append(fields, "metadata", md)
+		}
+
+		logCompletion(stream.Context(), info.FullMethod, err, fields)
+
+		return err
+	}
+}
+
+// logCompletion logs request completion with level based on err's code.
+func logCompletion(ctx context.Context, fullMethod string, err error, fields []interface{}) {
+	code := status.Code(err)
+
+	if err != nil {
+		msg := fmt.Sprintf("%s - gRPC request completed with error", fullMethod)
+
+		if clientRequestErrorCode(code) {
+			fields = append(fields, "error", err)
+			log.FromCtx(ctx).Info(msg, fields...)
+		} else {
+			log.FromCtx(ctx).Error(err, msg, fields...)
+		}
+
+		return
+	}
+
+	msg := fmt.Sprintf("%s - gRPC request completed", fullMethod)
+
+	switch codeToLogLevel(code) {
+	case log.LevelDebug:
+		log.FromCtx(ctx).Debug(msg, fields...)
+	case log.LevelWarn:
+		log.FromCtx(ctx).Warn(msg, fields...)
+	default:
+		log.FromCtx(ctx).Info(msg, fields...)
 	}
 }
 
@@ -0,0 +1,184 @@
+package grpckit
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+	"github.com/adipurnama/go-toolkit/internal/grpcutil"
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/ratelimitkit"
+)
+
+// rate limit response header metadata keys.
+const (
+	keyRateLimitLimit     = "ratelimit-limit"
+	keyRateLimitRemaining = "ratelimit-remaining"
+	keyRateLimitReset     = "ratelimit-reset"
+	keyRetryAfter         = "retry-after"
+)
+
+// RateLimitKeyFunc extracts rate limit key from incoming request.
+// empty key means the request is not rate limited.
+type RateLimitKeyFunc func(ctx context.Context, fullMethod string) string
+
+// RateLimitKeyByPeerIP returns RateLimitKeyFunc using client's peer IP address.
+func RateLimitKeyByPeerIP() RateLimitKeyFunc {
+	return func(ctx context.Context, _ string) string {
+		p, ok := peer.FromContext(ctx)
+		if !ok || p.Addr == nil {
+			return ""
+		}
+
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+
+		return "ip:" + host
+	}
+}
+
+// RateLimitKeyByMetadata returns RateLimitKeyFunc using incoming metadata value, e.g. `x-api-key`.
+// The value is hashed, see ratelimitkit.HashedKey.
+func RateLimitKeyByMetadata(key string) RateLimitKeyFunc {
+	return func(ctx context.Context, _ string) string {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return ""
+		}
+
+		if vals := md.Get(key); len(vals) > 0 && vals[0] != "" {
+			return ratelimitkit.HashedKey(key, vals[0])
+		}
+
+		return ""
+	}
+}
+
+// RateLimitInterceptor limits unary request rate per key using limiter,
+// keyFunc defaults to RateLimitKeyByPeerIP.
+// It sends ratelimit-* header metadata, and returns ResourceExhausted status
+// with RetryInfo details when the limit is reached.
+func RateLimitInterceptor(limiter ratelimitkit.Limiter, keyFunc RateLimitKeyFunc) grpc.UnaryServerInterceptor {
+	rl := newRateLimiter(limiter, keyFunc)
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		md, err := rl.allow(ctx, info.FullMethod)
+		if md != nil {
+			_ = grpc.SetHeader(ctx, md)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStreamInterceptor limits stream request rate per key using limiter,
+// see RateLimitInterceptor.
+func RateLimitStreamInterceptor(limiter ratelimitkit.Limiter, keyFunc RateLimitKeyFunc) grpc.StreamServerInterceptor {
+	rl := newRateLimiter(limiter, keyFunc)
+
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		md, err := rl.allow(stream.Context(), info.FullMethod)
+		if md != nil {
+			_ = stream.SetHeader(md)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, stream)
+	}
+}
+
+type rateLimiter struct {
+	limiter ratelimitkit.Limiter
+	keyFunc RateLimitKeyFunc
+}
+
+func newRateLimiter(limiter ratelimitkit.Limiter, keyFunc RateLimitKeyFunc) *rateLimiter {
+	if limiter == nil {
+		panic("grpckit: rate limiter cannot be nil")
+	}
+
+	if keyFunc == nil {
+		keyFunc = RateLimitKeyByPeerIP()
+	}
+
+	return &rateLimiter{
+		limiter: limiter,
+		keyFunc: keyFunc,
+	}
+}
+
+// allow returns header metadata to send & error status if request is not allowed.
+func (rl *rateLimiter) allow(ctx context.Context, fullMethod string) (metadata.MD, error) {
+	if grpcutil.IsHealthCheck(fullMethod) {
+		return nil, nil
+	}
+
+	key := rl.keyFunc(ctx, fullMethod)
+	if key == "" {
+		return nil, nil
+	}
+
+	res, err := rl.limiter.Allow(ctx, key)
+	if err != nil {
+		log.FromCtx(ctx).WarnError(err, "rate limiter failed, request is allowed", "key", key)
+
+		return nil, nil
+	}
+
+	md := metadata.Pairs(
+		keyRateLimitLimit, strconv.Itoa(res.Limit),
+		keyRateLimitRemaining, strconv.Itoa(res.Remaining),
+		keyRateLimitReset, strconv.Itoa(ceilSeconds(res.Reset)),
+	)
+
+	if res.Allowed {
+		return md, nil
+	}
+
+	retryAfter := ceilSeconds(res.RetryAfter)
+	md.Set(keyRetryAfter, strconv.Itoa(retryAfter))
+
+	st := apperrors.New(apperrors.CodeResourceExhausted).
+		WithDetail("retry_after_seconds", retryAfter).
+		GRPCStatus()
+
+	if stWithRetry, errDetails := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(res.RetryAfter),
+	}); errDetails == nil {
+		st = stWithRetry
+	}
+
+	return md, st.Err()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,91 @@
+package grpckit_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/adipurnama/go-toolkit/grpckit"
+	"github.com/adipurnama/go-toolkit/ratelimitkit"
+)
+
+// keyRecorder records keys passed to the wrapped limiter.
+type keyRecorder struct {
+	ratelimitkit.Limiter
+
+	mu   sync.Mutex
+	keys []string
+}
+
+func (r *keyRecorder) Allow(ctx context.Context, key string) (ratelimitkit.Result, error) {
+	r.mu.Lock()
+	r.keys = append(r.keys, key)
+	r.mu.Unlock()
+
+	return r.Limiter.Allow(ctx, key)
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	memory, err := ratelimitkit.NewMemoryLimiter(ratelimitkit.TokenBucket, ratelimitkit.Limit{
+		Rate:   1,
+		Period: time.Minute,
+		Burst:  2,
+	})
+	require.NoError(t, err)
+
+	limiter := &keyRecorder{Limiter: memory}
+	keyFunc := grpckit.RateLimitKeyByMetadata("x-api-key")
+
+	conn := dialItemServer(t, &itemService{
+		getItem: func(_ context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+			return req, nil
+		},
+		watchItems: func(req *wrapperspb.StringValue, ss grpc.ServerStream) error {
+			return ss.SendMsg(req)
+		},
+	},
+		grpc.UnaryInterceptor(grpckit.RateLimitInterceptor(limiter, keyFunc)),
+		grpc.StreamInterceptor(grpckit.RateLimitStreamInterceptor(limiter, keyFunc)),
+	)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "s3cr3t")
+
+	var header metadata.MD
+
+	_, err = getItem(ctx, conn, "1", grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, header.Get("ratelimit-limit"))
+	assert.Equal(t, []string{"1"}, header.Get("ratelimit-remaining"))
+
+	items, err := watchItems(ctx, conn, "2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, items)
+
+	_, err = getItem(ctx, conn, "3", grpc.Header(&header))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.NotEmpty(t, header.Get("retry-after"))
+
+	_, err = watchItems(ctx, conn, "4")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "stream shares the limit")
+
+	// request without key isn't limited
+	_, err = getItem(context.Background(), conn, "5")
+	assert.NoError(t, err)
+
+	require.NotEmpty(t, limiter.keys)
+
+	for _, k := range limiter.keys {
+		assert.True(t, strings.HasPrefix(k, "x-api-key:"))
+		assert.NotContains(t, k, "s3cr3t", "key value is hashed")
+	}
+}
@@ -0,0 +1,70 @@
+package grpckit
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/runtimekit"
+)
+
+const msgPanicInternal = "found panic while serving request"
+
+// RecoverInterceptor recovers panic from unary handler, logs it with its stack trace
+// using logger from request context and returns Internal status.
+// The panic value is not exposed in the response.
+// reporter is optional, called with the recovered panic e.g. to send it to error tracker.
+func RecoverInterceptor(reporter runtimekit.PanicReporter) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverPanic(ctx, info.FullMethod, r, reporter)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// RecoverStreamInterceptor recovers panic from stream handler, see RecoverInterceptor.
+func RecoverStreamInterceptor(reporter runtimekit.PanicReporter) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverPanic(stream.Context(), info.FullMethod, r, reporter)
+			}
+		}()
+
+		return handler(srv, stream)
+	}
+}
+
+func recoverPanic(ctx context.Context, fullMethod string, r interface{}, reporter runtimekit.PanicReporter) error {
+	pErr := runtimekit.NewPanicError(r)
+
+	log.FromCtx(ctx).Error(
+		pErr,
+		"recovered from panic",
+		"grpc.method", fullMethod,
+		"panic_stack", pErr.Stack,
+	)
+
+	if reporter != nil {
+		reporter(ctx, pErr)
+	}
+
+	return status.Error(codes.Internal, msgPanicInternal)
+}
@@ -0,0 +1,65 @@
+package grpckit_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/adipurnama/go-toolkit/grpckit"
+	"github.com/adipurnama/go-toolkit/runtimekit"
+)
+
+func TestRecoverInterceptor(t *testing.T) {
+	var reported int32
+
+	reporter := func(_ context.Context, _ *runtimekit.PanicError) {
+		atomic.AddInt32(&reported, 1)
+	}
+
+	conn := dialItemServer(t, &itemService{
+		getItem: func(_ context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+			if req.GetValue() == "panic" {
+				panic("boom")
+			}
+
+			return req, nil
+		},
+		watchItems: func(req *wrapperspb.StringValue, ss grpc.ServerStream) error {
+			if req.GetValue() == "panic" {
+				panic("boom")
+			}
+
+			return ss.SendMsg(req)
+		},
+	},
+		grpc.UnaryInterceptor(grpckit.RecoverInterceptor(reporter)),
+		grpc.StreamInterceptor(grpckit.RecoverStreamInterceptor(reporter)),
+	)
+
+	ctx := context.Background()
+
+	_, err := getItem(ctx, conn, "panic")
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.NotContains(t, err.Error(), "boom", "panic value isn't exposed")
+
+	_, err = watchItems(ctx, conn, "panic")
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&reported))
+
+	// server keeps serving
+	item, err := getItem(ctx, conn, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "1", item)
+
+	items, err := watchItems(ctx, conn, "2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, items)
+}
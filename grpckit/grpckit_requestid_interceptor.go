@@ -3,26 +3,18 @@ package grpckit
 import (
 	"context"
 
-	shortuuid "github.com/lithammer/shortuuid/v3"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+
+	"github.com/adipurnama/go-toolkit/web"
 )
 
 // RequestIDProvider generates new request id for each requests.
-type RequestIDProvider interface {
-	NewRequestID() string
-}
+type RequestIDProvider = web.RequestIDProvider
 
 // DefaultRequestIDProvider generates shortuuid string for request id.
 func DefaultRequestIDProvider() RequestIDProvider {
-	return &shortuuidRequestIDProvider{}
-}
-
-type shortuuidRequestIDProvider struct{}
-
-// NewRequestID implements RequestIDProvider interface.
-func (p *shortuuidRequestIDProvider) NewRequestID() string {
-	return shortuuid.New()
+	return web.DefaultRequestIDProvider()
 }
 
 // RequestIDInterceptor add request id to incoming request if it doesn't exists yet.
@@ -37,15 +29,35 @@ func RequestIDInterceptor(rIDProvider RequestIDProvider) grpc.UnaryServerInterce
 			rIDProvider = DefaultRequestIDProvider()
 		}
 
-		if md, ok := metadata.FromIncomingContext(ctx); ok {
-			if _, rIDFound := md[keyRequestID]; !rIDFound {
-				md.Set(keyRequestID, rIDProvider.NewRequestID())
-				ctx = metadata.NewIncomingContext(ctx, md)
-			}
-		}
-
-		resp, err = handler(ctx, req)
+		resp, err = handler(ctxWithRequestID(ctx, rIDProvider), req)
 
 		return resp, err
 	}
 }
+
+// RequestIDStreamInterceptor add request id to incoming stream if it doesn't exists yet.
+func RequestIDStreamInterceptor(rIDProvider RequestIDProvider) grpc.StreamServerInterceptor {
+	if rIDProvider == nil {
+		rIDProvider = DefaultRequestIDProvider()
+	}
+
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return handler(srv, wrapServerStream(ctxWithRequestID(ss.Context(), rIDProvider), ss))
+	}
+}
+
+func ctxWithRequestID(ctx context.Context, rIDProvider RequestIDProvider) context.Context {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if _, rIDFound := md[keyRequestID]; !rIDFound {
+			md.Set(keyRequestID, rIDProvider.NewRequestID())
+			ctx = metadata.NewIncomingContext(ctx, md)
+		}
+	}
+
+	return ctx
+}
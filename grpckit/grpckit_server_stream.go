@@ -0,0 +1,42 @@
+package grpckit
+
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+
+	"github.com/adipurnama/go-toolkit/internal/grpcutil"
+)
+
+// serverStream wraps grpc.ServerStream to override its context
+// and count sent & received messages.
+type serverStream struct {
+	*grpcutil.ServerStream
+	sent     int64
+	received int64
+}
+
+func wrapServerStream(ctx context.Context, ss grpc.ServerStream) *serverStream {
+	return &serverStream{ServerStream: grpcutil.WrapServerStream(ctx, ss)}
+}
+
+// SendMsg implements grpc.ServerStream.
+func (s *serverStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		atomic.AddInt64(&s.sent, 1)
+	}
+
+	return err
+}
+
+// RecvMsg implements grpc.ServerStream.
+func (s *serverStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		atomic.AddInt64(&s.received, 1)
+	}
+
+	return err
+}
@@ -0,0 +1,97 @@
+package grpckit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/adipurnama/go-toolkit/grpckit"
+)
+
+type staticRequestID string
+
+func (id staticRequestID) NewRequestID() string {
+	return string(id)
+}
+
+// requestIDService streams back request ID seen in the handler's stream context.
+func requestIDService() *itemService {
+	return &itemService{
+		watchItems: func(_ *wrapperspb.StringValue, ss grpc.ServerStream) error {
+			md, _ := metadata.FromIncomingContext(ss.Context())
+
+			for _, id := range md.Get("x-request-id") {
+				if err := ss.SendMsg(wrapperspb.String(id)); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+func TestRequestIDStreamInterceptor(t *testing.T) {
+	conn := dialItemServer(t, requestIDService(),
+		grpc.ChainStreamInterceptor(
+			grpckit.RequestIDStreamInterceptor(staticRequestID("generated-id")),
+			grpckit.LoggerStreamInterceptor(),
+		),
+	)
+
+	t.Run("generated", func(t *testing.T) {
+		ids, err := watchItems(context.Background(), conn, "1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"generated-id"}, ids)
+	})
+
+	t.Run("propagated", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "client-id")
+
+		ids, err := watchItems(ctx, conn, "1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"client-id"}, ids)
+	})
+}
+
+func TestRequestTimeoutStreamInterceptor(t *testing.T) {
+	conn := dialItemServer(t, &itemService{
+		watchItems: func(_ *wrapperspb.StringValue, ss grpc.ServerStream) error {
+			if err := ss.SendMsg(wrapperspb.String("first")); err != nil {
+				return err
+			}
+
+			if _, ok := ss.Context().Deadline(); !ok {
+				return status.Error(codes.FailedPrecondition, "stream context has no deadline")
+			}
+
+			select {
+			case <-ss.Context().Done():
+				return status.FromContextError(ss.Context().Err()).Err()
+			case <-time.After(5 * time.Second):
+				return ss.SendMsg(wrapperspb.String("late"))
+			}
+		},
+	},
+		grpc.ChainStreamInterceptor(
+			grpckit.LoggerStreamInterceptor(),
+			grpckit.RequestTimeoutStreamInterceptor(50*time.Millisecond),
+		),
+	)
+
+	start := time.Now()
+
+	items, err := watchItems(context.Background(), conn, "1")
+
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Equal(t, []string{"first"}, items)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,147 @@
+package grpckit_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	methodGetItem    = "/test.v1.ItemService/GetItem"
+	methodWatchItems = "/test.v1.ItemService/WatchItems"
+)
+
+// itemService is test gRPC service with unary GetItem & server streaming WatchItems methods.
+type itemService struct {
+	getItem    func(ctx context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error)
+	watchItems func(req *wrapperspb.StringValue, ss grpc.ServerStream) error
+}
+
+var itemServiceDesc = grpc.ServiceDesc{
+	ServiceName: "test.v1.ItemService",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetItem",
+			Handler: func(
+				srv interface{},
+				ctx context.Context,
+				dec func(interface{}) error,
+				interceptor grpc.UnaryServerInterceptor,
+			) (interface{}, error) {
+				req := new(wrapperspb.StringValue)
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(*itemService).getItem(ctx, req.(*wrapperspb.StringValue))
+				}
+
+				if interceptor == nil {
+					return handler(ctx, req)
+				}
+
+				return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: methodGetItem}, handler)
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchItems",
+			ServerStreams: true,
+			Handler: func(srv interface{}, ss grpc.ServerStream) error {
+				req := new(wrapperspb.StringValue)
+				if err := ss.RecvMsg(req); err != nil {
+					return err
+				}
+
+				return srv.(*itemService).watchItems(req, ss)
+			},
+		},
+	},
+}
+
+// newItemServer serves svc using in-memory listener, returns the listener's dial option.
+func newItemServer(t *testing.T, svc *itemService, opts ...grpc.ServerOption) grpc.DialOption {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(opts...)
+	s.RegisterService(&itemServiceDesc, svc)
+
+	go func() {
+		_ = s.Serve(lis)
+	}()
+
+	t.Cleanup(s.Stop)
+
+	return grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	})
+}
+
+// dialItemServer returns client connection to svc served with opts.
+func dialItemServer(t *testing.T, svc *itemService, opts ...grpc.ServerOption) *grpc.ClientConn {
+	t.Helper()
+
+	conn, err := grpc.Dial("bufnet",
+		newItemServer(t, svc, opts...),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func getItem(ctx context.Context, conn *grpc.ClientConn, id string, opts ...grpc.CallOption) (string, error) {
+	resp := new(wrapperspb.StringValue)
+	if err := conn.Invoke(ctx, methodGetItem, wrapperspb.String(id), resp, opts...); err != nil {
+		return "", err
+	}
+
+	return resp.GetValue(), nil
+}
+
+// watchItems returns all items received from WatchItems stream & its final error, nil on io.EOF.
+func watchItems(ctx context.Context, conn *grpc.ClientConn, id string, opts ...grpc.CallOption) ([]string, error) {
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, methodWatchItems, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.SendMsg(wrapperspb.String(id)); err != nil {
+		return nil, err
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	var items []string
+
+	for {
+		resp := new(wrapperspb.StringValue)
+
+		err := stream.RecvMsg(resp)
+		if err == io.EOF {
+			return items, nil
+		}
+
+		if err != nil {
+			return items, err
+		}
+
+		items = append(items, resp.GetValue())
+	}
+}
@@ -23,3 +23,19 @@ func RequestTimeoutInterceptor(t time.Duration) grpc.UnaryServerInterceptor {
 		return resp, err
 	}
 }
+
+// RequestTimeoutStreamInterceptor adds timeout to incoming stream,
+// use it only for short-lived streams as the whole stream is cancelled after t.
+func RequestTimeoutStreamInterceptor(t time.Duration) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx, cancel := context.WithTimeout(ss.Context(), t)
+		defer cancel()
+
+		return handler(srv, wrapServerStream(ctx, ss))
+	}
+}
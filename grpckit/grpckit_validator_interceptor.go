@@ -0,0 +1,90 @@
+package grpckit
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+const keyAcceptLanguage = "accept-language"
+
+// TranslatorInterceptor adds translator negotiated from `accept-language` metadata
+// to request's context, see web.TranslatorFromContext.
+// errors.AppError messages are added to all v's locales.
+func TranslatorInterceptor(v *web.Validator) grpc.UnaryServerInterceptor {
+	if err := v.AddTranslations(apperrors.RegisterTranslations); err != nil {
+		log.FromCtx(context.Background()).Error(err, "failed to register errors.AppError translations, messages are not translated")
+	}
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		var lang string
+
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if vals := md.Get(keyAcceptLanguage); len(vals) > 0 {
+				lang = vals[0]
+			}
+		}
+
+		ctx = context.WithValue(ctx, web.ContextKeyTranslator, v.Negotiate(lang))
+
+		return handler(ctx, req)
+	}
+}
+
+// ValidatorInterceptor validates request message `validate` struct tags using v,
+// e.g. generated by protoc-gen-go-tag. It should be registered after TranslatorInterceptor.
+// Invalid request returns InvalidArgument status with translated BadRequest field violations.
+func ValidatorInterceptor(v *web.Validator) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		if err := v.Validate(req); err != nil {
+			return nil, validationStatus(ctx, err)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func validationStatus(ctx context.Context, err error) error {
+	httpErr := web.NewHTTPValidationError(ctx, err)
+
+	details, ok := httpErr.Response.(web.ErrorDetails)
+	if !ok {
+		// not a struct or validator failure
+		return status.Error(codes.InvalidArgument, errors.Cause(err).Error())
+	}
+
+	br := &errdetails.BadRequest{}
+
+	for _, f := range details.Errors {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       f.Field,
+			Description: f.Message,
+		})
+	}
+
+	st, errDetails := status.New(codes.InvalidArgument, httpErr.Message).WithDetails(br)
+	if errDetails != nil {
+		return status.Error(codes.InvalidArgument, httpErr.Message)
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,33 @@
+// Package grpcutil contains gRPC helpers shared by the toolkit server interceptors.
+package grpcutil
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// HealthService is full method prefix of the standard gRPC health checking service.
+const HealthService = "/grpc.health.v1.Health/"
+
+// IsHealthCheck reports whether fullMethod, e.g. `/pkg.Service/Method`, belongs to HealthService.
+func IsHealthCheck(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, HealthService)
+}
+
+// ServerStream overrides grpc.ServerStream context.
+type ServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// WrapServerStream returns ss with its context replaced by ctx.
+func WrapServerStream(ctx context.Context, ss grpc.ServerStream) *ServerStream {
+	return &ServerStream{ServerStream: ss, ctx: ctx}
+}
+
+// Context implements grpc.ServerStream.
+func (s *ServerStream) Context() context.Context {
+	return s.ctx
+}
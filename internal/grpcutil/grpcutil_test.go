@@ -0,0 +1,26 @@
+package grpcutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/adipurnama/go-toolkit/internal/grpcutil"
+)
+
+type ctxKey struct{}
+
+func TestIsHealthCheck(t *testing.T) {
+	assert.True(t, grpcutil.IsHealthCheck("/grpc.health.v1.Health/Check"))
+	assert.True(t, grpcutil.IsHealthCheck("/grpc.health.v1.Health/Watch"))
+	assert.False(t, grpcutil.IsHealthCheck("/grpc.health.v1.HealthExt/Check"))
+	assert.False(t, grpcutil.IsHealthCheck("/order.v1.OrderService/GetOrder"))
+}
+
+func TestWrapServerStream(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	ss := grpcutil.WrapServerStream(ctx, nil)
+
+	assert.Equal(t, "value", ss.Context().Value(ctxKey{}))
+}
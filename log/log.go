@@ -252,6 +252,8 @@ func stringify(val interface{}) string {
 		return fmt.Sprintf("%t", v)
 	case string:
 		return strcase.ToSnake(v)
+	case RawKey:
+		return string(v)
 	default:
 		return strcase.ToSnake(fmt.Sprintf("%+v", v))
 	}
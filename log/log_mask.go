@@ -36,6 +36,8 @@ var headerMatches = []string{
 	`key$`,
 	`signature`,
 	`^authorization$`,
+	`^cookie$`,
+	`^set-cookie$`,
 }
 
 // parameterMatcher is precompiled for performance reasons. Keep in mind that
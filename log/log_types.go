@@ -43,6 +43,10 @@ type config struct {
 	isDevelopment bool
 }
 
+// RawKey is log field key written as is, without snake_case conversion.
+// e.g. `log.RawKey("httpRequest")` for GCP structured logging field.
+type RawKey string
+
 // ErrFunc is any function which takes no argument and possibly returns error
 // e.g. tx.Rollback(), resp.Body.Close().
 type ErrFunc func() error
@@ -0,0 +1,167 @@
+// Package ratelimitkit provides per-key rate limiter
+// with in-memory & redis backed store
+package ratelimitkit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Algorithm is rate limiting algorithm.
+type Algorithm string
+
+const (
+	// TokenBucket allows burst up to Limit.Burst requests,
+	// refilled at Limit.Rate tokens per Limit.Period.
+	TokenBucket Algorithm = "token_bucket"
+
+	// SlidingWindow allows Limit.Rate requests in any Limit.Period window,
+	// approximated using current & previous fixed window counter.
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+const defaultPeriod = time.Second
+
+// ErrInvalidLimit is returned by limiter constructors when Limit.Rate is not positive.
+var ErrInvalidLimit = errors.New("ratelimitkit: limit rate must be positive")
+
+// Limit defines allowed request rate, Rate must be positive.
+// default value:
+//   - period: 1 second
+//   - burst: same as rate, only used by TokenBucket
+type Limit struct {
+	Rate   int           `json:"rate"`
+	Period time.Duration `json:"period,omitempty"`
+	Burst  int           `json:"burst,omitempty"`
+}
+
+// PerSecond returns Limit with rate requests per second.
+func PerSecond(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Second}
+}
+
+// PerMinute returns Limit with rate requests per minute.
+func PerMinute(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Minute}
+}
+
+func (l Limit) normalize() (Limit, error) {
+	if l.Rate <= 0 {
+		return l, errors.Wrapf(ErrInvalidLimit, "rate %d", l.Rate)
+	}
+
+	if l.Period <= 0 {
+		l.Period = defaultPeriod
+	}
+
+	if l.Burst <= 0 {
+		l.Burst = l.Rate
+	}
+
+	return l, nil
+}
+
+// tokenInterval returns duration to refill one token.
+func (l Limit) tokenInterval() time.Duration {
+	return l.Period / time.Duration(l.Rate)
+}
+
+// ttl returns duration after which idle key state is no longer relevant.
+func (l Limit) ttl() time.Duration {
+	return 2*l.Period + time.Duration(l.Burst)*l.tokenInterval()
+}
+
+// Result is rate limiter decision for single request.
+// Reset is duration until the limit is fully restored / current window ends,
+// RetryAfter is only set when request is not allowed.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter decides whether request identified by key is allowed.
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+// HashedKey returns `prefix:<hex sha256 of value>` key, so secrets like API keys
+// don't end up in store key names or logs.
+func HashedKey(prefix, value string) string {
+	sum := sha256.Sum256([]byte(value))
+
+	return prefix + ":" + hex.EncodeToString(sum[:])
+}
+
+func tokenBucketResult(l Limit, tokens float64, allowed bool) Result {
+	interval := float64(l.tokenInterval())
+
+	r := Result{
+		Allowed:   allowed,
+		Limit:     l.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(l.Burst) - tokens) * interval),
+	}
+
+	if !allowed {
+		r.RetryAfter = time.Duration((1 - tokens) * interval)
+	}
+
+	return r
+}
+
+// slidingWindowWeight returns previous window weight & elapsed duration in current window.
+func slidingWindowWeight(l Limit, now time.Time) (float64, time.Duration) {
+	elapsed := now.Sub(now.Truncate(l.Period))
+
+	return 1 - float64(elapsed)/float64(l.Period), elapsed
+}
+
+func slidingWindowResult(l Limit, now time.Time, cur, prev int, allowed bool) Result {
+	weight, elapsed := slidingWindowWeight(l, now)
+	estimated := float64(prev)*weight + float64(cur)
+
+	r := Result{
+		Allowed:   allowed,
+		Limit:     l.Rate,
+		Remaining: l.Rate - int(math.Ceil(estimated)),
+		Reset:     l.Period - elapsed,
+	}
+
+	if r.Remaining < 0 {
+		r.Remaining = 0
+	}
+
+	if allowed {
+		return r
+	}
+
+	// wait until previous window weight drops enough for one more request
+	available := l.Rate - cur - 1
+	if available >= 0 && prev > 0 {
+		r.RetryAfter = slidingWindowWait(l, available, prev) - elapsed
+		return r
+	}
+
+	// current window is full, it becomes previous window of the next one
+	r.RetryAfter = l.Period - elapsed + slidingWindowWait(l, l.Rate-1, cur)
+
+	return r
+}
+
+// slidingWindowWait returns elapsed duration in a window needed
+// for prev count weight to drop below available requests.
+func slidingWindowWait(l Limit, available, prev int) time.Duration {
+	if prev <= available {
+		return 0
+	}
+
+	return time.Duration(float64(l.Period) * (1 - float64(available)/float64(prev)))
+}
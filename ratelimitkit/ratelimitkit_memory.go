@@ -0,0 +1,124 @@
+package ratelimitkit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// NewMemoryLimiter returns in-process Limiter,
+// suitable for single instance app. It returns ErrInvalidLimit when l.Rate is not positive.
+func NewMemoryLimiter(alg Algorithm, l Limit) (Limiter, error) {
+	l, err := l.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	return &memoryLimiter{
+		alg:     alg,
+		limit:   l,
+		buckets: make(map[string]*memoryState),
+	}, nil
+}
+
+type memoryLimiter struct {
+	mu        sync.Mutex
+	alg       Algorithm
+	limit     Limit
+	buckets   map[string]*memoryState
+	lastSweep time.Time
+}
+
+type memoryState struct {
+	lastSeen time.Time
+
+	// token bucket
+	tokens float64
+
+	// sliding window
+	windowStart time.Time
+	cur         int
+	prev        int
+}
+
+// Allow implements Limiter.
+func (m *memoryLimiter) Allow(_ context.Context, key string) (Result, error) {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(now)
+
+	s, ok := m.buckets[key]
+	if !ok {
+		s = &memoryState{
+			lastSeen:    now,
+			tokens:      float64(m.limit.Burst),
+			windowStart: now.Truncate(m.limit.Period),
+		}
+		m.buckets[key] = s
+	}
+
+	if m.alg == SlidingWindow {
+		return m.allowSlidingWindow(s, now), nil
+	}
+
+	return m.allowTokenBucket(s, now), nil
+}
+
+func (m *memoryLimiter) allowTokenBucket(s *memoryState, now time.Time) Result {
+	elapsed := now.Sub(s.lastSeen)
+	s.tokens = math.Min(float64(m.limit.Burst), s.tokens+float64(elapsed)/float64(m.limit.tokenInterval()))
+	s.lastSeen = now
+
+	allowed := s.tokens >= 1
+	if allowed {
+		s.tokens--
+	}
+
+	return tokenBucketResult(m.limit, s.tokens, allowed)
+}
+
+func (m *memoryLimiter) allowSlidingWindow(s *memoryState, now time.Time) Result {
+	windowStart := now.Truncate(m.limit.Period)
+
+	if !windowStart.Equal(s.windowStart) {
+		if windowStart.Sub(s.windowStart) == m.limit.Period {
+			s.prev = s.cur
+		} else {
+			s.prev = 0
+		}
+
+		s.cur = 0
+		s.windowStart = windowStart
+	}
+
+	s.lastSeen = now
+
+	weight, _ := slidingWindowWeight(m.limit, now)
+
+	allowed := float64(s.prev)*weight+float64(s.cur)+1 <= float64(m.limit.Rate)
+	if allowed {
+		s.cur++
+	}
+
+	return slidingWindowResult(m.limit, now, s.cur, s.prev, allowed)
+}
+
+// sweep removes idle keys, at most once per limit period.
+func (m *memoryLimiter) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < m.limit.Period {
+		return
+	}
+
+	m.lastSweep = now
+	ttl := m.limit.ttl()
+
+	for k, s := range m.buckets {
+		if now.Sub(s.lastSeen) > ttl {
+			delete(m.buckets, k)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package ratelimitkit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+const defaultRedisKeyPrefix = "ratelimit:"
+
+// KEYS[1] bucket hash
+// ARGV[1] token interval (ms, float), ARGV[2] burst, ARGV[3] now (ms), ARGV[4] ttl (ms).
+var tokenBucketScript = goredis.NewScript(`
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) / interval)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+
+return {allowed, tostring(tokens)}
+`)
+
+// KEYS[1] current window counter, KEYS[2] previous window counter
+// ARGV[1] rate, ARGV[2] previous window weight, ARGV[3] ttl (ms).
+var slidingWindowScript = goredis.NewScript(`
+local rate = tonumber(ARGV[1])
+local weight = tonumber(ARGV[2])
+
+local cur = tonumber(redis.call('GET', KEYS[1]) or '0')
+local prev = tonumber(redis.call('GET', KEYS[2]) or '0')
+
+if prev * weight + cur + 1 > rate then
+	return {0, cur, prev}
+end
+
+cur = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+
+return {1, cur, prev}
+`)
+
+type redisOptions struct {
+	keyPrefix string
+}
+
+// RedisOption sets options for redis Limiter.
+type RedisOption func(*redisOptions)
+
+// WithKeyPrefix returns RedisOption which sets redis key prefix,
+// default to `ratelimit:`.
+func WithKeyPrefix(prefix string) RedisOption {
+	return func(o *redisOptions) {
+		if prefix != "" {
+			o.keyPrefix = prefix
+		}
+	}
+}
+
+// NewRedisLimiter returns Limiter storing its state in redis using Lua script,
+// suitable for app running on multiple instances.
+// client is usually created using `rediskit.NewRedisConnection`.
+// It returns ErrInvalidLimit when l.Rate is not positive.
+func NewRedisLimiter(client goredis.Scripter, alg Algorithm, l Limit, o ...RedisOption) (Limiter, error) {
+	l, err := l.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := redisOptions{
+		keyPrefix: defaultRedisKeyPrefix,
+	}
+
+	for _, o := range o {
+		o(&opts)
+	}
+
+	return &redisLimiter{
+		client:    client,
+		alg:       alg,
+		limit:     l,
+		keyPrefix: opts.keyPrefix,
+	}, nil
+}
+
+type redisLimiter struct {
+	client    goredis.Scripter
+	alg       Algorithm
+	limit     Limit
+	keyPrefix string
+}
+
+// Allow implements Limiter.
+func (r *redisLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	// hash tag keeps sliding window keys on the same redis cluster slot
+	key = r.keyPrefix + "{" + key + "}"
+	now := time.Now()
+
+	if r.alg == SlidingWindow {
+		return r.allowSlidingWindow(ctx, key, now)
+	}
+
+	return r.allowTokenBucket(ctx, key, now)
+}
+
+func (r *redisLimiter) allowTokenBucket(ctx context.Context, key string, now time.Time) (Result, error) {
+	interval := float64(r.limit.tokenInterval()) / float64(time.Millisecond)
+
+	res, err := tokenBucketScript.Run(ctx, r.client, []string{key},
+		strconv.FormatFloat(interval, 'f', -1, 64),
+		r.limit.Burst,
+		now.UnixMilli(),
+		r.limit.ttl().Milliseconds(),
+	).Slice()
+	if err != nil {
+		return Result{}, errors.Wrap(err, "ratelimitkit: token bucket script")
+	}
+
+	if len(res) != 2 {
+		return Result{}, errors.Wrapf(errUnexpectedScriptResult, "got %v", res)
+	}
+
+	tokensStr, _ := res[1].(string)
+
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return Result{}, errors.Wrap(err, "ratelimitkit: parse tokens")
+	}
+
+	return tokenBucketResult(r.limit, tokens, toInt(res[0]) == 1), nil
+}
+
+func (r *redisLimiter) allowSlidingWindow(ctx context.Context, key string, now time.Time) (Result, error) {
+	windowStart := now.Truncate(r.limit.Period)
+	curKey := key + ":" + strconv.FormatInt(windowStart.UnixMilli(), 10)
+	prevKey := key + ":" + strconv.FormatInt(windowStart.Add(-r.limit.Period).UnixMilli(), 10)
+
+	weight, _ := slidingWindowWeight(r.limit, now)
+
+	res, err := slidingWindowScript.Run(ctx, r.client, []string{curKey, prevKey},
+		r.limit.Rate,
+		strconv.FormatFloat(weight, 'f', -1, 64),
+		r.limit.ttl().Milliseconds(),
+	).Slice()
+	if err != nil {
+		return Result{}, errors.Wrap(err, "ratelimitkit: sliding window script")
+	}
+
+	if len(res) != 3 {
+		return Result{}, errors.Wrapf(errUnexpectedScriptResult, "got %v", res)
+	}
+
+	return slidingWindowResult(r.limit, now, toInt(res[1]), toInt(res[2]), toInt(res[0]) == 1), nil
+}
+
+var errUnexpectedScriptResult = errors.New("ratelimitkit: unexpected script result")
+
+func toInt(v interface{}) int {
+	switch n := v.(type) {
+	case int64:
+		return int(n)
+	case string:
+		i, _ := strconv.Atoi(n)
+		return i
+	default:
+		return 0
+	}
+}
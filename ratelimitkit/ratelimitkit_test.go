@@ -0,0 +1,126 @@
+package ratelimitkit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/ratelimitkit"
+)
+
+func newLimiters(t *testing.T, alg ratelimitkit.Algorithm, l ratelimitkit.Limit) map[string]ratelimitkit.Limiter {
+	t.Helper()
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(mr.Close)
+
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	memory, err := ratelimitkit.NewMemoryLimiter(alg, l)
+	require.NoError(t, err)
+
+	redis, err := ratelimitkit.NewRedisLimiter(client, alg, l)
+	require.NoError(t, err)
+
+	return map[string]ratelimitkit.Limiter{
+		"memory": memory,
+		"redis":  redis,
+	}
+}
+
+func TestInvalidLimit(t *testing.T) {
+	for _, rate := range []int{0, -1} {
+		_, err := ratelimitkit.NewMemoryLimiter(ratelimitkit.TokenBucket, ratelimitkit.Limit{Rate: rate})
+		assert.ErrorIs(t, err, ratelimitkit.ErrInvalidLimit)
+
+		_, err = ratelimitkit.NewRedisLimiter(goredis.NewClient(&goredis.Options{}), ratelimitkit.SlidingWindow, ratelimitkit.PerSecond(rate))
+		assert.ErrorIs(t, err, ratelimitkit.ErrInvalidLimit)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	limit := ratelimitkit.Limit{Rate: 10, Period: 500 * time.Millisecond, Burst: 3}
+
+	for name, limiter := range newLimiters(t, ratelimitkit.TokenBucket, limit) {
+		limiter := limiter
+
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			for i := 0; i < 3; i++ {
+				res, err := limiter.Allow(ctx, "client-1")
+				assert.NoError(t, err)
+				assert.True(t, res.Allowed, "burst request %d should be allowed", i)
+				assert.Equal(t, 3, res.Limit)
+				assert.Equal(t, 2-i, res.Remaining)
+			}
+
+			res, err := limiter.Allow(ctx, "client-1")
+			assert.NoError(t, err)
+			assert.False(t, res.Allowed)
+			assert.Greater(t, res.RetryAfter, time.Duration(0))
+			assert.LessOrEqual(t, res.RetryAfter, 50*time.Millisecond)
+
+			// other key has its own bucket
+			res, err = limiter.Allow(ctx, "client-2")
+			assert.NoError(t, err)
+			assert.True(t, res.Allowed)
+
+			// one token refilled every 50ms
+			time.Sleep(60 * time.Millisecond)
+
+			res, err = limiter.Allow(ctx, "client-1")
+			assert.NoError(t, err)
+			assert.True(t, res.Allowed)
+		})
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	limit := ratelimitkit.Limit{Rate: 3, Period: 200 * time.Millisecond}
+
+	for name, limiter := range newLimiters(t, ratelimitkit.SlidingWindow, limit) {
+		limiter := limiter
+
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			// start at the beginning of a window, so all requests land in the same one
+			time.Sleep(time.Until(time.Now().Truncate(limit.Period).Add(limit.Period)))
+
+			for i := 0; i < 3; i++ {
+				res, err := limiter.Allow(ctx, "client-1")
+				assert.NoError(t, err)
+				assert.True(t, res.Allowed, "request %d should be allowed", i)
+				assert.Equal(t, 3, res.Limit)
+			}
+
+			res, err := limiter.Allow(ctx, "client-1")
+			assert.NoError(t, err)
+			assert.False(t, res.Allowed)
+			assert.Equal(t, 0, res.Remaining)
+			assert.Greater(t, res.RetryAfter, time.Duration(0))
+			assert.LessOrEqual(t, res.RetryAfter, 2*limit.Period)
+
+			// previous window requests still counted partially
+			time.Sleep(res.RetryAfter + 10*time.Millisecond)
+
+			res, err = limiter.Allow(ctx, "client-1")
+			assert.NoError(t, err)
+			assert.True(t, res.Allowed)
+		})
+	}
+}
@@ -2,11 +2,13 @@
 package runtimekit
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"runtime"
+	"strconv"
 	"strings"
 	"syscall"
 )
@@ -74,3 +76,58 @@ func SkippedFunctionName(skip int) string {
 
 	return result
 }
+
+// GoroutineID returns current goroutine id, parsed from `goroutine 123 [running]:` stack header.
+// returns 0 if id can't be parsed.
+func GoroutineID() uint64 {
+	buf := make([]byte, goroutineHeaderSize)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, goroutinePrefix)
+
+	idx := bytes.IndexByte(buf, ' ')
+	if idx < 0 {
+		return 0
+	}
+
+	id, err := strconv.ParseUint(string(buf[:idx]), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return id
+}
+
+// GoroutineStack returns stack trace of goroutine with given id.
+// it's expensive (stops the world), only use it for diagnostic purposes
+// e.g. logging stuck / abandoned request handler.
+func GoroutineStack(id uint64) string {
+	buf := make([]byte, goroutineStackInitSize)
+
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= goroutineStackMaxSize {
+			buf = buf[:n]
+			break
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+
+	header := []byte(fmt.Sprintf("goroutine %d [", id))
+
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(stack, header) {
+			return string(stack)
+		}
+	}
+
+	return ""
+}
+
+const (
+	goroutineHeaderSize    = 64
+	goroutineStackInitSize = 64 << 10
+	goroutineStackMaxSize  = 8 << 20
+)
+
+var goroutinePrefix = []byte("goroutine ")
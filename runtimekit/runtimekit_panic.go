@@ -0,0 +1,37 @@
+package runtimekit
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is error of recovered panic value with its stack trace.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// NewPanicError returns *PanicError of recovered value r,
+// call it from the deferred function so Stack includes the panicking frame.
+func NewPanicError(r interface{}) *PanicError {
+	return &PanicError{
+		Value: r,
+		Stack: debug.Stack(),
+	}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("recovered from panic: %v", e.Value)
+}
+
+// Unwrap returns recovered value if it's an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+
+	return err
+}
+
+// PanicReporter reports recovered panic, e.g. to error tracker or APM agent.
+// ctx is the recovered request context.
+type PanicReporter func(ctx context.Context, err *PanicError)
@@ -0,0 +1,363 @@
+// Package tenantkit provides multi-tenant request context
+// for echo, gRPC, http client & pubsub
+package tenantkit
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/adipurnama/go-toolkit/authkit"
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+const (
+	// HTTPKeyTenantID is tenant ID request header, also used to propagate tenant to outgoing http calls.
+	HTTPKeyTenantID = "X-Tenant-ID"
+	// MetadataKeyTenantID is tenant ID gRPC metadata key.
+	MetadataKeyTenantID = "x-tenant-id"
+	// AttributeTenantID is tenant ID pubsub message attribute.
+	AttributeTenantID = "tenant_id"
+
+	defaultSchemaPrefix = "tenant_"
+
+	defaultCacheTTL        = time.Minute
+	defaultCacheMaxEntries = 10000
+	defaultCacheMaxUnknown = 1000
+)
+
+var (
+	// ErrTenantMissing returned when request contains no tenant ID.
+	ErrTenantMissing = errors.New("tenantkit: tenant id is missing")
+	// ErrTenantUnknown returned by Resolver when tenant ID isn't registered or is disabled.
+	ErrTenantUnknown = errors.New("tenantkit: unknown tenant")
+
+	// contextKeyTenant to store/obtains resolved *Tenant to/from request's context.
+	contextKeyTenant = web.ContextKey("tenant")
+
+	invalidSchemaChars = regexp.MustCompile(`[^a-z0-9_]+`)
+)
+
+// Tenant is resolved tenant of a request.
+// Schema is tenant's SQL schema, defaults to `tenant_<id>` see SchemaName.
+type Tenant struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name,omitempty"`
+	Schema     string            `json:"schema,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// RedisKey returns tenant scoped redis key `tenant:<id>:<parts...>`,
+// so tenants sharing a redis instance never read each other's keys.
+func (t *Tenant) RedisKey(parts ...string) string {
+	return "tenant:" + t.ID + ":" + strings.Join(parts, ":")
+}
+
+// SchemaName returns tenant's SQL schema name, Schema if set,
+// otherwise `tenant_<id>` with characters other than [a-z0-9_] replaced by `_`.
+func (t *Tenant) SchemaName() string {
+	if t.Schema != "" {
+		return t.Schema
+	}
+
+	return defaultSchemaPrefix + invalidSchemaChars.ReplaceAllString(strings.ToLower(t.ID), "_")
+}
+
+// QualifiedTable returns quoted, schema qualified table name e.g. `"tenant_acme"."orders"`.
+func (t *Tenant) QualifiedTable(table string) string {
+	return quoteIdent(t.SchemaName()) + "." + quoteIdent(table)
+}
+
+// SearchPathQuery returns postgres statement setting session's search_path to tenant's schema.
+// Prefer SET LOCAL inside transaction, pooled connection keeps session setting after use.
+func (t *Tenant) SearchPathQuery(local bool) string {
+	if local {
+		return "SET LOCAL search_path TO " + quoteIdent(t.SchemaName())
+	}
+
+	return "SET search_path TO " + quoteIdent(t.SchemaName())
+}
+
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// NewContext returns copy of ctx containing t,
+// tenant ID is added to ctx's logger.
+func NewContext(ctx context.Context, t *Tenant) context.Context {
+	ctx = context.WithValue(ctx, contextKeyTenant, t)
+
+	logger := log.FromCtx(ctx)
+	logger.AddField("tenant_id", t.ID)
+
+	return log.AddToContext(ctx, logger)
+}
+
+// FromContext returns resolved tenant from ctx.
+func FromContext(ctx context.Context) (*Tenant, bool) {
+	t, ok := ctx.Value(contextKeyTenant).(*Tenant)
+
+	return t, ok && t != nil
+}
+
+// IDFromContext returns resolved tenant ID from ctx, empty if there's none.
+func IDFromContext(ctx context.Context) string {
+	if t, ok := FromContext(ctx); ok {
+		return t.ID
+	}
+
+	return ""
+}
+
+// RedisKey returns tenant scoped redis key of ctx's tenant, see Tenant.RedisKey.
+// It returns ErrTenantMissing when ctx has no tenant instead of falling back to a shared key.
+func RedisKey(ctx context.Context, parts ...string) (string, error) {
+	t, ok := FromContext(ctx)
+	if !ok {
+		return "", errors.WithStack(ErrTenantMissing)
+	}
+
+	return t.RedisKey(parts...), nil
+}
+
+// Resolver validates tenant ID & returns its Tenant.
+// It should return ErrTenantUnknown for unregistered or disabled tenant.
+type Resolver interface {
+	Resolve(ctx context.Context, id string) (*Tenant, error)
+}
+
+// ResolverFunc adapts function into Resolver.
+type ResolverFunc func(ctx context.Context, id string) (*Tenant, error)
+
+// Resolve implements Resolver interface.
+func (f ResolverFunc) Resolve(ctx context.Context, id string) (*Tenant, error) {
+	return f(ctx, id)
+}
+
+// StaticResolver resolves tenants from fixed list, keyed by Tenant.ID.
+type StaticResolver map[string]*Tenant
+
+// NewStaticResolver returns StaticResolver of tenants.
+func NewStaticResolver(tenants ...*Tenant) StaticResolver {
+	r := make(StaticResolver, len(tenants))
+	for _, t := range tenants {
+		r[t.ID] = t
+	}
+
+	return r
+}
+
+// Resolve implements Resolver interface.
+func (r StaticResolver) Resolve(_ context.Context, id string) (*Tenant, error) {
+	if t, ok := r[id]; ok {
+		return t, nil
+	}
+
+	return nil, errors.Wrapf(ErrTenantUnknown, "tenant %s", id)
+}
+
+// CachedResolverConfig is CachedResolver configuration
+// default value:
+//   - TTL: 1 minute
+//   - MaxEntries: 10000 cached tenants, random tenant is evicted when it's full
+//   - MaxUnknown: 1000 cached unknown tenant IDs, kept apart from MaxEntries
+//     as unknown IDs come from clients & may be random
+type CachedResolverConfig struct {
+	TTL        time.Duration `json:"ttl,omitempty"`
+	MaxEntries int           `json:"max_entries,omitempty"`
+	MaxUnknown int           `json:"max_unknown,omitempty"`
+}
+
+// CachedResolver caches Resolver results in memory for ttl.
+// Unknown tenants are cached as well, so unknown IDs won't hit the underlying resolver on every request.
+// Cache size is bounded by CachedResolverConfig.MaxEntries & CachedResolverConfig.MaxUnknown,
+// expired entries are swept at most once per ttl.
+type CachedResolver struct {
+	resolver Resolver
+	cfg      CachedResolverConfig
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	entries map[string]cacheEntry
+	unknown map[string]cacheEntry
+	sweptAt time.Time
+}
+
+type cacheEntry struct {
+	tenant    *Tenant
+	err       error
+	expiresAt time.Time
+}
+
+// NewCachedResolver returns r with in memory cache, ttl defaults to 1 minute.
+// See NewCachedResolverWithConfig for cache size limits.
+func NewCachedResolver(r Resolver, ttl time.Duration) *CachedResolver {
+	return NewCachedResolverWithConfig(r, &CachedResolverConfig{TTL: ttl})
+}
+
+// NewCachedResolverWithConfig returns r with in memory cache configured by cfg.
+func NewCachedResolverWithConfig(r Resolver, cfg *CachedResolverConfig) *CachedResolver {
+	if r == nil {
+		panic("tenantkit: resolver cannot be nil")
+	}
+
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultCacheTTL
+	}
+
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = defaultCacheMaxEntries
+	}
+
+	if cfg.MaxUnknown <= 0 {
+		cfg.MaxUnknown = defaultCacheMaxUnknown
+	}
+
+	return &CachedResolver{
+		resolver: r,
+		cfg:      *cfg,
+		now:      time.Now,
+		entries:  make(map[string]cacheEntry),
+		unknown:  make(map[string]cacheEntry),
+	}
+}
+
+// Resolve implements Resolver interface.
+// Errors other than ErrTenantUnknown, e.g. resolver backend down, are not cached.
+func (c *CachedResolver) Resolve(ctx context.Context, id string) (*Tenant, error) {
+	now := c.now()
+
+	c.mu.RLock()
+	e, ok := c.entries[id]
+	if !ok {
+		e, ok = c.unknown[id]
+	}
+
+	c.mu.RUnlock()
+
+	if ok && now.Before(e.expiresAt) {
+		return e.tenant, e.err
+	}
+
+	t, err := c.resolver.Resolve(ctx, id)
+	if err != nil && !errors.Is(err, ErrTenantUnknown) {
+		return nil, err
+	}
+
+	e = cacheEntry{tenant: t, err: err, expiresAt: now.Add(c.cfg.TTL)}
+
+	c.mu.Lock()
+	c.sweepExpired(now)
+
+	if err != nil {
+		putCacheEntry(c.unknown, id, e, c.cfg.MaxUnknown)
+	} else {
+		putCacheEntry(c.entries, id, e, c.cfg.MaxEntries)
+	}
+
+	c.mu.Unlock()
+
+	return t, err
+}
+
+// Len returns number of cached tenants & unknown tenant IDs, including expired ones not swept yet.
+func (c *CachedResolver) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.entries) + len(c.unknown)
+}
+
+// Invalidate removes tenant id from cache, e.g. after tenant is disabled.
+func (c *CachedResolver) Invalidate(id string) {
+	c.mu.Lock()
+	delete(c.entries, id)
+	delete(c.unknown, id)
+	c.mu.Unlock()
+}
+
+// sweepExpired removes expired entries at most once per ttl, c.mu must be held.
+func (c *CachedResolver) sweepExpired(now time.Time) {
+	if now.Sub(c.sweptAt) < c.cfg.TTL {
+		return
+	}
+
+	c.sweptAt = now
+
+	for _, m := range []map[string]cacheEntry{c.entries, c.unknown} {
+		for id, e := range m {
+			if !now.Before(e.expiresAt) {
+				delete(m, id)
+			}
+		}
+	}
+}
+
+// putCacheEntry stores e in m, evicting a random entry when m has max entries.
+func putCacheEntry(m map[string]cacheEntry, id string, e cacheEntry, max int) {
+	if _, ok := m[id]; !ok && len(m) >= max {
+		// map iteration order is random
+		for evicted := range m {
+			delete(m, evicted)
+			break
+		}
+	}
+
+	m[id] = e
+}
+
+// resolve validates tenant id using r, returning ErrTenantMissing on empty id.
+func resolve(ctx context.Context, r Resolver, id string) (*Tenant, error) {
+	if id == "" {
+		return nil, errors.WithStack(ErrTenantMissing)
+	}
+
+	t, err := r.Resolve(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if t == nil {
+		return nil, errors.Wrapf(ErrTenantUnknown, "tenant %s", id)
+	}
+
+	return t, nil
+}
+
+// toAppError maps resolve error into errors.AppError:
+// missing tenant is 400 InvalidArgument, unknown tenant is 403 PermissionDenied
+// and any other resolver failure is 503 Unavailable.
+func toAppError(err error) *apperrors.AppError {
+	switch {
+	case errors.Is(err, ErrTenantMissing):
+		return apperrors.Wrap(err, apperrors.CodeInvalidArgument).WithDetail("reason", "tenant id is missing")
+	case errors.Is(err, ErrTenantUnknown):
+		return apperrors.Wrap(err, apperrors.CodePermissionDenied).WithDetail("reason", "unknown tenant")
+	default:
+		return apperrors.Wrap(err, apperrors.CodeUnavailable)
+	}
+}
+
+// claimValue returns string claim of verified authkit.Claims in ctx.
+func claimValue(ctx context.Context, claim string) string {
+	c, ok := authkit.ClaimsFromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	switch v := c.Raw[claim].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
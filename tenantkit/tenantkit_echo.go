@@ -0,0 +1,114 @@
+package tenantkit
+
+import (
+	"net"
+	"strings"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"github.com/adipurnama/go-toolkit/log"
+)
+
+// EchoExtractor returns tenant ID of echo request, empty if not found.
+type EchoExtractor func(ctx echo.Context) string
+
+// HeaderExtractor extracts tenant ID from request header, e.g. HTTPKeyTenantID.
+func HeaderExtractor(header string) EchoExtractor {
+	return func(ctx echo.Context) string {
+		return strings.TrimSpace(ctx.Request().Header.Get(header))
+	}
+}
+
+// SubdomainExtractor extracts tenant ID from request host subdomain of baseDomain,
+// e.g. `acme` of `acme.shop.example.com` with baseDomain `shop.example.com`.
+// Nested subdomain e.g. `a.acme.shop.example.com` isn't a tenant.
+func SubdomainExtractor(baseDomain string) EchoExtractor {
+	suffix := "." + strings.ToLower(strings.Trim(baseDomain, "."))
+
+	return func(ctx echo.Context) string {
+		host := ctx.Request().Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+
+		host = strings.ToLower(host)
+		if !strings.HasSuffix(host, suffix) {
+			return ""
+		}
+
+		sub := strings.TrimSuffix(host, suffix)
+		if strings.Contains(sub, ".") {
+			return ""
+		}
+
+		return sub
+	}
+}
+
+// EchoClaimExtractor extracts tenant ID from verified JWT claim, see authkit.EchoMiddleware.
+// It must be registered after authkit.EchoMiddleware.
+func EchoClaimExtractor(claim string) EchoExtractor {
+	return func(ctx echo.Context) string {
+		return claimValue(ctx.Request().Context(), claim)
+	}
+}
+
+// PathParamExtractor extracts tenant ID from route path param, e.g. `tenant` of `/t/:tenant/orders`.
+// Path params are known after routing, so register the middleware as route or group middleware.
+func PathParamExtractor(name string) EchoExtractor {
+	return func(ctx echo.Context) string {
+		return ctx.Param(name)
+	}
+}
+
+// EchoMiddleware resolves request's tenant using r and stores it in request's context, see FromContext.
+// Tenant ID is taken from the first extractor returning non empty value,
+// extractors default to HeaderExtractor(HTTPKeyTenantID).
+//
+// Put trusted source first, e.g. EchoClaimExtractor before HeaderExtractor,
+// so clients can't switch tenant using a header.
+// It returns 400 when tenant ID is missing, 403 for unknown tenant.
+func EchoMiddleware(r Resolver, skipper middleware.Skipper, extractors ...EchoExtractor) echo.MiddlewareFunc {
+	if r == nil {
+		panic("tenantkit: resolver cannot be nil")
+	}
+
+	if skipper == nil {
+		skipper = middleware.DefaultSkipper
+	}
+
+	if len(extractors) == 0 {
+		extractors = []EchoExtractor{HeaderExtractor(HTTPKeyTenantID)}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if skipper(ctx) {
+				return next(ctx)
+			}
+
+			var id string
+
+			for _, extract := range extractors {
+				if id = extract(ctx); id != "" {
+					break
+				}
+			}
+
+			req := ctx.Request()
+			rCtx := req.Context()
+
+			t, err := resolve(rCtx, r, id)
+			if err != nil {
+				log.FromCtx(rCtx).Debug("tenant rejected", "tenant_id", id, "error", err.Error())
+
+				return toAppError(err)
+			}
+
+			ctx.SetRequest(req.WithContext(NewContext(rCtx, t)))
+
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package tenantkit
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/adipurnama/go-toolkit/internal/grpcutil"
+	"github.com/adipurnama/go-toolkit/log"
+)
+
+// GRPCExtractor returns tenant ID of gRPC call, empty if not found.
+type GRPCExtractor func(ctx context.Context, fullMethod string) string
+
+// MetadataExtractor extracts tenant ID from incoming metadata key, e.g. MetadataKeyTenantID.
+func MetadataExtractor(key string) GRPCExtractor {
+	return func(ctx context.Context, _ string) string {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return ""
+		}
+
+		if vals := md.Get(key); len(vals) > 0 {
+			return strings.TrimSpace(vals[0])
+		}
+
+		return ""
+	}
+}
+
+// GRPCClaimExtractor extracts tenant ID from verified JWT claim,
+// it must be chained after authkit.UnaryServerInterceptor / authkit.StreamServerInterceptor.
+func GRPCClaimExtractor(claim string) GRPCExtractor {
+	return func(ctx context.Context, _ string) string {
+		return claimValue(ctx, claim)
+	}
+}
+
+// UnaryServerInterceptor resolves call's tenant using r and stores it in context, see FromContext.
+// Tenant ID is taken from the first extractor returning non empty value,
+// extractors default to MetadataExtractor(MetadataKeyTenantID). gRPC health check service is skipped.
+// It returns InvalidArgument when tenant ID is missing, PermissionDenied for unknown tenant.
+func UnaryServerInterceptor(r Resolver, extractors ...GRPCExtractor) grpc.UnaryServerInterceptor {
+	if r == nil {
+		panic("tenantkit: resolver cannot be nil")
+	}
+
+	extractors = defaultGRPCExtractors(extractors)
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		ctx, err = resolveGRPC(ctx, r, extractors, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is stream version of UnaryServerInterceptor.
+func StreamServerInterceptor(r Resolver, extractors ...GRPCExtractor) grpc.StreamServerInterceptor {
+	if r == nil {
+		panic("tenantkit: resolver cannot be nil")
+	}
+
+	extractors = defaultGRPCExtractors(extractors)
+
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx, err := resolveGRPC(ss.Context(), r, extractors, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, grpcutil.WrapServerStream(ctx, ss))
+	}
+}
+
+// UnaryClientInterceptor propagates context's tenant ID to outgoing call metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		return invoker(outgoingContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor is stream version of UnaryClientInterceptor.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		return streamer(outgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
+
+func defaultGRPCExtractors(extractors []GRPCExtractor) []GRPCExtractor {
+	if len(extractors) == 0 {
+		return []GRPCExtractor{MetadataExtractor(MetadataKeyTenantID)}
+	}
+
+	return extractors
+}
+
+func resolveGRPC(ctx context.Context, r Resolver, extractors []GRPCExtractor, fullMethod string) (context.Context, error) {
+	if grpcutil.IsHealthCheck(fullMethod) {
+		return ctx, nil
+	}
+
+	var id string
+
+	for _, extract := range extractors {
+		if id = extract(ctx, fullMethod); id != "" {
+			break
+		}
+	}
+
+	t, err := resolve(ctx, r, id)
+	if err != nil {
+		log.FromCtx(ctx).Debug("tenant rejected", "method", fullMethod, "tenant_id", id, "error", err.Error())
+
+		return ctx, toAppError(err)
+	}
+
+	return NewContext(ctx, t), nil
+}
+
+func outgoingContext(ctx context.Context) context.Context {
+	id := IDFromContext(ctx)
+	if id == "" {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, MetadataKeyTenantID, id)
+}
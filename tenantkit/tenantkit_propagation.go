@@ -0,0 +1,71 @@
+package tenantkit
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/HereMobilityDevelopers/mediary"
+	"github.com/pkg/errors"
+
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/pubsubkit"
+)
+
+// HTTPClientMiddleware is mediary http client interceptor propagating request context's
+// tenant ID as HTTPKeyTenantID header, unless it's already set. e.g.
+//
+//	client := mediary.Init().AddInterceptors(tenantkit.HTTPClientMiddleware).Build()
+func HTTPClientMiddleware(req *http.Request, handler mediary.Handler) (*http.Response, error) {
+	if id := IDFromContext(req.Context()); id != "" && req.Header.Get(HTTPKeyTenantID) == "" {
+		// mediary passes the original request down the chain, so header is set in place
+		req.Header.Set(HTTPKeyTenantID, id)
+	}
+
+	return handler(req)
+}
+
+// PubSubAttributes returns attrs with ctx's tenant ID set as AttributeTenantID,
+// attrs is created when nil. Use it when building *pubsub.Message to publish:
+//
+//	topic.Publish(ctx, &pubsub.Message{Data: data, Attributes: tenantkit.PubSubAttributes(ctx, nil)})
+func PubSubAttributes(ctx context.Context, attrs map[string]string) map[string]string {
+	id := IDFromContext(ctx)
+	if id == "" {
+		return attrs
+	}
+
+	if attrs == nil {
+		attrs = make(map[string]string, 1)
+	}
+
+	attrs[AttributeTenantID] = id
+
+	return attrs
+}
+
+// PubSubWorkerMiddleware resolves message's tenant from AttributeTenantID attribute using r
+// before calling next, see pubsubkit.ReceiveSubscription.
+// Message without tenant or with unknown tenant is logged & acked since redelivery won't fix it,
+// other resolver errors are returned so the message is redelivered.
+func PubSubWorkerMiddleware(r Resolver, next pubsubkit.WorkerHandlerFunc) pubsubkit.WorkerHandlerFunc {
+	if r == nil {
+		panic("tenantkit: resolver cannot be nil")
+	}
+
+	return func(ctx context.Context, msg pubsubkit.Message) error {
+		id := msg.Attributes()[AttributeTenantID]
+
+		t, err := resolve(ctx, r, id)
+		if err != nil {
+			if errors.Is(err, ErrTenantMissing) || errors.Is(err, ErrTenantUnknown) {
+				log.FromCtx(ctx).Error(err, "message tenant rejected, message skipped", "msg_id", msg.ID(), "tenant_id", id)
+
+				return nil
+			}
+
+			return err
+		}
+
+		return next(NewContext(ctx, t), msg)
+	}
+}
@@ -0,0 +1,305 @@
+package tenantkit_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HereMobilityDevelopers/mediary"
+	echo "github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/adipurnama/go-toolkit/authkit"
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+	"github.com/adipurnama/go-toolkit/pubsubkit"
+	"github.com/adipurnama/go-toolkit/tenantkit"
+)
+
+var acme = &tenantkit.Tenant{ID: "acme", Name: "Acme Corp"}
+
+func TestTenantHelpers(t *testing.T) {
+	assert.Equal(t, "tenant:acme:orders:1", acme.RedisKey("orders", "1"))
+	assert.Equal(t, "tenant_acme_co", (&tenantkit.Tenant{ID: "Acme-Co"}).SchemaName())
+	assert.Equal(t, `"custom"."orders"`, (&tenantkit.Tenant{ID: "x", Schema: "custom"}).QualifiedTable("orders"))
+	assert.Equal(t, `SET LOCAL search_path TO "tenant_acme"`, acme.SearchPathQuery(true))
+	assert.Equal(t, `SET search_path TO "tenant_acme"`, acme.SearchPathQuery(false))
+
+	_, err := tenantkit.RedisKey(context.Background(), "orders")
+	assert.ErrorIs(t, err, tenantkit.ErrTenantMissing)
+
+	key, err := tenantkit.RedisKey(tenantkit.NewContext(context.Background(), acme), "orders")
+	require.NoError(t, err)
+	assert.Equal(t, "tenant:acme:orders", key)
+}
+
+func TestCachedResolver(t *testing.T) {
+	var calls int32
+
+	backend := tenantkit.ResolverFunc(func(ctx context.Context, id string) (*tenantkit.Tenant, error) {
+		atomic.AddInt32(&calls, 1)
+
+		switch id {
+		case "acme":
+			return acme, nil
+		case "down":
+			return nil, errors.New("connection refused")
+		default:
+			return nil, tenantkit.ErrTenantUnknown
+		}
+	})
+
+	r := tenantkit.NewCachedResolver(backend, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		got, err := r.Resolve(ctx, "acme")
+		require.NoError(t, err)
+		assert.Equal(t, acme, got)
+
+		_, err = r.Resolve(ctx, "ghost")
+		assert.ErrorIs(t, err, tenantkit.ErrTenantUnknown)
+	}
+
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls), "known & unknown tenants are cached")
+
+	_, _ = r.Resolve(ctx, "down")
+	_, _ = r.Resolve(ctx, "down")
+	assert.EqualValues(t, 4, atomic.LoadInt32(&calls), "backend errors aren't cached")
+
+	r.Invalidate("acme")
+	_, _ = r.Resolve(ctx, "acme")
+	assert.EqualValues(t, 5, atomic.LoadInt32(&calls))
+}
+
+func TestCachedResolverBounded(t *testing.T) {
+	var calls int32
+
+	backend := tenantkit.ResolverFunc(func(ctx context.Context, id string) (*tenantkit.Tenant, error) {
+		atomic.AddInt32(&calls, 1)
+
+		if id == "acme" {
+			return acme, nil
+		}
+
+		return nil, tenantkit.ErrTenantUnknown
+	})
+
+	r := tenantkit.NewCachedResolverWithConfig(backend, &tenantkit.CachedResolverConfig{
+		TTL:        time.Minute,
+		MaxEntries: 10,
+		MaxUnknown: 5,
+	})
+	ctx := context.Background()
+
+	_, err := r.Resolve(ctx, "acme")
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		_, err := r.Resolve(ctx, fmt.Sprintf("random-%d", i))
+		assert.ErrorIs(t, err, tenantkit.ErrTenantUnknown)
+	}
+
+	assert.Equal(t, 6, r.Len(), "unknown IDs are capped")
+
+	before := atomic.LoadInt32(&calls)
+
+	_, err = r.Resolve(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, before, atomic.LoadInt32(&calls), "unknown IDs don't evict known tenants")
+}
+
+func TestEchoMiddleware(t *testing.T) {
+	resolver := tenantkit.NewStaticResolver(acme)
+
+	tests := []struct {
+		name       string
+		extractors []tenantkit.EchoExtractor
+		setup      func(req *http.Request)
+		wantCode   apperrors.Code
+	}{
+		{
+			name:  "default header",
+			setup: func(req *http.Request) { req.Header.Set(tenantkit.HTTPKeyTenantID, "acme") },
+		},
+		{
+			name:     "missing",
+			setup:    func(req *http.Request) {},
+			wantCode: apperrors.CodeInvalidArgument,
+		},
+		{
+			name:     "unknown",
+			setup:    func(req *http.Request) { req.Header.Set(tenantkit.HTTPKeyTenantID, "ghost") },
+			wantCode: apperrors.CodePermissionDenied,
+		},
+		{
+			name:       "subdomain",
+			extractors: []tenantkit.EchoExtractor{tenantkit.SubdomainExtractor("shop.example.com")},
+			setup:      func(req *http.Request) { req.Host = "ACME.shop.example.com:8080" },
+		},
+		{
+			name:       "nested subdomain",
+			extractors: []tenantkit.EchoExtractor{tenantkit.SubdomainExtractor("shop.example.com")},
+			setup:      func(req *http.Request) { req.Host = "x.acme.shop.example.com" },
+			wantCode:   apperrors.CodeInvalidArgument,
+		},
+		{
+			name: "claim takes precedence over header",
+			extractors: []tenantkit.EchoExtractor{
+				tenantkit.EchoClaimExtractor("tenant_id"),
+				tenantkit.HeaderExtractor(tenantkit.HTTPKeyTenantID),
+			},
+			setup: func(req *http.Request) {
+				claims := &authkit.Claims{Subject: "u1", Raw: map[string]interface{}{"tenant_id": "acme"}}
+				*req = *req.WithContext(authkit.NewContext(req.Context(), claims))
+				req.Header.Set(tenantkit.HTTPKeyTenantID, "ghost")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := tenantkit.EchoMiddleware(resolver, nil, tt.extractors...)(func(ctx echo.Context) error {
+				return ctx.String(http.StatusOK, tenantkit.IDFromContext(ctx.Request().Context()))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			tt.setup(req)
+
+			rec := httptest.NewRecorder()
+			err := handler(echo.New().NewContext(req, rec))
+
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, apperrors.CodeOf(err))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "acme", rec.Body.String())
+		})
+	}
+}
+
+func TestEchoMiddlewarePathParam(t *testing.T) {
+	e := echo.New()
+	g := e.Group("/t/:tenant", tenantkit.EchoMiddleware(
+		tenantkit.NewStaticResolver(acme), nil, tenantkit.PathParamExtractor("tenant")))
+	g.GET("/orders", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, tenantkit.IDFromContext(ctx.Request().Context()))
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/t/acme/orders", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "acme", rec.Body.String())
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := tenantkit.UnaryServerInterceptor(tenantkit.NewStaticResolver(acme))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return tenantkit.IDFromContext(ctx), nil
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		tenantID string
+		wantCode codes.Code
+	}{
+		{name: "known", method: "/order.v1.OrderService/Get", tenantID: "acme", wantCode: codes.OK},
+		{name: "missing", method: "/order.v1.OrderService/Get", wantCode: codes.InvalidArgument},
+		{name: "unknown", method: "/order.v1.OrderService/Get", tenantID: "ghost", wantCode: codes.PermissionDenied},
+		{name: "health check", method: "/grpc.health.v1.Health/Check", wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.tenantID != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(tenantkit.MetadataKeyTenantID, tt.tenantID))
+			}
+
+			resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, tt.tenantID, resp)
+			}
+		})
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	ctx := tenantkit.NewContext(context.Background(), acme)
+
+	err := tenantkit.UnaryClientInterceptor()(ctx, "/order.v1.OrderService/Get", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			assert.Equal(t, []string{"acme"}, md.Get(tenantkit.MetadataKeyTenantID))
+
+			return nil
+		})
+	require.NoError(t, err)
+}
+
+func TestHTTPClientMiddleware(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get(tenantkit.HTTPKeyTenantID)))
+	}))
+	defer srv.Close()
+
+	client := mediary.Init().AddInterceptors(tenantkit.HTTPClientMiddleware).Build()
+
+	req, err := http.NewRequestWithContext(tenantkit.NewContext(context.Background(), acme), http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	body := make([]byte, 8)
+	n, _ := resp.Body.Read(body)
+	assert.Equal(t, "acme", string(body[:n]))
+}
+
+type message struct {
+	pubsubkit.Message
+	attrs map[string]string
+}
+
+func (m message) ID() string                    { return "msg-1" }
+func (m message) Attributes() map[string]string { return m.attrs }
+
+func TestPubSubPropagation(t *testing.T) {
+	ctx := tenantkit.NewContext(context.Background(), acme)
+
+	attrs := tenantkit.PubSubAttributes(ctx, map[string]string{"type": "order.created"})
+	assert.Equal(t, map[string]string{"type": "order.created", tenantkit.AttributeTenantID: "acme"}, attrs)
+	assert.Nil(t, tenantkit.PubSubAttributes(context.Background(), nil))
+
+	var got string
+
+	handler := tenantkit.PubSubWorkerMiddleware(tenantkit.NewStaticResolver(acme), func(ctx context.Context, msg pubsubkit.Message) error {
+		got = tenantkit.IDFromContext(ctx)
+
+		return nil
+	})
+
+	require.NoError(t, handler(context.Background(), message{attrs: attrs}))
+	assert.Equal(t, "acme", got)
+
+	got = ""
+	require.NoError(t, handler(context.Background(), message{attrs: map[string]string{tenantkit.AttributeTenantID: "ghost"}}))
+	assert.Empty(t, got, "unknown tenant message is skipped")
+}
@@ -0,0 +1,206 @@
+// Package tlskit provides server TLS configuration with mTLS,
+// certificate hot-reload & verified peer identity
+package tlskit
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/adipurnama/go-toolkit/config"
+)
+
+// client authentication modes.
+const (
+	ClientAuthNone          = "none"
+	ClientAuthRequire       = "require"
+	ClientAuthVerifyIfGiven = "verify-if-given"
+)
+
+const (
+	defaultMinVersion     = tls.VersionTLS12
+	defaultReloadInterval = time.Minute
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Config server TLS configuration
+// default value:
+//   - MinVersion: 1.2
+//   - CipherSuites: go's default cipher suites, only used up to TLS 1.2
+//   - ClientAuth: `require` when ClientCAFile is set, otherwise `none`
+//   - ReloadInterval: 1 minute, how often cert, key & client CA files are checked for changes
+type Config struct {
+	CertFile       string        `json:"cert_file,omitempty"`
+	KeyFile        string        `json:"key_file,omitempty"`
+	ClientCAFile   string        `json:"client_ca_file,omitempty"`
+	ClientAuth     string        `json:"client_auth,omitempty"`
+	MinVersion     string        `json:"min_version,omitempty"`
+	CipherSuites   []string      `json:"cipher_suites,omitempty"`
+	ReloadInterval time.Duration `json:"reload_interval,omitempty"`
+}
+
+/*
+NewConfig returns *Config based on viper configuration
+with layout:
+
+	given config file contents:
+
+		tls:
+		  cert-file: /etc/tls/tls.crt
+		  key-file: /etc/tls/tls.key
+		  client-ca-file: /etc/tls/ca.crt
+		  client-auth: require
+		  min-version: "1.2"
+		  cipher-suites:
+		    - TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256
+		    - TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+		  reload-interval: 1m
+
+	call using `tlskit.NewConfig(v, "tls")`.
+*/
+func NewConfig(cfg config.KVStore, path string) *Config {
+	return &Config{
+		CertFile:       cfg.GetString(fmt.Sprintf("%s.cert-file", path)),
+		KeyFile:        cfg.GetString(fmt.Sprintf("%s.key-file", path)),
+		ClientCAFile:   cfg.GetString(fmt.Sprintf("%s.client-ca-file", path)),
+		ClientAuth:     cfg.GetString(fmt.Sprintf("%s.client-auth", path)),
+		MinVersion:     cfg.GetString(fmt.Sprintf("%s.min-version", path)),
+		CipherSuites:   cfg.GetStringSlice(fmt.Sprintf("%s.cipher-suites", path)),
+		ReloadInterval: cfg.GetDuration(fmt.Sprintf("%s.reload-interval", path)),
+	}
+}
+
+// Enabled reports whether TLS cert & key files are configured.
+func (c *Config) Enabled() bool {
+	return c != nil && c.CertFile != "" && c.KeyFile != ""
+}
+
+// ServerTLSConfig returns *tls.Config serving certificate & verifying client certificate
+// from configured files, which are reloaded when changed until ctx is done.
+func (c *Config) ServerTLSConfig(ctx context.Context) (*tls.Config, error) {
+	if !c.Enabled() {
+		return nil, errors.New("tlskit: cert-file & key-file are required")
+	}
+
+	minVersion, err := parseVersion(c.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	cipherSuites, err := parseCipherSuites(c.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	clientAuth, err := c.clientAuth()
+	if err != nil {
+		return nil, err
+	}
+
+	interval := c.ReloadInterval
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+
+	r := newReloader(c.CertFile, c.KeyFile, c.ClientCAFile)
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	go r.watch(ctx, interval)
+
+	base := &tls.Config{
+		MinVersion:   minVersion,
+		CipherSuites: cipherSuites,
+		ClientAuth:   clientAuth,
+	}
+
+	// every handshake uses the latest loaded certificate & client CAs
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cert, clientCAs := r.current()
+
+		result := base.Clone()
+		result.GetConfigForClient = nil
+		result.Certificates = []tls.Certificate{*cert}
+		result.ClientCAs = clientCAs
+
+		return result, nil
+	}
+
+	return base, nil
+}
+
+func (c *Config) clientAuth() (tls.ClientAuthType, error) {
+	switch strings.ToLower(c.ClientAuth) {
+	case "":
+		if c.ClientCAFile != "" {
+			return tls.RequireAndVerifyClientCert, nil
+		}
+
+		return tls.NoClientCert, nil
+	case ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthRequire, ClientAuthVerifyIfGiven:
+		if c.ClientCAFile == "" {
+			return tls.NoClientCert, errors.Errorf("tlskit: client-auth %s requires client-ca-file", c.ClientAuth)
+		}
+
+		if strings.EqualFold(c.ClientAuth, ClientAuthRequire) {
+			return tls.RequireAndVerifyClientCert, nil
+		}
+
+		return tls.VerifyClientCertIfGiven, nil
+	default:
+		return tls.NoClientCert, errors.Errorf("tlskit: unknown client-auth %s", c.ClientAuth)
+	}
+}
+
+// parseVersion returns TLS version of `1.0`, `1.1`, `1.2` or `1.3`.
+func parseVersion(v string) (uint16, error) {
+	if v == "" {
+		return defaultMinVersion, nil
+	}
+
+	result, ok := tlsVersions[strings.TrimPrefix(strings.ToLower(v), "tls")]
+	if !ok {
+		return 0, errors.Errorf("tlskit: unknown min-version %s", v)
+	}
+
+	return result, nil
+}
+
+// parseCipherSuites returns IDs of cipher suite names, e.g. `TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256`.
+// insecure cipher suites are rejected.
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	ids := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		ids[s.Name] = s.ID
+	}
+
+	result := make([]uint16, 0, len(names))
+
+	for _, name := range names {
+		id, ok := ids[strings.ToUpper(strings.TrimSpace(name))]
+		if !ok {
+			return nil, errors.Errorf("tlskit: unknown or insecure cipher suite %s", name)
+		}
+
+		result = append(result, id)
+	}
+
+	return result, nil
+}
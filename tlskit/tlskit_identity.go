@@ -0,0 +1,64 @@
+package tlskit
+
+import (
+	"context"
+	"crypto/tls"
+
+	"github.com/adipurnama/go-toolkit/log"
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+// contextKeyPeerIdentity to store/obtains verified *PeerIdentity to/from request's context.
+var contextKeyPeerIdentity = web.ContextKey("tlsPeerIdentity")
+
+// PeerIdentity is verified mTLS client certificate identity.
+// URIs contains SPIFFE ID, e.g. `spiffe://cluster.local/ns/default/sa/payment`.
+type PeerIdentity struct {
+	CommonName   string   `json:"common_name,omitempty"`
+	Organization []string `json:"organization,omitempty"`
+	DNSNames     []string `json:"dns_names,omitempty"`
+	URIs         []string `json:"uris,omitempty"`
+	SerialNumber string   `json:"serial_number,omitempty"`
+	Issuer       string   `json:"issuer,omitempty"`
+}
+
+// PeerIdentityFromConnectionState returns identity of verified client certificate in state.
+func PeerIdentityFromConnectionState(state *tls.ConnectionState) (*PeerIdentity, bool) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	cert := state.VerifiedChains[0][0]
+
+	id := &PeerIdentity{
+		CommonName:   cert.Subject.CommonName,
+		Organization: cert.Subject.Organization,
+		DNSNames:     cert.DNSNames,
+		SerialNumber: cert.SerialNumber.String(),
+		Issuer:       cert.Issuer.CommonName,
+	}
+
+	for _, u := range cert.URIs {
+		id.URIs = append(id.URIs, u.String())
+	}
+
+	return id, true
+}
+
+// NewContext returns copy of ctx containing id,
+// id common name is added to ctx's logger.
+func NewContext(ctx context.Context, id *PeerIdentity) context.Context {
+	ctx = context.WithValue(ctx, contextKeyPeerIdentity, id)
+
+	logger := log.FromCtx(ctx)
+	logger.AddField("peer_common_name", id.CommonName)
+
+	return log.AddToContext(ctx, logger)
+}
+
+// PeerIdentityFromContext returns verified client identity from ctx.
+func PeerIdentityFromContext(ctx context.Context) (*PeerIdentity, bool) {
+	id, ok := ctx.Value(contextKeyPeerIdentity).(*PeerIdentity)
+
+	return id, ok && id != nil
+}
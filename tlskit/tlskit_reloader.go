@@ -0,0 +1,143 @@
+package tlskit
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/adipurnama/go-toolkit/log"
+)
+
+// reloader keeps certificate & client CAs loaded from files,
+// reloaded when any of the files modification time or size changes,
+// e.g. rotated by cert-manager.
+type reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	stats     map[string]fileStat
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+func newReloader(certFile, keyFile, clientCAFile string) *reloader {
+	return &reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+	}
+}
+
+func (r *reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, r.clientCAs
+}
+
+func (r *reloader) files() []string {
+	if r.clientCAFile == "" {
+		return []string{r.certFile, r.keyFile}
+	}
+
+	return []string{r.certFile, r.keyFile, r.clientCAFile}
+}
+
+// load reads files, current certificate & client CAs are kept on failure.
+func (r *reloader) load() error {
+	stats := make(map[string]fileStat)
+
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			return errors.Wrapf(err, "tlskit: failed to read %s", f)
+		}
+
+		stats[f] = fileStat{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return errors.Wrap(err, "tlskit: failed to load certificate")
+	}
+
+	var clientCAs *x509.CertPool
+
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return errors.Wrapf(err, "tlskit: failed to read %s", r.clientCAFile)
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return errors.Errorf("tlskit: no certificate found in %s", r.clientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.stats = stats
+
+	return nil
+}
+
+// changed reports whether any of the files changed since last load.
+func (r *reloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			// e.g. in the middle of rotation, checked again on next tick
+			return false
+		}
+
+		if s := r.stats[f]; !s.modTime.Equal(info.ModTime()) || s.size != info.Size() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *reloader) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger := log.FromCtx(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+
+			if err := r.load(); err != nil {
+				logger.WarnError(err, "failed to reload TLS certificate, keep using the previous one")
+				continue
+			}
+
+			logger.Info("TLS certificate reloaded", "cert_file", r.certFile)
+		}
+	}
+}
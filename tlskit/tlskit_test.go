@@ -0,0 +1,264 @@
+package tlskit_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/tlskit"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns PEM encoded certificate & key signed by ca.
+func (ca *testCA) issue(t *testing.T, cn string, serial int64, uris ...string) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"acme"}},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	for _, u := range uris {
+		parsed, err := url.Parse(u)
+		require.NoError(t, err)
+
+		tmpl.URIs = append(tmpl.URIs, parsed)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func TestServerTLSConfig(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	ca := newTestCA(t, "test-ca")
+
+	serverCert, serverKey := ca.issue(t, "server-v1", 10)
+	clientCert, clientKey := ca.issue(t, "payment", 20, "spiffe://cluster.local/ns/default/sa/payment")
+
+	cfg := &tlskit.Config{
+		CertFile:       filepath.Join(dir, "tls.crt"),
+		KeyFile:        filepath.Join(dir, "tls.key"),
+		ClientCAFile:   filepath.Join(dir, "ca.crt"),
+		MinVersion:     "1.2",
+		ReloadInterval: 10 * time.Millisecond,
+	}
+
+	writeFile(t, cfg.CertFile, serverCert)
+	writeFile(t, cfg.KeyFile, serverKey)
+	writeFile(t, cfg.ClientCAFile, ca.pem)
+
+	tlsCfg, err := cfg.ServerTLSConfig(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, tlsCfg.ClientAuth)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := tlskit.PeerIdentityFromConnectionState(r.TLS)
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = io.WriteString(w, id.CommonName+" "+strings.Join(id.URIs, ","))
+	}))
+	srv.TLS = tlsCfg
+	srv.StartTLS()
+
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.pem)
+
+	keyPair, err := tls.X509KeyPair(clientCert, clientKey)
+	require.NoError(t, err)
+
+	newClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:      roots,
+				Certificates: certs,
+				ServerName:   "localhost",
+			},
+			DisableKeepAlives: true,
+		}}
+	}
+
+	t.Run("verified client identity", func(t *testing.T) {
+		resp, err := newClient(keyPair).Get(srv.URL)
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "payment spiffe://cluster.local/ns/default/sa/payment", string(body))
+		assert.Equal(t, "server-v1", resp.TLS.PeerCertificates[0].Subject.CommonName)
+	})
+
+	t.Run("client without certificate", func(t *testing.T) {
+		resp, err := newClient().Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		assert.Error(t, err)
+	})
+
+	t.Run("certificate reloaded", func(t *testing.T) {
+		newCert, newKey := ca.issue(t, "server-v2", 11)
+
+		writeFile(t, cfg.KeyFile, newKey)
+		writeFile(t, cfg.CertFile, newCert)
+
+		assert.Eventually(t, func() bool {
+			resp, err := newClient(keyPair).Get(srv.URL)
+			if err != nil {
+				return false
+			}
+
+			defer resp.Body.Close()
+
+			return resp.TLS.PeerCertificates[0].Subject.CommonName == "server-v2"
+		}, 2*time.Second, 20*time.Millisecond)
+	})
+}
+
+func TestServerTLSConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "test-ca")
+	cert, key := ca.issue(t, "server", 10)
+
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+
+	writeFile(t, certFile, cert)
+	writeFile(t, keyFile, key)
+
+	tests := map[string]*tlskit.Config{
+		"not enabled":            {},
+		"unknown min version":    {CertFile: certFile, KeyFile: keyFile, MinVersion: "2.0"},
+		"insecure cipher":        {CertFile: certFile, KeyFile: keyFile, CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+		"client auth without ca": {CertFile: certFile, KeyFile: keyFile, ClientAuth: tlskit.ClientAuthRequire},
+		"missing key file":       {CertFile: certFile, KeyFile: filepath.Join(dir, "missing.key")},
+	}
+
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := cfg.ServerTLSConfig(context.Background())
+			assert.Error(t, err)
+		})
+	}
+
+	tlsCfg, err := (&tlskit.Config{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		MinVersion:   "1.3",
+		CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+	}).ServerTLSConfig(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), tlsCfg.MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, tlsCfg.CipherSuites)
+	assert.Equal(t, tls.NoClientCert, tlsCfg.ClientAuth)
+}
+
+func TestNewConfig(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+restapi:
+  tls:
+    cert-file: /etc/tls/tls.crt
+    key-file: /etc/tls/tls.key
+    client-ca-file: /etc/tls/ca.crt
+    client-auth: verify-if-given
+    min-version: "1.3"
+    cipher-suites: [TLS_AES_128_GCM_SHA256]
+    reload-interval: 30s
+`)))
+
+	cfg := tlskit.NewConfig(v, "restapi.tls")
+
+	assert.True(t, cfg.Enabled())
+	assert.Equal(t, &tlskit.Config{
+		CertFile:       "/etc/tls/tls.crt",
+		KeyFile:        "/etc/tls/tls.key",
+		ClientCAFile:   "/etc/tls/ca.crt",
+		ClientAuth:     tlskit.ClientAuthVerifyIfGiven,
+		MinVersion:     "1.3",
+		CipherSuites:   []string{"TLS_AES_128_GCM_SHA256"},
+		ReloadInterval: 30 * time.Second,
+	}, cfg)
+
+	assert.False(t, tlskit.NewConfig(v, "grpc.tls").Enabled())
+}
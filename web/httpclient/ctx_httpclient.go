@@ -40,11 +40,17 @@ func (c *ContextHTTPClient) Do(ctx context.Context, req *http.Request, v interfa
 		return nil, ErrNonNilContext
 	}
 
+	header := req.Header
+
 	req, err := http.NewRequestWithContext(ctx, req.Method, req.URL.String(), req.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "web/httpclient: failed to build new request with context")
 	}
 
+	if header != nil {
+		req.Header = header.Clone()
+	}
+
 	resp, err := c.client.Do(req)
 	if err != nil {
 		// If we got an error, and the context has been canceled,
@@ -0,0 +1,423 @@
+// Package pagination parses offset & cursor pagination, sorting and filtering
+// from HTTP query or gRPC page token, and builds paginated response envelope
+package pagination
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+)
+
+// query params.
+const (
+	QueryPage   = "page"
+	QuerySize   = "size"
+	QueryCursor = "cursor"
+	QuerySort   = "sort"
+	// QueryFilter is filter param prefix, e.g. `filter[status]=paid` or `filter[total][gte]=100`.
+	QueryFilter = "filter"
+)
+
+const (
+	defaultSize      = 20
+	defaultMaxSize   = 100
+	defaultMaxOffset = 10000
+)
+
+// Operator is filter comparison operator.
+type Operator string
+
+// filter operators, `in` value is comma separated list.
+const (
+	OpEq   Operator = "eq"
+	OpNe   Operator = "ne"
+	OpGt   Operator = "gt"
+	OpGte  Operator = "gte"
+	OpLt   Operator = "lt"
+	OpLte  Operator = "lte"
+	OpIn   Operator = "in"
+	OpLike Operator = "like"
+)
+
+// SQL returns SQL comparison operator of op.
+func (op Operator) SQL() string {
+	switch op {
+	case OpNe:
+		return "<>"
+	case OpGt:
+		return ">"
+	case OpGte:
+		return ">="
+	case OpLt:
+		return "<"
+	case OpLte:
+		return "<="
+	case OpIn:
+		return "IN"
+	case OpLike:
+		return "LIKE"
+	default:
+		return "="
+	}
+}
+
+// FilterField is filterable field, Operators defaults to OpEq only.
+type FilterField struct {
+	Column    string     `json:"column"`
+	Operators []Operator `json:"operators,omitempty"`
+}
+
+// Config pagination configuration
+// default value:
+//   - DefaultSize: 20
+//   - MaxSize: 100, larger size is lowered to MaxSize
+//   - MaxOffset: 10000, deeper offset page or offset based cursor is rejected, use keyset cursor instead
+//   - CursorTTL: 0, cursor never expires
+//
+// SortFields & FilterFields whitelist fields clients can use, keyed by API field name
+// with database column as value, so columns are safe to build SQL query with.
+// DefaultSort uses sort param format e.g. `-created_at,id`.
+// CursorSecret signs cursors & page tokens, cursor pagination is disabled when it's empty.
+type Config struct {
+	DefaultSize  int                    `json:"default_size,omitempty"`
+	MaxSize      int                    `json:"max_size,omitempty"`
+	MaxOffset    int                    `json:"max_offset,omitempty"`
+	SortFields   map[string]string      `json:"sort_fields,omitempty"`
+	DefaultSort  string                 `json:"default_sort,omitempty"`
+	FilterFields map[string]FilterField `json:"filter_fields,omitempty"`
+	CursorSecret []byte                 `json:"-"`
+	CursorTTL    time.Duration          `json:"cursor_ttl,omitempty"`
+}
+
+// Paginator parses pagination requests using its Config.
+type Paginator struct {
+	cfg         *Config
+	defaultSort []Sort
+	now         func() time.Time
+}
+
+// New returns Paginator of cfg, it panics when DefaultSort isn't in SortFields.
+func New(cfg *Config) *Paginator {
+	if cfg.DefaultSize <= 0 {
+		cfg.DefaultSize = defaultSize
+	}
+
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = defaultMaxSize
+	}
+
+	if cfg.DefaultSize > cfg.MaxSize {
+		cfg.DefaultSize = cfg.MaxSize
+	}
+
+	if cfg.MaxOffset <= 0 {
+		cfg.MaxOffset = defaultMaxOffset
+	}
+
+	p := &Paginator{cfg: cfg, now: time.Now}
+
+	defaultSort, err := p.parseSort(cfg.DefaultSort)
+	if err != nil {
+		panic(fmt.Sprintf("pagination: invalid DefaultSort %q: %v", cfg.DefaultSort, err))
+	}
+
+	p.defaultSort = defaultSort
+
+	return p
+}
+
+// Sort is validated sort field.
+type Sort struct {
+	Field  string `json:"field"`
+	Column string `json:"column"`
+	Desc   bool   `json:"desc,omitempty"`
+}
+
+// Filter is validated filter expression.
+type Filter struct {
+	Field    string   `json:"field"`
+	Column   string   `json:"column"`
+	Operator Operator `json:"operator"`
+	Value    string   `json:"value"`
+}
+
+// Values returns comma separated values of OpIn filter, or single Value for other operators.
+func (f Filter) Values() []string {
+	if f.Operator != OpIn {
+		return []string{f.Value}
+	}
+
+	return strings.Split(f.Value, ",")
+}
+
+// Request is validated pagination request.
+// Page is 1 based on offset pagination, 0 on cursor pagination.
+// Offset is rows to skip, also set by offset based cursor, see Paginator.NextCursor.
+// Cursor is only valid with the same filters it's issued for.
+type Request struct {
+	Page    int      `json:"page,omitempty"`
+	Size    int      `json:"size"`
+	Offset  int      `json:"offset,omitempty"`
+	Cursor  string   `json:"cursor,omitempty"`
+	Sort    []Sort   `json:"sort,omitempty"`
+	Filters []Filter `json:"filters,omitempty"`
+
+	key json.RawMessage
+	// filter is fingerprint of request filters, signed into the cursor
+	filter string
+}
+
+// IsCursor reports whether r uses cursor pagination.
+func (r *Request) IsCursor() bool {
+	return r.Cursor != ""
+}
+
+// CursorKey decodes keyset position of r's cursor into v, e.g. sort values of previous page last item.
+// It returns false when r has no cursor or cursor is offset based.
+func (r *Request) CursorKey(v interface{}) (bool, error) {
+	if len(r.key) == 0 {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(r.key, v); err != nil {
+		return false, invalidArgument(QueryCursor, "invalid cursor")
+	}
+
+	return true, nil
+}
+
+// OrderBy returns SQL ORDER BY expression of r's sort columns, e.g. `created_at DESC, id ASC`.
+func (r *Request) OrderBy() string {
+	exprs := make([]string, 0, len(r.Sort))
+
+	for _, s := range r.Sort {
+		dir := "ASC"
+		if s.Desc {
+			dir = "DESC"
+		}
+
+		exprs = append(exprs, s.Column+" "+dir)
+	}
+
+	return strings.Join(exprs, ", ")
+}
+
+// Parse parses & validates HTTP query params, e.g.
+// `?page=2&size=20&sort=-created_at,id&filter[status]=paid&filter[total][gte]=100`
+// or `?cursor=<next_cursor>&size=20`. Page & cursor can't be used together,
+// sort defaults to the cursor's sort, then Config.DefaultSort.
+// It returns errors.AppError CodeInvalidArgument on invalid params.
+func (p *Paginator) Parse(q url.Values) (*Request, error) {
+	r := &Request{Size: p.cfg.DefaultSize}
+
+	filters, err := p.parseFilters(q)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Filters = filters
+	r.filter = filterFingerprint(encodeFilters(filters))
+
+	if s := q.Get(QuerySize); s != "" {
+		size, err := strconv.Atoi(s)
+		if err != nil || size < 1 {
+			return nil, invalidArgument(QuerySize, "size must be a positive number")
+		}
+
+		r.Size = p.limitSize(size)
+	}
+
+	cursor, page := q.Get(QueryCursor), q.Get(QueryPage)
+
+	switch {
+	case cursor != "" && page != "":
+		return nil, invalidArgument(QueryCursor, "cursor and page can't be used together")
+	case cursor != "":
+		if err := p.decodeCursor(r, cursor, q.Get(QuerySort)); err != nil {
+			return nil, err
+		}
+	default:
+		if err := p.parsePage(r, page); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.Sort == nil {
+		fields, err := p.sortOrDefault(q.Get(QuerySort))
+		if err != nil {
+			return nil, err
+		}
+
+		r.Sort = fields
+	}
+
+	return r, nil
+}
+
+func (p *Paginator) parsePage(r *Request, page string) error {
+	r.Page = 1
+
+	if page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			return invalidArgument(QueryPage, "page must be a positive number")
+		}
+
+		r.Page = n
+	}
+
+	r.Offset = (r.Page - 1) * r.Size
+
+	if r.Offset > p.cfg.MaxOffset {
+		return invalidArgument(QueryPage, fmt.Sprintf("page exceeds max offset %d, use cursor pagination", p.cfg.MaxOffset))
+	}
+
+	return nil
+}
+
+func (p *Paginator) limitSize(size int) int {
+	if size > p.cfg.MaxSize {
+		return p.cfg.MaxSize
+	}
+
+	return size
+}
+
+func (p *Paginator) sortOrDefault(s string) ([]Sort, error) {
+	if s == "" {
+		return p.defaultSort, nil
+	}
+
+	return p.parseSort(s)
+}
+
+// parseSort parses comma separated sort fields, `-` prefix for descending order.
+func (p *Paginator) parseSort(s string) ([]Sort, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(s, ",")
+	fields := make([]Sort, 0, len(parts))
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+
+		desc := strings.HasPrefix(part, "-")
+		field := strings.TrimLeft(part, "+-")
+
+		column, ok := p.cfg.SortFields[field]
+		if !ok {
+			return nil, invalidArgument(QuerySort, fmt.Sprintf("sorting by %q is not supported", field))
+		}
+
+		fields = append(fields, Sort{Field: field, Column: column, Desc: desc})
+	}
+
+	return fields, nil
+}
+
+// parseFilters parses `filter[field]` & `filter[field][op]` params.
+func (p *Paginator) parseFilters(q url.Values) ([]Filter, error) {
+	keys := make([]string, 0, len(q))
+
+	for key := range q {
+		if strings.HasPrefix(key, QueryFilter+"[") {
+			keys = append(keys, key)
+		}
+	}
+
+	// stable filter order, e.g. for cached query plans
+	sort.Strings(keys)
+
+	var filters []Filter
+
+	for _, key := range keys {
+		field, op, ok := parseFilterKey(key)
+		if !ok {
+			return nil, invalidArgument(key, "filter must be filter[field] or filter[field][operator]")
+		}
+
+		ff, ok := p.cfg.FilterFields[field]
+		if !ok {
+			return nil, invalidArgument(key, fmt.Sprintf("filtering by %q is not supported", field))
+		}
+
+		if !ff.allows(op) {
+			return nil, invalidArgument(key, fmt.Sprintf("operator %q is not supported for %q", op, field))
+		}
+
+		for _, v := range q[key] {
+			filters = append(filters, Filter{Field: field, Column: ff.Column, Operator: op, Value: v})
+		}
+	}
+
+	return filters, nil
+}
+
+// encodeFilters returns filters in `filter[field][op]=value` query format.
+func encodeFilters(filters []Filter) string {
+	q := url.Values{}
+
+	for _, f := range filters {
+		q.Add(QueryFilter+"["+f.Field+"]["+string(f.Operator)+"]", f.Value)
+	}
+
+	return q.Encode()
+}
+
+// parseFilterKey returns field & operator of `filter[field]` or `filter[field][op]`.
+func parseFilterKey(key string) (field string, op Operator, ok bool) {
+	rest := strings.TrimPrefix(key, QueryFilter)
+
+	var parts []string
+
+	for rest != "" {
+		if !strings.HasPrefix(rest, "[") {
+			return "", "", false
+		}
+
+		end := strings.Index(rest, "]")
+		if end < 2 {
+			return "", "", false
+		}
+
+		parts = append(parts, rest[1:end])
+		rest = rest[end+1:]
+	}
+
+	switch len(parts) {
+	case 1:
+		return parts[0], OpEq, true
+	case 2:
+		return parts[0], Operator(parts[1]), true
+	default:
+		return "", "", false
+	}
+}
+
+func (ff FilterField) allows(op Operator) bool {
+	if len(ff.Operators) == 0 {
+		return op == OpEq
+	}
+
+	for _, o := range ff.Operators {
+		if o == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+func invalidArgument(field, msg string) *apperrors.AppError {
+	return apperrors.New(apperrors.CodeInvalidArgument).
+		WithMessage(msg).
+		WithDetail("field", field)
+}
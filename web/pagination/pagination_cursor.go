@@ -0,0 +1,240 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrCursorDisabled returned when encoding cursor without Config.CursorSecret.
+	ErrCursorDisabled = errors.New("pagination: cursor secret is not configured")
+	// ErrCursorMaxOffset returned when encoding offset based cursor beyond Config.MaxOffset.
+	ErrCursorMaxOffset = errors.New("pagination: cursor exceeds max offset, use keyset cursor")
+)
+
+// cursorPayload is signed cursor content, Key is keyset position
+// and Offset is rows to skip when there's no keyset.
+// Filter is fingerprint of the filters the cursor is issued for.
+type cursorPayload struct {
+	Sort     string          `json:"s,omitempty"`
+	Filter   string          `json:"f,omitempty"`
+	Offset   int             `json:"o,omitempty"`
+	Key      json.RawMessage `json:"k,omitempty"`
+	IssuedAt int64           `json:"t"`
+}
+
+// NextCursor returns opaque signed cursor of the page after r, also usable as gRPC `next_page_token`.
+// key is keyset position e.g. sort values of r's last item, as JSON encodable value.
+// When key is nil, cursor continues by offset instead, up to Config.MaxOffset.
+// Don't call it on the last page, empty next cursor tells clients there's no more page.
+func (p *Paginator) NextCursor(r *Request, key interface{}) (string, error) {
+	if len(p.cfg.CursorSecret) == 0 {
+		return "", errors.WithStack(ErrCursorDisabled)
+	}
+
+	c := cursorPayload{
+		Sort:     formatSort(r.Sort),
+		Filter:   r.filter,
+		IssuedAt: p.now().Unix(),
+	}
+
+	if key == nil {
+		c.Offset = r.Offset + r.Size
+
+		if c.Offset > p.cfg.MaxOffset {
+			return "", errors.WithStack(ErrCursorMaxOffset)
+		}
+	} else {
+		k, err := json.Marshal(key)
+		if err != nil {
+			return "", errors.Wrap(err, "pagination: encode cursor key")
+		}
+
+		c.Key = k
+	}
+
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", errors.Wrap(err, "pagination: encode cursor")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(p.sign(payload)), nil
+}
+
+// ParsePageToken parses & validates gRPC list request (AIP-158),
+// pageSize defaults to Config.DefaultSize & is lowered to Config.MaxSize,
+// pageToken is `next_page_token` of previous response, see NextCursor.
+// orderBy uses AIP-132 format e.g. `create_time desc, id`, defaults to page token's sort, then Config.DefaultSort.
+// filter is request's AIP-160 filter, parsed by the service, page token is only valid with the same filter.
+// It returns errors.AppError CodeInvalidArgument on invalid params.
+func (p *Paginator) ParsePageToken(pageSize int32, pageToken, orderBy, filter string) (*Request, error) {
+	if pageSize < 0 {
+		return nil, invalidArgument("page_size", "page_size must not be negative")
+	}
+
+	r := &Request{Size: p.cfg.DefaultSize, filter: filterFingerprint(filter)}
+	if pageSize > 0 {
+		r.Size = p.limitSize(int(pageSize))
+	}
+
+	sortParam, err := parseOrderBy(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if pageToken != "" {
+		if err := p.decodeCursor(r, pageToken, sortParam); err != nil {
+			return nil, err
+		}
+
+		return r, nil
+	}
+
+	r.Sort, err = p.sortOrDefault(sortParam)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// decodeCursor verifies cursor token & sets r's position & sort from it,
+// sort param must match the cursor's sort when it's set, r's filter must match the cursor's filter.
+func (p *Paginator) decodeCursor(r *Request, token, sortParam string) error {
+	if len(p.cfg.CursorSecret) == 0 {
+		return invalidArgument(QueryCursor, "cursor pagination is not supported")
+	}
+
+	c, ok := p.verify(token)
+	if !ok {
+		return invalidArgument(QueryCursor, "invalid cursor")
+	}
+
+	if p.cfg.CursorTTL > 0 && p.now().Sub(time.Unix(c.IssuedAt, 0)) > p.cfg.CursorTTL {
+		return invalidArgument(QueryCursor, "cursor has expired")
+	}
+
+	if c.Filter != r.filter {
+		return invalidArgument(QueryFilter, "filter doesn't match cursor's filter")
+	}
+
+	if len(c.Key) == 0 && c.Offset > p.cfg.MaxOffset {
+		return invalidArgument(QueryCursor, fmt.Sprintf("cursor exceeds max offset %d, use keyset cursor", p.cfg.MaxOffset))
+	}
+
+	if sortParam != "" {
+		fields, err := p.parseSort(sortParam)
+		if err != nil {
+			return err
+		}
+
+		if formatSort(fields) != c.Sort {
+			return invalidArgument(QuerySort, "sort doesn't match cursor's sort")
+		}
+	}
+
+	fields, err := p.parseSort(c.Sort)
+	if err != nil {
+		return invalidArgument(QueryCursor, "invalid cursor")
+	}
+
+	r.Cursor = token
+	r.Offset = c.Offset
+	r.Sort = fields
+	r.key = c.Key
+
+	return nil
+}
+
+func (p *Paginator) verify(token string) (cursorPayload, bool) {
+	var c cursorPayload
+
+	payloadPart, sigPart, ok := strings.Cut(token, ".")
+	if !ok {
+		return c, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(payloadPart)
+	if err != nil {
+		return c, false
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(sigPart)
+	if err != nil || !hmac.Equal(sig, p.sign(payload)) {
+		return c, false
+	}
+
+	if err := json.Unmarshal(payload, &c); err != nil || c.Offset < 0 {
+		return c, false
+	}
+
+	return c, true
+}
+
+// filterFingerprint returns short hash of filter, empty when there's no filter.
+func filterFingerprint(filter string) string {
+	if filter == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(filter))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
+
+func (p *Paginator) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, p.cfg.CursorSecret)
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
+
+// formatSort returns fields in sort param format e.g. `-created_at,id`.
+func formatSort(fields []Sort) string {
+	parts := make([]string, 0, len(fields))
+
+	for _, f := range fields {
+		if f.Desc {
+			parts = append(parts, "-"+f.Field)
+		} else {
+			parts = append(parts, f.Field)
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// parseOrderBy converts AIP-132 order_by e.g. `create_time desc, id` into sort param format.
+func parseOrderBy(orderBy string) (string, error) {
+	if strings.TrimSpace(orderBy) == "" {
+		return "", nil
+	}
+
+	exprs := strings.Split(orderBy, ",")
+	parts := make([]string, 0, len(exprs))
+
+	for _, expr := range exprs {
+		words := strings.Fields(expr)
+
+		switch {
+		case len(words) == 1:
+			parts = append(parts, words[0])
+		case len(words) == 2 && strings.EqualFold(words[1], "desc"):
+			parts = append(parts, "-"+words[0])
+		case len(words) == 2 && strings.EqualFold(words[1], "asc"):
+			parts = append(parts, words[0])
+		default:
+			return "", invalidArgument("order_by", "order_by must be `field [asc|desc], ...`")
+		}
+	}
+
+	return strings.Join(parts, ","), nil
+}
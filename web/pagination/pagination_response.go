@@ -0,0 +1,134 @@
+package pagination
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// HTTPKeyLink is RFC 8288 Link response header.
+const HTTPKeyLink = "Link"
+
+// Response is paginated list response envelope, web.Response with pagination Meta.
+type Response struct {
+	Code   int         `json:"code"`
+	Status string      `json:"status"`
+	Data   interface{} `json:"data"`
+	Meta   Meta        `json:"meta"`
+}
+
+// Meta is pagination metadata, Total is set only when it's counted.
+type Meta struct {
+	Page       int    `json:"page,omitempty"`
+	Size       int    `json:"size"`
+	Total      *int64 `json:"total,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewResponse returns 200 OK Response of data, a page of r.
+func NewResponse(r *Request, data interface{}) *Response {
+	return &Response{
+		Code:   http.StatusOK,
+		Status: http.StatusText(http.StatusOK),
+		Data:   data,
+		Meta: Meta{
+			Page: r.Page,
+			Size: r.Size,
+		},
+	}
+}
+
+// WithTotal sets total items count of all pages.
+func (resp *Response) WithTotal(total int64) *Response {
+	resp.Meta.Total = &total
+
+	return resp
+}
+
+// WithNextCursor sets cursor of next page, see Paginator.NextCursor.
+func (resp *Response) WithNextCursor(cursor string) *Response {
+	resp.Meta.NextCursor = cursor
+
+	return resp
+}
+
+// Links returns RFC 8288 Link header value of resp's pages, using u as the request URL
+// e.g. `<https://api.example.com/orders?page=3&size=20>; rel="next"`.
+// Offset page has first, prev, next & last (when Total is set) links,
+// next link is added without Total when page is full. Cursor page has first & next links.
+func (resp *Response) Links(u *url.URL) string {
+	var links []string
+
+	add := func(rel string, set func(q url.Values)) {
+		next := *u
+		q := next.Query()
+
+		q.Del(QueryPage)
+		q.Del(QueryCursor)
+		set(q)
+
+		next.RawQuery = q.Encode()
+		links = append(links, "<"+next.String()+`>; rel="`+rel+`"`)
+	}
+
+	setPage := func(page int) func(q url.Values) {
+		return func(q url.Values) {
+			q.Set(QueryPage, strconv.Itoa(page))
+		}
+	}
+
+	m := resp.Meta
+
+	if m.Page == 0 {
+		add("first", func(q url.Values) {})
+
+		if m.NextCursor != "" {
+			add("next", func(q url.Values) { q.Set(QueryCursor, m.NextCursor) })
+		}
+
+		return strings.Join(links, ", ")
+	}
+
+	add("first", setPage(1))
+
+	if m.Page > 1 {
+		add("prev", setPage(m.Page-1))
+	}
+
+	switch {
+	case m.Total != nil:
+		last := int((*m.Total + int64(m.Size) - 1) / int64(m.Size))
+
+		if m.Page < last {
+			add("next", setPage(m.Page+1))
+		}
+
+		if last > 0 {
+			add("last", setPage(last))
+		}
+	case dataLen(resp.Data) >= m.Size:
+		add("next", setPage(m.Page+1))
+	}
+
+	return strings.Join(links, ", ")
+}
+
+// SetLinkHeader sets Link header of resp's pages to h, see Links.
+func (resp *Response) SetLinkHeader(h http.Header, u *url.URL) {
+	if links := resp.Links(u); links != "" {
+		h.Set(HTTPKeyLink, links)
+	}
+}
+
+// dataLen returns length of slice or array data, -1 for other types.
+func dataLen(data interface{}) int {
+	v := reflect.ValueOf(data)
+
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		return v.Len()
+	}
+
+	return -1
+}
@@ -0,0 +1,225 @@
+package pagination_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/adipurnama/go-toolkit/errors"
+	"github.com/adipurnama/go-toolkit/web/pagination"
+)
+
+func newPaginator() *pagination.Paginator {
+	return pagination.New(&pagination.Config{
+		MaxSize:     50,
+		MaxOffset:   1000,
+		SortFields:  map[string]string{"created_at": "o.created_at", "id": "o.id", "total": "o.total"},
+		DefaultSort: "-created_at,id",
+		FilterFields: map[string]pagination.FilterField{
+			"status": {Column: "o.status", Operators: []pagination.Operator{pagination.OpEq, pagination.OpIn}},
+			"total":  {Column: "o.total", Operators: []pagination.Operator{pagination.OpGte, pagination.OpLt}},
+			"buyer":  {Column: "o.buyer_id"},
+		},
+		CursorSecret: []byte("s3cr3t"),
+	})
+}
+
+func query(t *testing.T, raw string) url.Values {
+	q, err := url.ParseQuery(raw)
+	require.NoError(t, err)
+
+	return q
+}
+
+func TestParse(t *testing.T) {
+	p := newPaginator()
+
+	r, err := p.Parse(query(t, ""))
+	require.NoError(t, err)
+	assert.Equal(t, 1, r.Page)
+	assert.Equal(t, 20, r.Size)
+	assert.Equal(t, 0, r.Offset)
+	assert.Equal(t, "o.created_at DESC, o.id ASC", r.OrderBy())
+
+	r, err = p.Parse(query(t, "page=3&size=500&sort=total,-id&filter[status][in]=paid,shipped&filter[total][gte]=100&filter[buyer]=b1"))
+	require.NoError(t, err)
+	assert.Equal(t, 50, r.Size, "size is lowered to max size")
+	assert.Equal(t, 100, r.Offset)
+	assert.Equal(t, "o.total ASC, o.id DESC", r.OrderBy())
+	assert.Equal(t, []pagination.Filter{
+		{Field: "buyer", Column: "o.buyer_id", Operator: pagination.OpEq, Value: "b1"},
+		{Field: "status", Column: "o.status", Operator: pagination.OpIn, Value: "paid,shipped"},
+		{Field: "total", Column: "o.total", Operator: pagination.OpGte, Value: "100"},
+	}, r.Filters)
+	assert.Equal(t, []string{"paid", "shipped"}, r.Filters[1].Values())
+	assert.Equal(t, ">=", r.Filters[2].Operator.SQL())
+
+	invalid := []string{
+		"page=0",
+		"page=abc",
+		"size=-1",
+		"page=100&size=50",
+		"sort=password",
+		"filter[password]=x",
+		"filter[buyer][gt]=x",
+		"filter[status]]=x",
+		"filter[a][b][c]=x",
+		"page=2&cursor=abc",
+		"cursor=abc",
+	}
+
+	for _, raw := range invalid {
+		_, err := p.Parse(query(t, raw))
+		assert.Equal(t, apperrors.CodeInvalidArgument, apperrors.CodeOf(err), raw)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	p := newPaginator()
+
+	first, err := p.Parse(query(t, "size=10&sort=-total"))
+	require.NoError(t, err)
+
+	type key struct {
+		Total int    `json:"total"`
+		ID    string `json:"id"`
+	}
+
+	cursor, err := p.NextCursor(first, key{Total: 90, ID: "o-10"})
+	require.NoError(t, err)
+
+	next, err := p.Parse(url.Values{"cursor": {cursor}, "size": {"10"}})
+	require.NoError(t, err)
+	assert.True(t, next.IsCursor())
+	assert.Equal(t, 0, next.Page)
+	assert.Equal(t, "o.total DESC", next.OrderBy(), "sort is taken from cursor")
+
+	var k key
+
+	ok, err := next.CursorKey(&k)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, key{Total: 90, ID: "o-10"}, k)
+
+	_, err = p.Parse(url.Values{"cursor": {cursor}, "sort": {"id"}})
+	assert.Equal(t, apperrors.CodeInvalidArgument, apperrors.CodeOf(err), "sort mismatch")
+
+	_, err = p.Parse(url.Values{"cursor": {cursor + "x"}})
+	assert.Equal(t, apperrors.CodeInvalidArgument, apperrors.CodeOf(err), "tampered cursor")
+
+	other := pagination.New(&pagination.Config{SortFields: map[string]string{"total": "total"}, CursorSecret: []byte("other")})
+	_, err = other.Parse(url.Values{"cursor": {cursor}})
+	assert.Equal(t, apperrors.CodeInvalidArgument, apperrors.CodeOf(err), "cursor signed by another secret")
+
+	filtered, err := p.Parse(query(t, "size=10&filter[status]=paid"))
+	require.NoError(t, err)
+
+	cursor, err = p.NextCursor(filtered, nil)
+	require.NoError(t, err)
+
+	next, err = p.Parse(url.Values{"cursor": {cursor}, "filter[status]": {"paid"}})
+	require.NoError(t, err)
+	assert.Equal(t, 10, next.Offset)
+
+	for _, q := range []url.Values{
+		{"cursor": {cursor}},
+		{"cursor": {cursor}, "filter[status]": {"shipped"}},
+		{"cursor": {cursor}, "filter[status]": {"paid"}, "filter[buyer]": {"b1"}},
+	} {
+		_, err = p.Parse(q)
+		assert.Equal(t, apperrors.CodeInvalidArgument, apperrors.CodeOf(err), "filter mismatch %v", q)
+	}
+
+	// offset cursor is limited by max offset
+	deep, err := p.Parse(query(t, "page=21&size=50"))
+	require.NoError(t, err)
+
+	_, err = p.NextCursor(deep, nil)
+	assert.ErrorIs(t, err, pagination.ErrCursorMaxOffset)
+
+	deepCursor, err := pagination.New(&pagination.Config{
+		SortFields:   map[string]string{"created_at": "o.created_at", "id": "o.id"},
+		DefaultSort:  "-created_at,id",
+		CursorSecret: []byte("s3cr3t"),
+	}).NextCursor(deep, nil)
+	require.NoError(t, err)
+
+	_, err = p.Parse(url.Values{"cursor": {deepCursor}})
+	assert.Equal(t, apperrors.CodeInvalidArgument, apperrors.CodeOf(err), "offset beyond max offset")
+
+	_, err = p.NextCursor(deep, key{Total: 90, ID: "o-10"})
+	assert.NoError(t, err, "keyset cursor isn't limited")
+
+	disabled := pagination.New(&pagination.Config{})
+	_, err = disabled.NextCursor(first, nil)
+	assert.ErrorIs(t, err, pagination.ErrCursorDisabled)
+}
+
+func TestParsePageToken(t *testing.T) {
+	p := newPaginator()
+
+	r, err := p.ParsePageToken(0, "", "total desc, id", `status = "paid"`)
+	require.NoError(t, err)
+	assert.Equal(t, 20, r.Size)
+	assert.Equal(t, "o.total DESC, o.id ASC", r.OrderBy())
+
+	token, err := p.NextCursor(r, nil)
+	require.NoError(t, err)
+
+	next, err := p.ParsePageToken(20, token, "", `status = "paid"`)
+	require.NoError(t, err)
+	assert.Equal(t, 20, next.Offset)
+	assert.Equal(t, "o.total DESC, o.id ASC", next.OrderBy())
+
+	ok, err := next.CursorKey(&struct{}{})
+	require.NoError(t, err)
+	assert.False(t, ok, "offset based page token has no key")
+
+	_, err = p.ParsePageToken(20, token, "", `status = "shipped"`)
+	assert.Equal(t, apperrors.CodeInvalidArgument, apperrors.CodeOf(err), "filter mismatch")
+
+	_, err = p.ParsePageToken(-1, "", "", "")
+	assert.Equal(t, apperrors.CodeInvalidArgument, apperrors.CodeOf(err))
+
+	_, err = p.ParsePageToken(10, "", "total sideways", "")
+	assert.Equal(t, apperrors.CodeInvalidArgument, apperrors.CodeOf(err))
+}
+
+func TestResponse(t *testing.T) {
+	p := newPaginator()
+	u, _ := url.Parse("https://api.example.com/orders?page=2&size=2&sort=id")
+
+	r, err := p.Parse(u.Query())
+	require.NoError(t, err)
+
+	resp := pagination.NewResponse(r, []string{"c", "d"}).WithTotal(5)
+
+	b, err := json.Marshal(resp)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"code":200,"status":"OK","data":["c","d"],"meta":{"page":2,"size":2,"total":5}}`, string(b))
+
+	assert.Equal(t, `<https://api.example.com/orders?page=1&size=2&sort=id>; rel="first", `+
+		`<https://api.example.com/orders?page=1&size=2&sort=id>; rel="prev", `+
+		`<https://api.example.com/orders?page=3&size=2&sort=id>; rel="next", `+
+		`<https://api.example.com/orders?page=3&size=2&sort=id>; rel="last"`, resp.Links(u))
+
+	// without total, full page has next link
+	resp = pagination.NewResponse(r, []string{"c", "d"})
+	assert.Contains(t, resp.Links(u), `page=3&size=2&sort=id>; rel="next"`)
+
+	resp = pagination.NewResponse(r, []string{"c"})
+	assert.NotContains(t, resp.Links(u), `rel="next"`)
+
+	// cursor
+	cu, _ := url.Parse("https://api.example.com/orders?cursor=abc&size=2")
+
+	resp = pagination.NewResponse(&pagination.Request{Size: 2, Cursor: "abc"}, []string{"e"}).WithNextCursor("def")
+
+	h := make(map[string][]string)
+	resp.SetLinkHeader(h, cu)
+	assert.Equal(t, `<https://api.example.com/orders?size=2>; rel="first", `+
+		`<https://api.example.com/orders?cursor=def&size=2>; rel="next"`, h[pagination.HTTPKeyLink][0])
+}
@@ -57,7 +57,9 @@ func HeaderFromContext(ctx context.Context) http.Header {
 	return val
 }
 
-func translatorFromContext(ctx context.Context) ut.Translator {
+// TranslatorFromContext returns request's translator
+// set by echokit.ValidatorTranslatorMiddleware, nil if not found.
+func TranslatorFromContext(ctx context.Context) ut.Translator {
 	if val, ok := ctx.Value(ContextKeyTranslator).(ut.Translator); ok {
 		return val
 	}
@@ -56,7 +56,7 @@ func NewHTTPValidationError(ctx context.Context, err error) (result *HTTPError)
 	var fields []ErrorField
 
 	message = "field validation error found"
-	trans := translatorFromContext(ctx)
+	trans := TranslatorFromContext(ctx)
 
 	for _, e := range validationErrs {
 		fieldName := strings.ToLower(e.Field())
@@ -0,0 +1,65 @@
+package web
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HTTPKeyAcceptLanguage is standard HTTP header for preferred response languages.
+const HTTPKeyAcceptLanguage = "Accept-Language"
+
+// ParseAcceptLanguage returns language tags from `Accept-Language` header value
+// ordered by their q-value, e.g. `en;q=0.8, id-ID, id;q=0.9` returns [id-id id en].
+// tags are lower-cased, tags with q=0 or invalid q-value are excluded.
+func ParseAcceptLanguage(header string) []string {
+	type langQ struct {
+		tag string
+		q   float64
+	}
+
+	var langs []langQ
+
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		tag = normalizeLocale(tag)
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+
+		if params = strings.TrimSpace(params); params != "" {
+			name, value, _ := strings.Cut(params, "=")
+			if strings.TrimSpace(name) != "q" {
+				continue
+			}
+
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				continue
+			}
+
+			q = parsed
+		}
+
+		if q == 0 {
+			continue
+		}
+
+		langs = append(langs, langQ{tag: tag, q: q})
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	result := make([]string, 0, len(langs))
+
+	for _, l := range langs {
+		result = append(result, l.tag)
+	}
+
+	return result
+}
@@ -0,0 +1,106 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// MIMEApplicationProblemJSON is RFC 7807 problem details media type.
+	MIMEApplicationProblemJSON = "application/problem+json"
+
+	// ProblemTypeBlank is RFC 7807 default problem type
+	// used when the problem has no additional semantics beyond the http status code.
+	ProblemTypeBlank = "about:blank"
+)
+
+// ProblemDetails is RFC 7807 error response body.
+// see https://www.rfc-editor.org/rfc/rfc7807
+//
+// non-standard members (request_id, trace_id, errors, etc.)
+// are written as top level extension members.
+type ProblemDetails struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	TraceID    string                 `json:"trace_id,omitempty"`
+	Errors     []ErrorField           `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// NewProblemDetails returns *ProblemDetails with `about:blank` type
+// and title taken from http status text.
+func NewProblemDetails(status int, detail string) *ProblemDetails {
+	return &ProblemDetails{
+		Type:   ProblemTypeBlank,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// ProblemDetailsFromHTTPError converts *HTTPError to *ProblemDetails,
+// field validation errors are copied to `errors` extension member.
+func ProblemDetailsFromHTTPError(e *HTTPError) *ProblemDetails {
+	p := NewProblemDetails(e.Code, e.Message)
+
+	switch details := e.Response.(type) {
+	case ErrorDetails:
+		p.Errors = details.Errors
+	case *ErrorDetails:
+		if details != nil {
+			p.Errors = details.Errors
+		}
+	}
+
+	return p
+}
+
+// SetExtension sets additional problem details member.
+// standard members can't be overridden.
+func (p *ProblemDetails) SetExtension(key string, value interface{}) *ProblemDetails {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]interface{})
+	}
+
+	p.Extensions[key] = value
+
+	return p
+}
+
+// Error implements error interface, so handler can return *ProblemDetails directly.
+func (p *ProblemDetails) Error() string {
+	return fmt.Sprintf("web.ProblemDetails status=%d, detail=%s", p.Status, p.Detail)
+}
+
+// MarshalJSON implements json.Marshaler, flattens Extensions as top level members.
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	type problemDetails ProblemDetails
+
+	b, err := json.Marshal(problemDetails(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+
+	members := make(map[string]interface{}, len(p.Extensions))
+
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+
+	// standard & known members always win over extensions
+	var known map[string]interface{}
+	if err := json.Unmarshal(b, &known); err != nil {
+		return nil, err
+	}
+
+	for k, v := range known {
+		members[k] = v
+	}
+
+	return json.Marshal(members)
+}
@@ -0,0 +1,53 @@
+package web_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/adipurnama/go-toolkit/web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblemDetailsFromHTTPError(t *testing.T) {
+	httpErr := &web.HTTPError{
+		Code:    http.StatusBadRequest,
+		Message: "name is a required field",
+		Response: web.ErrorDetails{
+			Exception: "field validation error found",
+			Errors: []web.ErrorField{
+				{Field: "name", Message: "name is a required field"},
+			},
+		},
+	}
+
+	p := web.ProblemDetailsFromHTTPError(httpErr)
+
+	assert.Equal(t, web.ProblemTypeBlank, p.Type)
+	assert.Equal(t, "Bad Request", p.Title)
+	assert.Equal(t, http.StatusBadRequest, p.Status)
+	assert.Equal(t, "name is a required field", p.Detail)
+	assert.Len(t, p.Errors, 1)
+}
+
+func TestProblemDetailsMarshalJSON(t *testing.T) {
+	p := web.NewProblemDetails(http.StatusNotFound, "user not found")
+	p.Instance = "/users/1"
+	p.RequestID = "req-1"
+	p.SetExtension("balance", 30).SetExtension("status", 200)
+
+	b, err := json.Marshal(p)
+	assert.NoError(t, err)
+
+	var got map[string]interface{}
+
+	assert.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, "about:blank", got["type"])
+	assert.Equal(t, "Not Found", got["title"])
+	assert.Equal(t, float64(http.StatusNotFound), got["status"], "extension should not override standard member")
+	assert.Equal(t, "user not found", got["detail"])
+	assert.Equal(t, "/users/1", got["instance"])
+	assert.Equal(t, "req-1", got["request_id"])
+	assert.Equal(t, float64(30), got["balance"])
+	assert.NotContains(t, got, "trace_id")
+}
@@ -0,0 +1,19 @@
+package web
+
+import "net/http"
+
+// Response is standard success response envelope.
+type Response struct {
+	Code   int         `json:"code"`
+	Status string      `json:"status"`
+	Data   interface{} `json:"data,omitempty"`
+}
+
+// NewResponse returns new Response with http status text of code.
+func NewResponse(code int, data interface{}) *Response {
+	return &Response{
+		Code:   code,
+		Status: http.StatusText(code),
+		Data:   data,
+	}
+}
@@ -0,0 +1,304 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	shortuuid "github.com/lithammer/shortuuid/v3"
+)
+
+// trace context propagation headers.
+const (
+	// HTTPKeyTraceParent is W3C trace context header, see https://www.w3.org/TR/trace-context/
+	HTTPKeyTraceParent = "traceparent"
+	// HTTPKeyTraceState is W3C vendor specific trace state header.
+	HTTPKeyTraceState = "tracestate"
+	// HTTPKeyElasticTraceParent is legacy Elastic APM agent header, in W3C traceparent format.
+	HTTPKeyElasticTraceParent = "Elastic-Apm-Traceparent"
+	// HTTPKeyB3 is Zipkin B3 single header.
+	HTTPKeyB3 = "b3"
+	// HTTPKeyB3TraceID is Zipkin B3 multi header trace ID.
+	HTTPKeyB3TraceID = "X-B3-TraceId"
+	// HTTPKeyB3SpanID is Zipkin B3 multi header span ID.
+	HTTPKeyB3SpanID = "X-B3-SpanId"
+	// HTTPKeyB3Sampled is Zipkin B3 multi header sampling decision.
+	HTTPKeyB3Sampled = "X-B3-Sampled"
+	// HTTPKeyB3Flags is Zipkin B3 multi header debug flag.
+	HTTPKeyB3Flags = "X-B3-Flags"
+)
+
+const (
+	traceParentVersion = "00"
+	traceIDLength      = 32
+	spanIDLength       = 16
+)
+
+// ContextKeyTraceContext to store/obtains TraceContext to/from request's context.
+var ContextKeyTraceContext = ContextKey("traceContext")
+
+// RequestIDProvider generates new request id for each requests.
+type RequestIDProvider interface {
+	NewRequestID() string
+}
+
+// DefaultRequestIDProvider generates shortuuid string for request id.
+func DefaultRequestIDProvider() RequestIDProvider {
+	return shortuuidRequestIDProvider{}
+}
+
+type shortuuidRequestIDProvider struct{}
+
+// NewRequestID implements RequestIDProvider interface.
+func (shortuuidRequestIDProvider) NewRequestID() string {
+	return shortuuid.New()
+}
+
+// TraceIDGenerator generates W3C compliant trace & span ID,
+// 32 & 16 lowercase hex characters, not all zeros.
+type TraceIDGenerator interface {
+	NewTraceID() string
+	NewSpanID() string
+}
+
+// DefaultTraceIDGenerator generates random trace & span ID using crypto/rand.
+func DefaultTraceIDGenerator() TraceIDGenerator {
+	return randomTraceIDGenerator{}
+}
+
+type randomTraceIDGenerator struct{}
+
+// NewTraceID implements TraceIDGenerator interface.
+func (randomTraceIDGenerator) NewTraceID() string {
+	return randomHexID(traceIDLength / 2)
+}
+
+// NewSpanID implements TraceIDGenerator interface.
+func (randomTraceIDGenerator) NewSpanID() string {
+	return randomHexID(spanIDLength / 2)
+}
+
+func randomHexID(n int) string {
+	b := make([]byte, n)
+
+	for {
+		_, _ = rand.Read(b)
+
+		id := hex.EncodeToString(b)
+		if isHexID(id, n*2) {
+			return id
+		}
+	}
+}
+
+// TraceContext is W3C trace context of a request.
+// SpanID is the current span, ParentSpanID is the caller span if any.
+type TraceContext struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Sampled      bool
+	TraceState   string
+}
+
+// NewTraceContext returns sampled root TraceContext with IDs from gen,
+// gen defaults to DefaultTraceIDGenerator.
+func NewTraceContext(gen TraceIDGenerator) TraceContext {
+	if gen == nil {
+		gen = DefaultTraceIDGenerator()
+	}
+
+	return TraceContext{
+		TraceID: gen.NewTraceID(),
+		SpanID:  gen.NewSpanID(),
+		Sampled: true,
+	}
+}
+
+// ChildOf returns TraceContext of a new span, using gen to generate span ID, whose parent is tc.
+func (tc TraceContext) ChildOf(gen TraceIDGenerator) TraceContext {
+	if gen == nil {
+		gen = DefaultTraceIDGenerator()
+	}
+
+	child := tc
+	child.ParentSpanID = tc.SpanID
+	child.SpanID = gen.NewSpanID()
+
+	return child
+}
+
+// IsValid reports whether tc has W3C compliant trace & span ID.
+func (tc TraceContext) IsValid() bool {
+	return isHexID(tc.TraceID, traceIDLength) && isHexID(tc.SpanID, spanIDLength)
+}
+
+// TraceParent returns tc in W3C traceparent header format, e.g.
+// `00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01`.
+func (tc TraceContext) TraceParent() string {
+	flags := "00"
+	if tc.Sampled {
+		flags = "01"
+	}
+
+	return traceParentVersion + "-" + tc.TraceID + "-" + tc.SpanID + "-" + flags
+}
+
+// Inject sets tc as traceparent & tracestate header of h.
+func (tc TraceContext) Inject(h http.Header) {
+	if !tc.IsValid() {
+		return
+	}
+
+	h.Set(HTTPKeyTraceParent, tc.TraceParent())
+
+	if tc.TraceState != "" {
+		h.Set(HTTPKeyTraceState, tc.TraceState)
+	}
+}
+
+// ParseTraceParent parses W3C traceparent header value,
+// returned SpanID is the caller's span.
+func ParseTraceParent(s string) (TraceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 {
+		return TraceContext{}, false
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	// future versions may append fields
+	if len(version) != 2 || version == "ff" || !isHex(version) ||
+		(version == traceParentVersion && len(parts) != 4) {
+		return TraceContext{}, false
+	}
+
+	flagBytes, err := hex.DecodeString(flags)
+	if err != nil || len(flagBytes) != 1 || !isHex(flags) {
+		return TraceContext{}, false
+	}
+
+	tc := TraceContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Sampled: flagBytes[0]&1 == 1,
+	}
+
+	if !tc.IsValid() {
+		return TraceContext{}, false
+	}
+
+	return tc, true
+}
+
+// ParseB3 parses Zipkin B3 single header value `{TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}`,
+// 64 bit trace ID is left padded with zeros. IDs are case insensitive.
+func ParseB3(s string) (TraceContext, bool) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(s)), "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return TraceContext{}, false
+	}
+
+	tc := TraceContext{
+		TraceID: padB3TraceID(parts[0]),
+		SpanID:  parts[1],
+		Sampled: true,
+	}
+
+	if len(parts) > 2 {
+		switch parts[2] {
+		case "0":
+			tc.Sampled = false
+		case "1", "d": // sampled & debug
+		default:
+			return TraceContext{}, false
+		}
+	}
+
+	if len(parts) > 3 && !isHexID(parts[3], spanIDLength) {
+		return TraceContext{}, false
+	}
+
+	if !tc.IsValid() {
+		return TraceContext{}, false
+	}
+
+	return tc, true
+}
+
+// TraceContextFromHeader extracts caller TraceContext from h, in order of precedence:
+// W3C traceparent, Elastic APM traceparent, B3 single header then B3 multi headers.
+func TraceContextFromHeader(h http.Header) (TraceContext, bool) {
+	for _, key := range []string{HTTPKeyTraceParent, HTTPKeyElasticTraceParent} {
+		if v := h.Get(key); v != "" {
+			if tc, ok := ParseTraceParent(v); ok {
+				tc.TraceState = h.Get(HTTPKeyTraceState)
+
+				return tc, true
+			}
+		}
+	}
+
+	if v := h.Get(HTTPKeyB3); v != "" {
+		if tc, ok := ParseB3(v); ok {
+			return tc, true
+		}
+	}
+
+	if h.Get(HTTPKeyB3TraceID) == "" {
+		return TraceContext{}, false
+	}
+
+	tc := TraceContext{
+		TraceID: padB3TraceID(h.Get(HTTPKeyB3TraceID)),
+		SpanID:  strings.ToLower(h.Get(HTTPKeyB3SpanID)),
+		Sampled: h.Get(HTTPKeyB3Sampled) != "0" || h.Get(HTTPKeyB3Flags) == "1",
+	}
+
+	if !tc.IsValid() {
+		return TraceContext{}, false
+	}
+
+	return tc, true
+}
+
+// ContextWithTraceContext returns ctx containing tc & its trace ID.
+func ContextWithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	ctx = context.WithValue(ctx, ContextKeyTraceContext, tc)
+
+	return context.WithValue(ctx, ContextKeyTraceID, tc.TraceID)
+}
+
+// TraceContextFromContext returns TraceContext set by ContextWithTraceContext.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(ContextKeyTraceContext).(TraceContext)
+
+	return tc, ok
+}
+
+func padB3TraceID(id string) string {
+	id = strings.ToLower(id)
+
+	if len(id) == spanIDLength {
+		return strings.Repeat("0", spanIDLength) + id
+	}
+
+	return id
+}
+
+// isHexID reports whether id is n lowercase hex characters, not all zeros.
+func isHexID(id string, n int) bool {
+	return len(id) == n && isHex(id) && strings.Trim(id, "0") != ""
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
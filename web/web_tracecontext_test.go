@@ -0,0 +1,182 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		valid   bool
+		sampled bool
+	}{
+		{"sampled", "00-" + testTraceID + "-" + testSpanID + "-01", true, true},
+		{"not sampled", "00-" + testTraceID + "-" + testSpanID + "-00", true, false},
+		{"future version with extra field", "01-" + testTraceID + "-" + testSpanID + "-09-xyz", true, true},
+		{"version 00 with extra field", "00-" + testTraceID + "-" + testSpanID + "-01-xyz", false, false},
+		{"invalid version", "ff-" + testTraceID + "-" + testSpanID + "-01", false, false},
+		{"uppercase trace ID", "00-4BF92F3577B34DA6A3CE929D0E0E4736-" + testSpanID + "-01", false, false},
+		{"zero trace ID", "00-00000000000000000000000000000000-" + testSpanID + "-01", false, false},
+		{"zero span ID", "00-" + testTraceID + "-0000000000000000-01", false, false},
+		{"short span ID", "00-" + testTraceID + "-00f067aa-01", false, false},
+		{"invalid flags", "00-" + testTraceID + "-" + testSpanID + "-zz", false, false},
+		{"garbage", "not-a-traceparent", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc, ok := web.ParseTraceParent(tt.value)
+			assert.Equal(t, tt.valid, ok)
+
+			if tt.valid {
+				assert.Equal(t, testTraceID, tc.TraceID)
+				assert.Equal(t, testSpanID, tc.SpanID)
+				assert.Equal(t, tt.sampled, tc.Sampled)
+			}
+		})
+	}
+}
+
+func TestParseB3(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		valid   bool
+		sampled bool
+	}{
+		{"sampled", testTraceID + "-" + testSpanID + "-1", true, true},
+		{"debug", testTraceID + "-" + testSpanID + "-d", true, true},
+		{"not sampled", testTraceID + "-" + testSpanID + "-0", true, false},
+		{"deferred sampling", testTraceID + "-" + testSpanID, true, true},
+		{"with parent span ID", testTraceID + "-" + testSpanID + "-1-05e3ac9a4f6e3b90", true, true},
+		{"uppercase IDs", "4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-1", true, true},
+		{"short span ID", testTraceID + "-00f067aa-1", false, false},
+		{"non hex span ID", testTraceID + "-00f067aa0ba902bz-1", false, false},
+		{"invalid sampling state", testTraceID + "-" + testSpanID + "-x", false, false},
+		{"invalid parent span ID", testTraceID + "-" + testSpanID + "-1-05e3ac9a", false, false},
+		{"extra field", testTraceID + "-" + testSpanID + "-1-05e3ac9a4f6e3b90-x", false, false},
+		{"sampling state only", "0", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc, ok := web.ParseB3(tt.value)
+			assert.Equal(t, tt.valid, ok)
+
+			if tt.valid {
+				assert.Equal(t, testTraceID, tc.TraceID)
+				assert.Equal(t, testSpanID, tc.SpanID)
+				assert.Equal(t, tt.sampled, tc.Sampled)
+			}
+		})
+	}
+}
+
+func TestTraceContextFromHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  map[string]string
+		traceID string
+		sampled bool
+	}{
+		{
+			name: "W3C with tracestate",
+			header: map[string]string{
+				web.HTTPKeyTraceParent: "00-" + testTraceID + "-" + testSpanID + "-01",
+				web.HTTPKeyTraceState:  "es=s:1",
+				web.HTTPKeyB3:          "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1",
+			},
+			traceID: testTraceID,
+			sampled: true,
+		},
+		{
+			name: "elastic",
+			header: map[string]string{
+				web.HTTPKeyElasticTraceParent: "00-" + testTraceID + "-" + testSpanID + "-00",
+			},
+			traceID: testTraceID,
+		},
+		{
+			name: "B3 single",
+			header: map[string]string{
+				web.HTTPKeyB3: "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-05e3ac9a4f6e3b90",
+			},
+			traceID: "80f198ee56343ba864fe8b2a57d3eff7",
+			sampled: true,
+		},
+		{
+			name: "B3 multi with 64 bit trace ID",
+			header: map[string]string{
+				web.HTTPKeyB3TraceID: "64fe8b2a57d3eff7",
+				web.HTTPKeyB3SpanID:  "e457b5a2e4d86bd1",
+				web.HTTPKeyB3Sampled: "0",
+			},
+			traceID: "000000000000000064fe8b2a57d3eff7",
+		},
+		{
+			name: "invalid W3C falls back to B3",
+			header: map[string]string{
+				web.HTTPKeyTraceParent: "00-invalid",
+				web.HTTPKeyB3:          "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1",
+			},
+			traceID: "80f198ee56343ba864fe8b2a57d3eff7",
+			sampled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tt.header {
+				h.Set(k, v)
+			}
+
+			tc, ok := web.TraceContextFromHeader(h)
+			require.True(t, ok)
+			assert.Equal(t, tt.traceID, tc.TraceID)
+			assert.Equal(t, tt.sampled, tc.Sampled)
+		})
+	}
+
+	_, ok := web.TraceContextFromHeader(http.Header{web.HTTPKeyTraceID: []string{"legacy-id"}})
+	assert.False(t, ok)
+}
+
+func TestTraceContextPropagation(t *testing.T) {
+	root := web.NewTraceContext(nil)
+	require.True(t, root.IsValid())
+	assert.Equal(t, "00-"+root.TraceID+"-"+root.SpanID+"-01", root.TraceParent())
+
+	child := root.ChildOf(nil)
+	assert.Equal(t, root.TraceID, child.TraceID)
+	assert.Equal(t, root.SpanID, child.ParentSpanID)
+	assert.NotEqual(t, root.SpanID, child.SpanID)
+
+	h := http.Header{}
+	child.Inject(h)
+
+	parsed, ok := web.TraceContextFromHeader(h)
+	require.True(t, ok)
+	assert.Equal(t, child.TraceID, parsed.TraceID)
+	assert.Equal(t, child.SpanID, parsed.SpanID)
+
+	ctx := web.ContextWithTraceContext(context.Background(), child)
+
+	fromCtx, ok := web.TraceContextFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, child, fromCtx)
+	assert.Equal(t, child.TraceID, web.ValueFromContext(ctx, web.ContextKeyTraceID))
+}
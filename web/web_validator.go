@@ -1,24 +1,229 @@
 package web
 
 import (
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales"
+	en_locale "github.com/go-playground/locales/en"
+	id_locale "github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
 	validator "github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
 )
 
-// Validator - go-playground/validator wrapper.
+// DefaultLocale is fallback locale when none of requested locales is registered.
+const DefaultLocale = "en"
+
+// RegisterDefaultTranslationsFunc registers validator's default tags translations,
+// e.g. `en_translations.RegisterDefaultTranslations`.
+type RegisterDefaultTranslationsFunc func(v *validator.Validate, trans ut.Translator) error
+
+// Rule is custom validation tag with its messages per locale.
+// Messages may contain `{0}` for field name & `{1}` for tag param,
+// locale without message uses DefaultLocale message.
+// Rule with nil Func only registers messages for existing tag.
+type Rule struct {
+	Tag                      string
+	Func                     validator.Func
+	CallValidationEvenIfNull bool
+	Messages                 map[string]string
+}
+
+// Validator - go-playground/validator wrapper
+// with registered locales translators & custom rules,
+// shared by echo & gRPC request validation.
 type Validator struct {
 	validator *validator.Validate
+
+	mu           sync.RWMutex
+	uni          *ut.UniversalTranslator
+	translators  map[string]ut.Translator
+	rules        []Rule
+	translations []func(trans ut.Translator) error
 }
 
-// NewValidator -.
+// NewValidator returns new *Validator with `en` & `id` locales,
+// and built-in rules: RuleIDPhone, RuleNIK, RuleCurrency.
 func NewValidator(v *validator.Validate) *Validator {
 	if v == nil {
 		v = validator.New()
 	}
 
-	return &Validator{v}
+	en := en_locale.New()
+
+	result := &Validator{
+		validator:   v,
+		uni:         ut.New(en),
+		translators: make(map[string]ut.Translator),
+	}
+
+	_ = result.RegisterLocale(en, en_translations.RegisterDefaultTranslations)
+	_ = result.RegisterLocale(id_locale.New(), id_translations.RegisterDefaultTranslations)
+
+	for _, r := range []Rule{RuleIDPhone, RuleNIK, RuleCurrency} {
+		_ = result.RegisterRule(r)
+	}
+
+	return result
 }
 
 // Validate - go-playground/validator impl.
 func (v *Validator) Validate(i interface{}) error {
 	return v.validator.Struct(i)
 }
+
+// Engine returns underlying *validator.Validate.
+func (v *Validator) Engine() *validator.Validate {
+	return v.validator
+}
+
+// RegisterLocale adds new locale translator, e.g. `RegisterLocale(ja.New(), ja_translations.RegisterDefaultTranslations)`.
+// registered rules & translations are added to the new translator.
+// Like validator.RegisterValidation, it should be called before validating any request.
+func (v *Validator) RegisterLocale(l locales.Translator, registerDefaults RegisterDefaultTranslationsFunc) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key := normalizeLocale(l.Locale())
+
+	trans, found := v.uni.GetTranslator(l.Locale())
+	if !found || normalizeLocale(trans.Locale()) != key {
+		if err := v.uni.AddTranslator(l, true); err != nil {
+			return err
+		}
+
+		trans, _ = v.uni.GetTranslator(l.Locale())
+	}
+
+	if registerDefaults != nil {
+		if err := registerDefaults(v.validator, trans); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range v.rules {
+		if err := v.registerRuleTranslation(trans, r); err != nil {
+			return err
+		}
+	}
+
+	for _, fn := range v.translations {
+		if err := fn(trans); err != nil {
+			return err
+		}
+	}
+
+	v.translators[key] = trans
+
+	return nil
+}
+
+// RegisterRule registers custom validation tag & its messages to all registered locales.
+// Like validator.RegisterValidation, it should be called before validating any request.
+func (v *Validator) RegisterRule(r Rule) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if r.Func != nil {
+		if err := v.validator.RegisterValidation(r.Tag, r.Func, r.CallValidationEvenIfNull); err != nil {
+			return err
+		}
+	}
+
+	for _, trans := range v.translators {
+		if err := v.registerRuleTranslation(trans, r); err != nil {
+			return err
+		}
+	}
+
+	v.rules = append(v.rules, r)
+
+	return nil
+}
+
+// AddTranslations runs fn for all registered & future locale translators,
+// e.g. to add application messages.
+func (v *Validator) AddTranslations(fn func(trans ut.Translator) error) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for _, trans := range v.translators {
+		if err := fn(trans); err != nil {
+			return err
+		}
+	}
+
+	v.translations = append(v.translations, fn)
+
+	return nil
+}
+
+// Translator returns translator of locale, e.g. `id` or `en-US`, falls back to its base language.
+func (v *Validator) Translator(locale string) (ut.Translator, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	locale = normalizeLocale(locale)
+
+	if trans, ok := v.translators[locale]; ok {
+		return trans, true
+	}
+
+	if idx := strings.Index(locale, "-"); idx > 0 {
+		trans, ok := v.translators[locale[:idx]]
+
+		return trans, ok
+	}
+
+	return nil, false
+}
+
+// Negotiate returns the best translator for `Accept-Language` header value,
+// e.g. `id-ID,id;q=0.9,en;q=0.8`, or DefaultLocale translator.
+func (v *Validator) Negotiate(acceptLanguage string) ut.Translator {
+	for _, lang := range ParseAcceptLanguage(acceptLanguage) {
+		if lang == "*" {
+			break
+		}
+
+		if trans, ok := v.Translator(lang); ok {
+			return trans
+		}
+	}
+
+	trans, _ := v.Translator(DefaultLocale)
+
+	return trans
+}
+
+func (v *Validator) registerRuleTranslation(trans ut.Translator, r Rule) error {
+	msg, ok := r.Messages[trans.Locale()]
+	if !ok {
+		msg = r.Messages[DefaultLocale]
+	}
+
+	if msg == "" {
+		return nil
+	}
+
+	return v.validator.RegisterTranslation(r.Tag, trans,
+		func(t ut.Translator) error {
+			return t.Add(r.Tag, msg, true)
+		},
+		func(t ut.Translator, fe validator.FieldError) string {
+			result, err := t.T(fe.Tag(), fe.Field(), fe.Param())
+			if err != nil {
+				return fe.Error()
+			}
+
+			return result
+		},
+	)
+}
+
+// normalizeLocale returns lower-cased locale with `-` separator, e.g. `en_US` becomes `en-us`.
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
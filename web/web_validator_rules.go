@@ -0,0 +1,70 @@
+package web
+
+import (
+	"regexp"
+	"strconv"
+
+	validator "github.com/go-playground/validator/v10"
+)
+
+var (
+	idPhoneRegex = regexp.MustCompile(`^(\+62|62|0)8[1-9][0-9]{6,11}$`)
+	nikRegex     = regexp.MustCompile(`^[0-9]{16}$`)
+)
+
+// built-in validation rules.
+var (
+	// RuleIDPhone validates Indonesian mobile phone number, e.g. `081234567890` or `+6281234567890`.
+	RuleIDPhone = Rule{
+		Tag:  "id_phone",
+		Func: isIDPhone,
+		Messages: map[string]string{
+			"en": "{0} must be a valid Indonesian phone number",
+			"id": "{0} harus berupa nomor telepon Indonesia yang valid",
+		},
+	}
+
+	// RuleNIK validates Indonesian population identity number (Nomor Induk Kependudukan),
+	// 16 digits containing region code & birth date.
+	RuleNIK = Rule{
+		Tag:  "nik",
+		Func: isNIK,
+		Messages: map[string]string{
+			"en": "{0} must be a valid NIK",
+			"id": "{0} harus berupa NIK yang valid",
+		},
+	}
+
+	// RuleCurrency adds messages for validator's built-in `iso4217` currency code tag.
+	RuleCurrency = Rule{
+		Tag: "iso4217",
+		Messages: map[string]string{
+			"en": "{0} must be a valid ISO 4217 currency code",
+			"id": "{0} harus berupa kode mata uang ISO 4217 yang valid",
+		},
+	}
+)
+
+func isIDPhone(fl validator.FieldLevel) bool {
+	return idPhoneRegex.MatchString(fl.Field().String())
+}
+
+func isNIK(fl validator.FieldLevel) bool {
+	nik := fl.Field().String()
+	if !nikRegex.MatchString(nik) {
+		return false
+	}
+
+	province, _ := strconv.Atoi(nik[0:2])
+	day, _ := strconv.Atoi(nik[6:8])
+	month, _ := strconv.Atoi(nik[8:10])
+
+	// female birth day is added by 40
+	if day > 40 {
+		day -= 40
+	}
+
+	return province >= 11 && province <= 94 &&
+		day >= 1 && day <= 31 &&
+		month >= 1 && month <= 12
+}
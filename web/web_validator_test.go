@@ -0,0 +1,113 @@
+package web_test
+
+import (
+	"context"
+	"testing"
+
+	fr_locale "github.com/go-playground/locales/fr"
+	validator "github.com/go-playground/validator/v10"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adipurnama/go-toolkit/web"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := map[string][]string{
+		"":                             {},
+		"id":                           {"id"},
+		"en;q=0.8, id-ID, id;q=0.9":    {"id-id", "id", "en"},
+		"fr;q=0, en_US;q=0.5, *;q=0.1": {"en-us", "*"},
+		"de;q=invalid, ja;q=0.7,,en":   {"en", "ja"},
+		"en-GB;level=1, id;q=0.3":      {"id"},
+	}
+
+	for header, want := range tests {
+		assert.Equal(t, want, web.ParseAcceptLanguage(header), header)
+	}
+}
+
+func TestValidatorNegotiate(t *testing.T) {
+	v := web.NewValidator(nil)
+
+	assert.Equal(t, "id", v.Negotiate("id-ID,id;q=0.9,en;q=0.8").Locale())
+	assert.Equal(t, "en", v.Negotiate("fr-FR, en;q=0.5, id;q=0.4").Locale())
+	assert.Equal(t, "en", v.Negotiate("fr-FR").Locale())
+	assert.Equal(t, "en", v.Negotiate("").Locale())
+}
+
+type customerRequest struct {
+	Phone    string `validate:"id_phone"`
+	NIK      string `validate:"nik"`
+	Currency string `validate:"iso4217"`
+	Code     string `validate:"even_length"`
+}
+
+func TestValidatorRules(t *testing.T) {
+	v := web.NewValidator(nil)
+
+	require.NoError(t, v.RegisterRule(web.Rule{
+		Tag: "even_length",
+		Func: func(fl validator.FieldLevel) bool {
+			return len(fl.Field().String())%2 == 0
+		},
+		Messages: map[string]string{
+			"en": "{0} length must be even",
+			"id": "panjang {0} harus genap",
+		},
+	}))
+
+	valid := customerRequest{
+		Phone:    "081234567890",
+		NIK:      "3174015708900002",
+		Currency: "IDR",
+		Code:     "ab",
+	}
+	assert.NoError(t, v.Validate(valid))
+
+	invalid := customerRequest{
+		Phone:    "021555",
+		NIK:      "9974011308900002",
+		Currency: "XYZ",
+		Code:     "abc",
+	}
+
+	err := v.Validate(invalid)
+	require.Error(t, err)
+
+	ctx := context.WithValue(context.Background(), web.ContextKeyTranslator, v.Negotiate("id"))
+	httpErr := web.NewHTTPValidationError(ctx, err)
+
+	details, ok := httpErr.Response.(web.ErrorDetails)
+	require.True(t, ok)
+
+	messages := make(map[string]string)
+	for _, f := range details.Errors {
+		messages[f.Field] = f.Message
+	}
+
+	assert.Equal(t, map[string]string{
+		"phone":    "Phone harus berupa nomor telepon Indonesia yang valid",
+		"nik":      "NIK harus berupa NIK yang valid",
+		"currency": "Currency harus berupa kode mata uang ISO 4217 yang valid",
+		"code":     "panjang Code harus genap",
+	}, messages)
+
+	// additional locale, rule without its message falls back to `en`
+	require.NoError(t, v.RegisterLocale(fr_locale.New(), fr_translations.RegisterDefaultTranslations))
+
+	trans := v.Negotiate("fr-CA, en;q=0.5")
+	assert.Equal(t, "fr", trans.Locale())
+
+	ctx = context.WithValue(context.Background(), web.ContextKeyTranslator, trans)
+	details = web.NewHTTPValidationError(ctx, v.Validate(customerRequest{
+		Phone:    valid.Phone,
+		NIK:      valid.NIK,
+		Currency: valid.Currency,
+		Code:     "abc",
+	})).Response.(web.ErrorDetails)
+
+	require.Len(t, details.Errors, 1)
+	assert.Equal(t, "Code length must be even", details.Errors[0].Message)
+}